@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -15,6 +14,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
 	"github.com/asdf-vm/asdf/internal/exec"
 	"github.com/asdf-vm/asdf/internal/execenv"
 	"github.com/asdf-vm/asdf/internal/execute"
@@ -24,9 +24,12 @@ import (
 	"github.com/asdf-vm/asdf/internal/installs"
 	"github.com/asdf-vm/asdf/internal/pluginindex"
 	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/plugintemplate"
+	"github.com/asdf-vm/asdf/internal/plugintest"
 	"github.com/asdf-vm/asdf/internal/resolve"
 	"github.com/asdf-vm/asdf/internal/shims"
 	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/versioncmp"
 	"github.com/asdf-vm/asdf/internal/versions"
 	"github.com/urfave/cli/v2"
 )
@@ -64,6 +67,17 @@ func Execute(version string) {
 		Usage:     "The multiple runtime version manager",
 		UsageText: usageText,
 		Commands: []*cli.Command{
+			{
+				Name: "cache",
+				Subcommands: []*cli.Command{
+					{
+						Name: "clear",
+						Action: func(_ *cli.Context) error {
+							return cacheClearCommand(logger)
+						},
+					},
+				},
+			},
 			{
 				Name: "cmd",
 				Action: func(cCtx *cli.Context) error {
@@ -79,6 +93,53 @@ func Execute(version string) {
 					return completionCommand(logger, shell)
 				},
 			},
+			{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name: "get",
+						Action: func(cCtx *cli.Context) error {
+							return configGetCommand(logger, cCtx.Args().Get(0))
+						},
+					},
+					{
+						Name: "list",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "show-origin",
+								Usage: "Show the layer and file each value came from",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return configListCommand(logger, cCtx.Bool("show-origin"))
+						},
+					},
+					{
+						Name:  "set",
+						Flags: configLayerFlags(),
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							if cCtx.Bool("project") && cCtx.Bool("user") {
+								return cli.Exit("--project and --user can't be used together", 1)
+							}
+
+							return configSetCommand(logger, cCtx.Bool("project"), args.Get(0), args.Get(1), args.Len())
+						},
+					},
+					{
+						Name:  "unset",
+						Flags: configLayerFlags(),
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							if cCtx.Bool("project") && cCtx.Bool("user") {
+								return cli.Exit("--project and --user can't be used together", 1)
+							}
+
+							return configUnsetCommand(logger, cCtx.Bool("project"), args.Get(0), args.Len())
+						},
+					},
+				},
+			},
 			{
 				Name: "current",
 				Flags: []cli.Flag{
@@ -86,10 +147,18 @@ func Execute(version string) {
 						Name:  "no-header",
 						Usage: "Whether or not to print a header line",
 					},
+					&cli.BoolFlag{
+						Name:  "explain",
+						Usage: "Show every place consulted to resolve the version and why it was picked",
+					},
 				},
 				Action: func(cCtx *cli.Context) error {
 					tool := cCtx.Args().Get(0)
 
+					if cCtx.Bool("explain") {
+						return currentExplainCommand(logger, tool)
+					}
+
 					noHeader := cCtx.Bool("no-header")
 					return currentCommand(logger, tool, noHeader)
 				},
@@ -139,11 +208,15 @@ func Execute(version string) {
 						Name:  "keep-download",
 						Usage: "Whether or not to keep download directory after successful install",
 					},
+					&cli.BoolFlag{
+						Name:  "refresh-refs",
+						Usage: "Reinstall ref versions whose upstream ref has moved",
+					},
 				},
 				Action: func(cCtx *cli.Context) error {
 					args := cCtx.Args()
 					keepDownload := cCtx.Bool("keep-download")
-					return installCommand(logger, args.Get(0), args.Get(1), keepDownload)
+					return installCommand(logger, args.Get(0), args.Get(1), keepDownload, cCtx.Bool("refresh-refs"))
 				},
 			},
 			{
@@ -153,13 +226,17 @@ func Execute(version string) {
 						Name:  "all",
 						Usage: "Show latest version of all tools",
 					},
+					&cli.BoolFlag{
+						Name:  "include-prereleases",
+						Usage: "Include pre-release versions like release candidates",
+					},
 				},
 				Action: func(cCtx *cli.Context) error {
 					tool := cCtx.Args().Get(0)
 					pattern := cCtx.Args().Get(1)
 					all := cCtx.Bool("all")
 
-					return latestCommand(logger, all, tool, pattern)
+					return latestCommand(logger, all, cCtx.Bool("include-prereleases"), tool, pattern)
 				},
 			},
 			{
@@ -169,6 +246,24 @@ func Execute(version string) {
 					return listCommand(logger, args.Get(0), args.Get(1), args.Get(2))
 				},
 			},
+			{
+				Name: "migrate-dirs",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print what would be moved without moving anything",
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					return migrateDirsCommand(logger, cCtx.Bool("dry-run"))
+				},
+			},
+			{
+				Name: "outdated",
+				Action: func(cCtx *cli.Context) error {
+					return outdatedCommand(logger, cCtx.Args().Get(0))
+				},
+			},
 			{
 				Name: "plugin",
 				Action: func(_ *cli.Context) error {
@@ -179,6 +274,12 @@ func Execute(version string) {
 				Subcommands: []*cli.Command{
 					{
 						Name: "add",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "link",
+								Usage: "Link a local plugin working copy instead of cloning a Git repository",
+							},
+						},
 						Action: func(cCtx *cli.Context) error {
 							args := cCtx.Args()
 							conf, err := config.LoadConfig()
@@ -187,9 +288,44 @@ func Execute(version string) {
 								return err
 							}
 
+							// Flags after the plugin name aren't parsed as
+							// flags, so `asdf plugin add <name> --link <path>`
+							// is handled here
+							positional, linkPath, linked := linkArg(args.Slice())
+							if flagPath := cCtx.String("link"); flagPath != "" {
+								linkPath, linked = flagPath, true
+							}
+
+							if linked {
+								return pluginAddLinkCommand(conf, logger, positional, linkPath)
+							}
+
 							return pluginAddCommand(cCtx, conf, logger, args.Get(0), args.Get(1))
 						},
 					},
+					{
+						Name: "config",
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							return pluginConfigCommand(logger, args.Get(0), args.Get(1), args.Slice())
+						},
+					},
+					{
+						Name: "info",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Output plugin information as JSON",
+							},
+							&cli.BoolFlag{
+								Name:  "no-update-check",
+								Usage: "Don't query the plugin's remote for updates",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return pluginInfoCommand(logger, cCtx.Args().Get(0), cCtx.Bool("json"), !cCtx.Bool("no-update-check"))
+						},
+					},
 					{
 						Name: "list",
 						Flags: []cli.Flag{
@@ -214,6 +350,19 @@ func Execute(version string) {
 							},
 						},
 					},
+					{
+						Name: "new",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "no-git",
+								Usage: "Don't create a Git repository for the new plugin",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							args := cCtx.Args()
+							return pluginNewCommand(logger, args.Get(0), args.Get(1), !cCtx.Bool("no-git"))
+						},
+					},
 					{
 						Name: "remove",
 						Action: func(cCtx *cli.Context) error {
@@ -228,12 +377,23 @@ func Execute(version string) {
 								Name:  "all",
 								Usage: "Update all installed plugins",
 							},
+							&cli.IntFlag{
+								Name:  "concurrency",
+								Value: plugins.DefaultUpdateConcurrency,
+								Usage: "Number of plugins to update at once when used with --all",
+							},
 						},
 						Action: func(cCtx *cli.Context) error {
 							args := cCtx.Args()
 							return pluginUpdateCommand(cCtx, logger, args.Get(0), args.Get(1))
 						},
 					},
+					{
+						Name: "verify",
+						Action: func(cCtx *cli.Context) error {
+							return pluginVerifyCommand(logger, cCtx.Args().Get(0))
+						},
+					},
 					{
 						Name: "test",
 						Flags: []cli.Flag{
@@ -245,13 +405,19 @@ func Execute(version string) {
 								Name:  "asdf-plugin-gitref",
 								Usage: "The plugin Git ref to test",
 							},
+							&cli.StringFlag{
+								Name:  "junit",
+								Usage: "Write a JUnit XML report of the test results to this file",
+							},
 						},
 						Action: func(cCtx *cli.Context) error {
-							toolVersion := cCtx.String("asdf-tool-version")
-							gitRef := cCtx.String("asdf-plugin-gitref")
-							args := cCtx.Args().Slice()
-							pluginTestCommand(logger, args, toolVersion, gitRef)
-							return nil
+							flags := map[string]string{
+								"asdf-tool-version":  cCtx.String("asdf-tool-version"),
+								"asdf-plugin-gitref": cCtx.String("asdf-plugin-gitref"),
+								"junit":              cCtx.String("junit"),
+							}
+							args := extractTrailingFlags(cCtx.Args().Slice(), flags)
+							return pluginTestCommand(logger, args, flags["asdf-tool-version"], flags["asdf-plugin-gitref"], flags["junit"])
 						},
 					},
 				},
@@ -297,9 +463,19 @@ func Execute(version string) {
 			},
 			{
 				Name: "which",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "explain",
+						Usage: "Show how the version of each tool providing the command was resolved",
+					},
+				},
 				Action: func(cCtx *cli.Context) error {
 					tool := cCtx.Args().Get(0)
 
+					if cCtx.Bool("explain") {
+						return whichExplainCommand(logger, tool)
+					}
+
 					return whichCommand(logger, tool)
 				},
 			},
@@ -388,9 +564,15 @@ func currentCommand(logger *log.Logger, tool string, noHeader bool) error {
 			return nil
 		}
 
-		for _, plugin := range allPlugins {
-			toolversion, versionFound, versionInstalled := getVersionInfo(conf, plugin, currentDir)
-			formatCurrentVersionLine(w, plugin, toolversion, versionFound, versionInstalled, err)
+		results, err := resolve.AllVersions(conf, allPlugins, currentDir)
+		if err != nil {
+			logger.Printf("unable to resolve versions: %s", err)
+			return err
+		}
+
+		for _, result := range results {
+			installed := result.Found && isInstalled(conf, result.Plugin, result.Versions)
+			formatCurrentVersionLine(w, result.Plugin, result.Versions, result.Found, installed, result.Err)
 		}
 		w.Flush()
 		return nil
@@ -421,17 +603,85 @@ func currentCommand(logger *log.Logger, tool string, noHeader bool) error {
 	return nil
 }
 
+// currentExplainCommand shows how the version of the tool, or of every tool if
+// none is given, was resolved in the current directory
+func currentExplainCommand(logger *log.Logger, tool string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		logger.Printf("unable to get current directory: %s", err)
+		return err
+	}
+
+	allPlugins := []plugins.Plugin{plugins.New(conf, tool)}
+	if tool == "" {
+		allPlugins, err = plugins.List(conf, false, false)
+		if err != nil {
+			return err
+		}
+	} else if err := allPlugins[0].Exists(); err != nil {
+		fmt.Printf("No such plugin: %s\n", tool)
+		return err
+	}
+
+	for i, plugin := range allPlugins {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		toolversion, found, trace, err := resolve.Explain(conf, plugin, currentDir)
+		if err != nil {
+			logger.Printf("unable to resolve version of %s: %s", plugin.Name, err)
+		}
+
+		fmt.Println(plugin.Name)
+		if found {
+			version := formatVersions(toolversion.Versions)
+			if len(toolversion.Requested) > 0 && !slices.Equal(toolversion.Requested, toolversion.Versions) {
+				version = fmt.Sprintf("%s (requested %s)", version, formatVersions(toolversion.Requested))
+			}
+
+			installed := installs.IsInstalled(conf, plugin, toolversions.Parse(toolversion.Versions[0]))
+			fmt.Printf("  version:   %s\n", version)
+			fmt.Printf("  source:    %s\n", formatSource(toolversion, found))
+			fmt.Printf("  installed: %t\n", installed)
+		}
+
+		writeTrace(os.Stdout, trace)
+	}
+
+	return nil
+}
+
+// writeTrace writes every place consulted to resolve a version and the reason
+// the winner was picked
+func writeTrace(out io.Writer, trace resolve.Trace) {
+	fmt.Fprintln(out, "  consulted:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, step := range trace.Steps {
+		fmt.Fprintf(w, "    %s\t%s\n", step.Source, step.Result)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "  reason:    %s\n", trace.Reason)
+}
+
 func getVersionInfo(conf config.Config, plugin plugins.Plugin, currentDir string) (resolve.ToolVersions, bool, bool) {
 	toolversion, found, _ := resolve.Version(conf, plugin, currentDir)
-	installed := false
-	if found {
-		firstVersion := toolversion.Versions[0]
-		version := toolversions.Parse(firstVersion)
-		installed = installs.IsInstalled(conf, plugin, version)
-	}
+	installed := found && isInstalled(conf, plugin, toolversion)
 	return toolversion, found, installed
 }
 
+// isInstalled returns true if the first of the resolved versions is installed
+func isInstalled(conf config.Config, plugin plugins.Plugin, toolversion resolve.ToolVersions) bool {
+	version := toolversions.Parse(toolversion.Versions[0])
+	return installs.IsInstalled(conf, plugin, version)
+}
+
 func writeHeader(w *tabwriter.Writer) {
 	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "Name", "Version", "Source", "Installed")
 }
@@ -443,6 +693,9 @@ func formatCurrentVersionLine(w *tabwriter.Writer, plugin plugins.Plugin, toolve
 
 	// columns are: name, version, source, installed
 	version := formatVersions(toolversion.Versions)
+	if len(toolversion.Requested) > 0 && !slices.Equal(toolversion.Requested, toolversion.Versions) {
+		version = fmt.Sprintf("%s (%s)", formatVersions(toolversion.Requested), version)
+	}
 	source := formatSource(toolversion, found)
 	installedStatus := formatInstalled(toolversion, plugin.Name, found, installed)
 	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", plugin.Name, version, source, installedStatus)
@@ -454,7 +707,11 @@ func formatInstalled(toolversion resolve.ToolVersions, name string, found, insta
 		return ""
 	}
 	if !installed {
-		return fmt.Sprintf("false - Run `asdf install %s %s`", name, toolversion.Versions[0])
+		version := toolversion.Versions[0]
+		if toolversions.IsConstraint(version) {
+			version = fmt.Sprintf("'%s'", version)
+		}
+		return fmt.Sprintf("false - Run `asdf install %s %s`", name, version)
 	}
 	return "true"
 }
@@ -615,6 +872,11 @@ func extensionCommand(logger *log.Logger, args []string) error {
 }
 
 func runExtensionCommand(plugin plugins.Plugin, args []string, environment map[string]string) (err error) {
+	err = plugin.Verify()
+	if err != nil {
+		return err
+	}
+
 	path := ""
 	if len(args) > 0 {
 		path, err = plugin.ExtensionCommandPath(args[0])
@@ -729,6 +991,77 @@ func pluginAddCommand(_ *cli.Context, conf config.Config, logger *log.Logger, pl
 	return nil
 }
 
+// linkArg removes a --link <path> or --link=<path> argument from the arguments
+// and returns the remaining arguments and the path. The boolean is false if
+// there is no --link argument.
+func linkArg(args []string) ([]string, string, bool) {
+	for index, arg := range args {
+		if path, ok := strings.CutPrefix(arg, "--link="); ok {
+			return append(slices.Clone(args[:index]), args[index+1:]...), path, true
+		}
+
+		if arg == "--link" {
+			if index+1 >= len(args) {
+				return append(slices.Clone(args[:index]), args[index+1:]...), "", true
+			}
+
+			return append(slices.Clone(args[:index]), args[index+2:]...), args[index+1], true
+		}
+	}
+
+	return args, "", false
+}
+
+func pluginAddLinkCommand(conf config.Config, logger *log.Logger, args []string, path string) error {
+	if len(args) != 1 || path == "" {
+		return cli.Exit("usage: asdf plugin add <name> --link <path>", 1)
+	}
+
+	pluginName := args[0]
+
+	err := plugins.AddLink(conf, pluginName, path)
+	if err != nil {
+		logger.Printf("%s", err)
+
+		var existsErr plugins.PluginAlreadyExists
+		if errors.As(err, &existsErr) {
+			os.Exit(0)
+			return nil
+		}
+
+		os.Exit(1)
+		return nil
+	}
+
+	os.Exit(0)
+	return nil
+}
+
+func pluginNewCommand(logger *log.Logger, pluginName, dir string, initGit bool) error {
+	if pluginName == "" {
+		return cli.Exit("usage: asdf plugin new [--no-git] <name> [<directory>]", 1)
+	}
+
+	err := plugins.ValidatePluginName(pluginName)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	if dir == "" {
+		dir = fmt.Sprintf("asdf-%s", pluginName)
+	}
+
+	err = plugintemplate.Generate(dir, pluginName, initGit)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	fmt.Printf("Created plugin %s in %s\n", pluginName, dir)
+	return nil
+}
+
 func pluginRemoveCommand(_ *cli.Context, logger *log.Logger, pluginName string) error {
 	if pluginName == "" {
 		logger.Print("No plugin given")
@@ -821,7 +1154,7 @@ func pluginListAllCommand(logger *log.Logger) error {
 		lastCheckDuration = checkDuration.Every
 	}
 
-	index := pluginindex.Build(conf.DataDir, conf.PluginIndexURL, false, lastCheckDuration)
+	index := pluginindex.Build(conf.CacheDirectory(), conf.PluginIndexURL, false, lastCheckDuration, plugins.GitOptions(conf))
 	availablePlugins, err := index.Get()
 	if err != nil {
 		logger.Printf("error loading plugin index: %s", err)
@@ -857,180 +1190,563 @@ func pluginInstalled(plugin pluginindex.Plugin, installedPlugins []plugins.Plugi
 	return false
 }
 
-func infoCommand(conf config.Config, version string) error {
-	return info.Print(conf, version)
-}
-
-func helpCommand(logger *log.Logger, asdfVersion, tool, version string) error {
+// cacheClearCommand removes the cached callback output of every plugin
+func cacheClearCommand(logger *log.Logger) error {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
 		return err
 	}
 
-	if tool != "" {
-		if version != "" {
-			err := help.PrintToolVersion(conf, tool, version)
-			if err != nil {
-				os.Exit(1)
-			}
-			return err
-		}
-
-		err := help.PrintTool(conf, tool)
-		if err != nil {
-			os.Exit(1)
-		}
+	err = plugins.ClearCaches(conf)
+	if err != nil {
+		logger.Printf("error clearing cache: %s", err)
 		return err
 	}
 
-	allPlugins, err := plugins.List(conf, false, false)
+	return nil
+}
+
+// migrateDirsCommand moves the legacy ~/.asdfrc file and ~/.asdf directory to
+// the XDG base directories. Downloads and the plugin index go to the cache
+// directory.
+func migrateDirsCommand(logger *log.Logger, dryRun bool) error {
+	conf, err := config.LoadConfig()
 	if err != nil {
-		os.Exit(1)
+		logger.Printf("error loading config: %s", err)
+		return err
 	}
 
-	err = help.Print(asdfVersion, allPlugins)
-	if err != nil {
-		os.Exit(1)
+	if _, ok := os.LookupEnv("ASDF_DATA_DIR"); ok {
+		return cli.Exit("ASDF_DATA_DIR is set, unset it to use the XDG base directories", 1)
 	}
 
-	return err
-}
+	legacy := config.LegacyDirs(conf.Home)
+	target := config.XDGDirs(conf.Home)
+	moves := []data.Move{}
 
-func pluginUpdateCommand(cCtx *cli.Context, logger *log.Logger, pluginName, ref string) error {
-	updateAll := cCtx.Bool("all")
-	if !updateAll && pluginName == "" {
-		return cli.Exit("usage: asdf plugin-update {<name> [git-ref] | --all}", 1)
+	if target.ConfigFile != legacy.ConfigFile {
+		for _, suffix := range []string{"", ".d"} {
+			from, to := legacy.ConfigFile+suffix, target.ConfigFile+suffix
+			if _, err := os.Stat(from); err != nil {
+				continue
+			}
+
+			if _, err := os.Stat(to); err == nil {
+				return cli.Exit(fmt.Sprintf("unable to move %s, %s already exists", from, to), 1)
+			}
+
+			moves = append(moves, data.Move{From: from, To: to})
+		}
 	}
 
-	conf, err := config.LoadConfig()
-	if err != nil {
-		logger.Printf("error loading config: %s", err)
-		return err
+	_, err = os.Stat(legacy.DataDir)
+	migrateData := err == nil && target.DataDir != legacy.DataDir
+	if err == nil && !migrateData {
+		logger.Printf("XDG_DATA_HOME is not set, leaving %s in place", legacy.DataDir)
 	}
 
-	if updateAll {
-		installedPlugins, err := plugins.List(conf, false, false)
+	if migrateData {
+		targetConf := config.Config{DataDir: target.DataDir, CacheDir: target.CacheDir}
+		dataMoves, err := data.PlanMigration(legacy.DataDir, targetConf.DataDir, targetConf.CacheDirectory())
 		if err != nil {
-			logger.Printf("failed to get plugin list: %s", err)
-			return err
+			return cli.Exit(err.Error(), 1)
 		}
 
-		for _, plugin := range installedPlugins {
-			updatedToRef, err := plugin.Update(conf, "", os.Stdout, os.Stderr)
-			formatUpdateResult(logger, plugin.Name, updatedToRef, err)
-		}
+		moves = append(moves, dataMoves...)
+	}
 
+	if len(moves) == 0 {
+		fmt.Println("Nothing to migrate")
 		return nil
 	}
 
-	plugin := plugins.New(conf, pluginName)
+	for _, move := range moves {
+		if dryRun {
+			fmt.Printf("would move %s to %s\n", move.From, move.To)
+		} else {
+			fmt.Printf("moving %s to %s\n", move.From, move.To)
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	err = data.Migrate(moves)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	if migrateData {
+		// Only removes the legacy directory if everything was moved out of it
+		os.Remove(legacy.DataDir)
+
+		fmt.Printf("\nReplace %s with %s in your PATH.\n", shims.Directory(config.Config{DataDir: legacy.DataDir}), shims.Directory(config.Config{DataDir: target.DataDir}))
+		fmt.Println("Tools that record their install path may need to be reinstalled.")
+	}
+
+	return nil
+}
+
+func infoCommand(conf config.Config, version string) error {
+	return info.Print(conf, version)
+}
+
+func helpCommand(logger *log.Logger, asdfVersion, tool, version string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	if tool != "" {
+		if version != "" {
+			err := help.PrintToolVersion(conf, tool, version)
+			if err != nil {
+				os.Exit(1)
+			}
+			return err
+		}
+
+		err := help.PrintTool(conf, tool)
+		if err != nil {
+			os.Exit(1)
+		}
+		return err
+	}
+
+	allPlugins, err := plugins.List(conf, false, false)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	err = help.Print(asdfVersion, allPlugins)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	return err
+}
+
+func pluginUpdateCommand(cCtx *cli.Context, logger *log.Logger, pluginName, ref string) error {
+	updateAll := cCtx.Bool("all")
+	if !updateAll && pluginName == "" {
+		return cli.Exit("usage: asdf plugin-update {<name> [git-ref] | --all}", 1)
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	if updateAll {
+		installedPlugins, err := plugins.List(conf, false, false)
+		if err != nil {
+			logger.Printf("failed to get plugin list: %s", err)
+			return err
+		}
+
+		results := plugins.UpdateAll(conf, installedPlugins, cCtx.Int("concurrency"), os.Stdout, os.Stderr)
+		err = writeUpdateSummary(os.Stdout, results)
+		if err != nil {
+			logger.Printf("unable to write update summary: %s", err)
+			return err
+		}
+
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			err := fmt.Errorf("%d of %d plugins failed to update", failed, len(results))
+			logger.Printf("%s", err)
+			return err
+		}
+
+		return nil
+	}
+
+	plugin := plugins.New(conf, pluginName)
 	updatedToRef, err := plugin.Update(conf, ref, os.Stdout, os.Stderr)
+	if _, linked := plugin.Linked(); linked && err == nil {
+		// Update has already explained why the plugin was skipped
+		return nil
+	}
+
 	formatUpdateResult(logger, pluginName, updatedToRef, err)
 	return err
 }
 
-func pluginTestCommand(l *log.Logger, args []string, toolVersion, ref string) {
+func pluginInfoCommand(logger *log.Logger, pluginName string, asJSON, checkUpdate bool) error {
+	if pluginName == "" {
+		return cli.Exit("usage: asdf plugin info [--json] [--no-update-check] <name>", 1)
+	}
+
 	conf, err := config.LoadConfig()
 	if err != nil {
-		l.Printf("error loading config: %s", err)
-		os.Exit(1)
-		return
+		logger.Printf("error loading config: %s", err)
+		return err
 	}
 
-	if len(args) < 2 {
-		failTest(l, "please provide a plugin name and url")
+	plugin, err := loadPlugin(logger, conf, pluginName)
+	if err != nil {
+		return err
+	}
+
+	pluginInfo, err := info.Plugin(conf, plugin, checkUpdate)
+	if err != nil {
+		logger.Printf("unable to load plugin info: %s", err)
+		return err
+	}
+
+	if asJSON {
+		return info.WritePluginJSON(pluginInfo, os.Stdout)
+	}
+
+	return info.WritePlugin(pluginInfo, os.Stdout)
+}
+
+func pluginConfigCommand(logger *log.Logger, pluginName, action string, args []string) error {
+	usage := "usage: asdf plugin config <name> list|get <key>|set <key> <value>"
+	if pluginName == "" || action == "" {
+		return cli.Exit(usage, 1)
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	if _, err := loadPlugin(logger, conf, pluginName); err != nil {
+		return err
+	}
+
+	switch {
+	case action == "list" && len(args) == 2:
+		options, err := conf.PluginOptions(pluginName)
+		if err != nil {
+			logger.Printf("error loading plugin options: %s", err)
+			return err
+		}
+
+		keys, _ := conf.PluginOptionKeys(pluginName)
+		for _, key := range keys {
+			fmt.Printf("%s = %s\n", key, options[key])
+		}
+
+		return nil
+	case action == "get" && len(args) == 3:
+		options, err := conf.PluginOptions(pluginName)
+		if err != nil {
+			logger.Printf("error loading plugin options: %s", err)
+			return err
+		}
+
+		value, ok := options[args[2]]
+		if !ok {
+			return cli.Exit(fmt.Sprintf("option %s not set for plugin %s", args[2], pluginName), 1)
+		}
+
+		fmt.Println(value)
+		return nil
+	case action == "set" && len(args) == 4:
+		err := conf.SetPluginOption(pluginName, args[2], args[3])
+		if err != nil {
+			logger.Printf("error setting plugin option: %s", err)
+		}
+
+		return err
+	default:
+		return cli.Exit(usage, 1)
 	}
+}
 
-	name := args[0]
-	url := args[1]
-	testName := fmt.Sprintf("asdf-test-%s", name)
+func configLayerFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "project",
+			Usage: "Write to the project .asdfrc file",
+		},
+		&cli.BoolFlag{
+			Name:  "user",
+			Usage: "Write to the user's asdfrc file (default)",
+		},
+	}
+}
 
-	// Install plugin
-	err = plugins.Add(conf, testName, url, ref)
+// loadConfigWithWarnings loads the config along with its settings, so warnings
+// about unknown settings and invalid values are printed even if the command
+// doesn't read any setting
+func loadConfigWithWarnings(logger *log.Logger) (config.Config, error) {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return conf, err
+	}
+
+	// Loading the settings writes the warnings
+	_, err = conf.Warnings()
 	if err != nil {
-		failTest(l, fmt.Sprintf("%s was not properly installed", name))
+		logger.Printf("error loading settings: %s", err)
+		return conf, err
 	}
 
-	// Remove plugin
-	var blackhole strings.Builder
-	defer plugins.Remove(conf, testName, &blackhole, &blackhole)
+	return conf, nil
+}
 
-	// Assert callbacks are present
-	plugin := plugins.New(conf, testName)
-	files, err := os.ReadDir(filepath.Join(plugin.Dir, "bin"))
-	if _, ok := err.(*fs.PathError); ok {
-		failTest(l, "bin/ directory does not exist")
+func configListCommand(logger *log.Logger, showOrigin bool) error {
+	conf, err := loadConfigWithWarnings(logger)
+	if err != nil {
+		return err
 	}
 
-	callbacks := []string{}
-	for _, file := range files {
-		callbacks = append(callbacks, file.Name())
+	values, _ := conf.Values()
+	set := map[string]config.Value{}
+	for _, value := range values {
+		set[value.Name()] = value
 	}
 
-	for _, expectedCallback := range []string{"download", "install", "list-all"} {
-		if !slices.Contains(callbacks, expectedCallback) {
-			failTest(l, fmt.Sprintf("missing callback %s", expectedCallback))
+	printValue := func(value config.Value) {
+		if showOrigin {
+			fmt.Printf("%s\t", value.Origin)
 		}
+		fmt.Printf("%s = %s\n", value.Name(), value.Value)
 	}
 
-	allCallbacks := []string{"download", "install", "list-all", "latest-stable", "help.overview", "help.deps", "help.config", "help.links", "list-bin-paths", "exec-env", "exec-path", "uninstall", "list-legacy-filenames", "parse-legacy-file", "post-plugin-add", "post-plugin-update", "pre-plugin-remove"}
+	for _, setting := range config.Schema {
+		value, ok := set[setting.Key]
+		if !ok {
+			value = config.Value{Key: setting.Key, Value: setting.Default, Origin: config.Origin{Layer: config.LayerDefault}}
+		}
 
-	// Assert all callbacks present are executable
-	for _, file := range files {
-		// file is a callback...
-		if slices.Contains(allCallbacks, file.Name()) {
-			// check if it is executable
-			info, _ := file.Info()
-			if !(info.Mode()&0o111 != 0) {
-				failTest(l, fmt.Sprintf("callback lacks executable permission: %s", file.Name()))
-			}
+		printValue(value)
+	}
+
+	for _, value := range values {
+		if _, ok := config.LookupSetting(value.Name()); !ok {
+			printValue(value)
 		}
 	}
 
-	// Assert has license
-	licensePath := filepath.Join(plugin.Dir, "LICENSE")
-	if _, err := os.Stat(licensePath); errors.Is(err, os.ErrNotExist) {
-		failTest(l, "LICENSE file must be present in the plugin repository")
+	return nil
+}
+
+func configGetCommand(logger *log.Logger, key string) error {
+	if key == "" {
+		return cli.Exit("usage: asdf config get <key>", 1)
 	}
 
-	bytes, err := os.ReadFile(licensePath)
+	conf, err := loadConfigWithWarnings(logger)
 	if err != nil {
-		failTest(l, "LICENSE file must be present in the plugin repository")
+		return err
+	}
+
+	values, _ := conf.Values()
+	for _, value := range values {
+		if value.Name() == key {
+			fmt.Println(value.Value)
+			return nil
+		}
 	}
 
-	// Validate license file not empty
-	if len(bytes) == 0 {
-		failTest(l, "LICENSE file in the plugin repository must not be empty")
+	if setting, ok := config.LookupSetting(key); ok {
+		fmt.Println(setting.Default)
+		return nil
 	}
 
-	// Validate it returns at least one available version
-	var output strings.Builder
-	err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &output, &blackhole)
+	return cli.Exit(fmt.Sprintf("%s is not set", key), 1)
+}
+
+func configSetCommand(logger *log.Logger, project bool, key, value string, argCount int) error {
+	if argCount != 2 {
+		return cli.Exit("usage: asdf config set [--project|--user] <key> <value>", 1)
+	}
+
+	conf, err := loadConfigWithWarnings(logger)
 	if err != nil {
-		failTest(l, "Unable to list available versions")
+		return err
 	}
 
-	allVersions := strings.Fields(output.String())
-	if len(allVersions) < 1 {
-		failTest(l, "list-all did not return any version")
+	layer := configLayer(project)
+	err = conf.Set(layer, key, value)
+	if err != nil {
+		logger.Printf("error setting %s: %s", key, err)
+		return err
 	}
 
-	// grab first version returned by list-all callback if no version provided as
-	// a CLI argument
-	if toolVersion == "" {
-		toolVersion = allVersions[0]
+	warnOverridden(logger, conf, layer, key)
+	return nil
+}
+
+func configUnsetCommand(logger *log.Logger, project bool, key string, argCount int) error {
+	if argCount != 1 {
+		return cli.Exit("usage: asdf config unset [--project|--user] <key>", 1)
 	}
 
-	err = versions.InstallOneVersion(conf, plugin, toolVersion, false, os.Stdout, os.Stderr)
+	conf, err := loadConfigWithWarnings(logger)
 	if err != nil {
-		failTest(l, "install exited with an error")
+		return err
 	}
+
+	layer := configLayer(project)
+	err = conf.Unset(layer, key)
+	if err != nil {
+		logger.Printf("error unsetting %s: %s", key, err)
+		return err
+	}
+
+	warnOverridden(logger, conf, layer, key)
+	return nil
+}
+
+func configLayer(project bool) string {
+	if project {
+		return config.LayerProject
+	}
+
+	return config.LayerUser
+}
+
+// warnOverridden tells the user when a setting they just changed is still
+// overridden by a later layer, like an environment variable
+func warnOverridden(logger *log.Logger, conf config.Config, layer, key string) {
+	origin, ok := conf.Origin(key)
+	if ok && origin.Overrides(layer) {
+		logger.Printf("warning: %s is overridden by %s", key, origin)
+	}
+}
+
+func pluginVerifyCommand(logger *log.Logger, pluginName string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	policy, err := plugins.LoadPolicy(conf)
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	if !policy.Enabled() {
+		fmt.Println("No plugin policy configured")
+		return nil
+	}
+
+	var toVerify []plugins.Plugin
+	if pluginName != "" {
+		plugin, err := loadPlugin(logger, conf, pluginName)
+		if err != nil {
+			return err
+		}
+		toVerify = []plugins.Plugin{plugin}
+	} else {
+		toVerify, err = plugins.List(conf, false, false)
+		if err != nil {
+			logger.Printf("error loading plugin list: %s", err)
+			return err
+		}
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 2, ' ', 0)
+	for _, plugin := range toVerify {
+		if err := plugin.Verify(); err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tFAILED\t%s\n", plugin.Name, err)
+		} else {
+			fmt.Fprintf(w, "%s\tOK\t\n", plugin.Name)
+		}
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d plugins violate the plugin policy", failed)
+	}
+
+	return nil
 }
 
-func failTest(logger *log.Logger, msg string) {
-	logger.Printf("FAILED: %s", msg)
-	os.Exit(1)
+func pluginTestCommand(l *log.Logger, args []string, toolVersion, ref, junitFile string) error {
+	if len(args) < 2 {
+		l.Print("FAILED: please provide a plugin name and url")
+		return cli.Exit("", 1)
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		l.Printf("error loading config: %s", err)
+		return err
+	}
+
+	options := plugintest.Options{
+		Name:        args[0],
+		URL:         args[1],
+		Ref:         ref,
+		ToolVersion: toolVersion,
+		Command:     args[2:],
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		options.AsdfDir = filepath.Dir(executable)
+	}
+
+	report, err := plugintest.Run(conf, options, os.Stdout, os.Stderr)
+	if err != nil {
+		l.Printf("unable to run plugin tests: %s", err)
+		return err
+	}
+
+	fmt.Println()
+	report.WriteText(os.Stdout)
+
+	if junitFile != "" {
+		file, err := os.Create(junitFile)
+		if err != nil {
+			l.Printf("unable to create JUnit report: %s", err)
+			return err
+		}
+		defer file.Close()
+
+		err = report.WriteJUnit(file)
+		if err != nil {
+			l.Printf("unable to write JUnit report: %s", err)
+			return err
+		}
+	}
+
+	if report.Failed() > 0 {
+		return cli.Exit("", 1)
+	}
+
+	return nil
+}
+
+// extractTrailingFlags removes the flags named in the map, along with their
+// values, from args and stores the values in the map. The flag parser stops at
+// the first positional argument but flags may be given anywhere in the
+// `asdf plugin test` command line, as they could with previous versions of asdf.
+func extractTrailingFlags(args []string, flags map[string]string) (remaining []string) {
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimPrefix(args[i], "--")
+		if _, ok := flags[name]; ok && strings.HasPrefix(args[i], "--") && i+1 < len(args) {
+			flags[name] = args[i+1]
+			i++
+			continue
+		}
+
+		remaining = append(remaining, args[i])
+	}
+
+	return remaining
 }
 
 func formatUpdateResult(logger *log.Logger, pluginName, updatedToRef string, err error) {
@@ -1043,7 +1759,43 @@ func formatUpdateResult(logger *log.Logger, pluginName, updatedToRef string, err
 	logger.Printf("updated %s to ref %s\n", pluginName, updatedToRef)
 }
 
-func installCommand(logger *log.Logger, toolName, version string, keepDownload bool) error {
+func writeUpdateSummary(output io.Writer, results []plugins.UpdateResult) error {
+	w := tabwriter.NewWriter(output, 10, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "Name", "Old SHA", "New SHA", "Status")
+
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, shortSHA(result.OldSHA), shortSHA(result.NewSHA), updateStatus(result))
+	}
+
+	return w.Flush()
+}
+
+func updateStatus(result plugins.UpdateResult) string {
+	switch {
+	case result.Err != nil:
+		return fmt.Sprintf("failed: %s", result.Err)
+	case result.Linked:
+		return "skipped (linked)"
+	case result.OldSHA == result.NewSHA:
+		return "up to date"
+	default:
+		return "updated"
+	}
+}
+
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "-"
+	}
+
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
+
+func installCommand(logger *log.Logger, toolName, version string, keepDownload, refreshRefs bool) error {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
@@ -1057,7 +1809,12 @@ func installCommand(logger *log.Logger, toolName, version string, keepDownload b
 
 	if toolName == "" {
 		// Install all versions
-		errs := versions.InstallAll(conf, dir, os.Stdout, os.Stderr)
+		installAll := versions.InstallAll
+		if refreshRefs {
+			installAll = versions.RefreshAll
+		}
+
+		errs := installAll(conf, dir, os.Stdout, os.Stderr)
 		if len(errs) > 0 {
 			for _, err := range errs {
 				// Don't print error if no version set, this just means the current
@@ -1079,7 +1836,12 @@ func installCommand(logger *log.Logger, toolName, version string, keepDownload b
 		plugin := plugins.New(conf, toolName)
 
 		if version == "" {
-			err = versions.Install(conf, plugin, dir, os.Stdout, os.Stderr)
+			install := versions.Install
+			if refreshRefs {
+				install = versions.Refresh
+			}
+
+			err = install(conf, plugin, dir, os.Stdout, os.Stderr)
 			if err != nil {
 				if _, ok := err.(versions.NoVersionSetError); ok {
 					logger.Printf("No versions specified for %s in config files or environment", toolName)
@@ -1093,11 +1855,16 @@ func installCommand(logger *log.Logger, toolName, version string, keepDownload b
 
 			if parsedVersion.Type == "latest" {
 				err = versions.InstallVersion(conf, plugin, parsedVersion, os.Stdout, os.Stderr)
+			} else if parsedVersion.Type == "ref" && refreshRefs {
+				_, err = versions.RefreshRef(conf, plugin, parsedVersion, os.Stdout, os.Stderr)
 			} else {
-				// Adding this here to get tests passing. The other versions.Install*
-				// calls here could have a keepDownload argument added as well. PR
-				// welcome!
-				err = versions.InstallOneVersion(conf, plugin, version, keepDownload, os.Stdout, os.Stderr)
+				version, err = versions.ResolveAvailable(plugin, version)
+				if err == nil {
+					// Adding this here to get tests passing. The other versions.Install*
+					// calls here could have a keepDownload argument added as well. PR
+					// welcome!
+					err = versions.InstallOneVersion(conf, plugin, version, keepDownload, os.Stdout, os.Stderr)
+				}
 			}
 
 			if err != nil {
@@ -1119,7 +1886,7 @@ func filterInstallErrors(errs []error) []error {
 	return filtered
 }
 
-func latestCommand(logger *log.Logger, all bool, toolName, pattern string) (err error) {
+func latestCommand(logger *log.Logger, all, includePrereleases bool, toolName, pattern string) (err error) {
 	conf, err := config.LoadConfig()
 	if err != nil {
 		logger.Printf("error loading config: %s", err)
@@ -1127,7 +1894,7 @@ func latestCommand(logger *log.Logger, all bool, toolName, pattern string) (err
 	}
 
 	if !all {
-		err = latestForPlugin(conf, toolName, pattern, false)
+		err = latestForPlugin(conf, toolName, pattern, includePrereleases, false)
 		if err != nil {
 			os.Exit(1)
 		}
@@ -1144,7 +1911,7 @@ func latestCommand(logger *log.Logger, all bool, toolName, pattern string) (err
 	var maybeErr error
 	// loop over all plugins and show latest for each one.
 	for _, plugin := range plugins {
-		maybeErr = latestForPlugin(conf, plugin.Name, "", true)
+		maybeErr = latestForPlugin(conf, plugin.Name, "", includePrereleases, true)
 		if maybeErr != nil {
 			err = maybeErr
 		}
@@ -1157,6 +1924,68 @@ func latestCommand(logger *log.Logger, all bool, toolName, pattern string) (err
 	return nil
 }
 
+// outdatedCommand compares installed ref versions with the commits their refs
+// currently point to upstream
+func outdatedCommand(logger *log.Logger, toolName string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	var toCheck []plugins.Plugin
+	if toolName == "" {
+		toCheck, err = plugins.List(conf, false, false)
+		if err != nil {
+			logger.Printf("error loading plugin list: %s", err)
+			return err
+		}
+	} else {
+		plugin := plugins.New(conf, toolName)
+		if err := plugin.Exists(); err != nil {
+			logger.Printf("%s", err)
+			return err
+		}
+
+		toCheck = []plugins.Plugin{plugin}
+	}
+
+	var failures []error
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", "Name", "Version", "Installed", "Upstream", "Status")
+	for _, plugin := range toCheck {
+		statuses, err := versions.RefStatuses(conf, plugin)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("unable to check %s: %w", plugin.Name, err))
+			continue
+		}
+
+		for _, status := range statuses {
+			state := "up to date"
+			switch {
+			case status.Err != nil:
+				state = "error"
+				failures = append(failures, status.Err)
+			case status.Outdated():
+				state = "outdated"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", plugin.Name, "ref:"+status.Version.Value, shortSHA(status.Installed), shortSHA(status.Upstream), state)
+		}
+	}
+	w.Flush()
+
+	for _, failure := range failures {
+		logger.Printf("%s", failure)
+	}
+
+	if len(failures) > 0 {
+		return failures[0]
+	}
+
+	return nil
+}
+
 func listCommand(logger *log.Logger, first, second, third string) (err error) {
 	conf, err := config.LoadConfig()
 	if err != nil {
@@ -1186,33 +2015,30 @@ func listAllCommand(logger *log.Logger, conf config.Config, toolName, filter str
 		return err
 	}
 
-	var stdout strings.Builder
-	var stderr strings.Builder
+	allVersions, err := versions.AllVersions(plugin)
+	if _, ok := err.(plugins.PolicyViolationError); ok {
+		logger.Printf("%s", err)
+		os.Exit(1)
+		return err
+	}
 
-	err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stderr)
 	if err != nil {
-		fmt.Printf("Plugin %s's list-all callback script failed with output:\n", plugin.Name)
-		// Print to stderr
-		os.Stderr.WriteString(stderr.String())
-		os.Stderr.WriteString(stdout.String())
-
+		logger.Printf("Plugin %s's list-all callback failed: %s", plugin.Name, err)
 		os.Exit(1)
 		return err
 	}
 
-	versions := strings.Split(stdout.String(), " ")
-
 	if filter != "" {
-		versions = filterByExactMatch(versions, filter)
+		allVersions = filterByExactMatch(allVersions, filter)
 	}
 
-	if len(versions) == 0 {
+	if len(allVersions) == 0 {
 		logger.Printf("No compatible versions available (%s %s)", plugin.Name, filter)
 		os.Exit(1)
 		return nil
 	}
 
-	for _, version := range versions {
+	for _, version := range allVersions {
 		fmt.Printf("%s\n", version)
 	}
 
@@ -1243,6 +2069,7 @@ func listLocalCommand(logger *log.Logger, conf config.Config, pluginName, filter
 			return err
 		}
 		versions, _ := installs.Installed(conf, plugin)
+		versioncmp.Sort(versions)
 
 		if filter != "" {
 			versions = filterByExactMatch(versions, filter)
@@ -1276,16 +2103,25 @@ func listLocalCommand(logger *log.Logger, conf config.Config, pluginName, filter
 		return err
 	}
 
-	for _, plugin := range allPlugins {
+	results, err := resolve.AllVersions(conf, allPlugins, currentDir)
+	if err != nil {
+		logger.Printf("unable to resolve versions: %s", err)
+		os.Exit(1)
+		return err
+	}
+
+	for _, result := range results {
+		plugin := result.Plugin
 		fmt.Printf("%s\n", plugin.Name)
 		versions, _ := installs.Installed(conf, plugin)
+		versioncmp.Sort(versions)
 
 		if len(versions) > 0 {
-			currentVersions, _, err := resolve.Version(conf, plugin, currentDir)
-			if err != nil {
+			if result.Err != nil {
 				os.Exit(1)
-				return err
+				return result.Err
 			}
+			currentVersions := result.Versions
 			for _, version := range versions {
 				if slices.Contains(currentVersions.Versions, version) {
 					fmt.Printf(" *%s\n", version)
@@ -1385,6 +2221,53 @@ func whichCommand(logger *log.Logger, command string) error {
 	return nil
 }
 
+// whichExplainCommand shows how the executable for the command was picked: the
+// versions the shim has executables for, the versions each tool resolved to
+// and how, and the versions left after intersecting the two
+func whichExplainCommand(logger *log.Logger, command string) error {
+	conf, err := config.LoadConfig()
+	if err != nil {
+		logger.Printf("error loading config: %s", err)
+		return err
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		logger.Printf("unable to get current directory: %s", err)
+		return err
+	}
+
+	if command == "" {
+		fmt.Println("usage: asdf which --explain <command>")
+		return errors.New("must provide command")
+	}
+
+	path, explanation, err := shims.ExplainExecutable(conf, command, currentDir)
+	if _, ok := err.(shims.UnknownCommandError); ok {
+		logger.Printf("unknown command: %s. Perhaps you have to reshim?", command)
+		return errors.New("command not found")
+	}
+
+	for _, tool := range explanation.Tools {
+		fmt.Println(tool.Name)
+		fmt.Printf("  shim versions: %s\n", formatVersions(tool.ShimVersions))
+		if tool.Found {
+			fmt.Printf("  resolved:      %s (%s)\n", formatVersions(tool.Versions.Versions), formatSource(tool.Versions, tool.Found))
+			fmt.Printf("  candidates:    %s\n", formatVersions(tool.Candidates))
+		}
+		writeTrace(os.Stdout, tool.Trace)
+		fmt.Println()
+	}
+
+	if err != nil {
+		logger.Printf("%s", err)
+		return err
+	}
+
+	fmt.Printf("executable: %s\n", path)
+	return nil
+}
+
 func uninstallCommand(logger *log.Logger, tool, version string) error {
 	if tool == "" || version == "" {
 		logger.Print("No plugin given")
@@ -1441,7 +2324,7 @@ func whereCommand(logger *log.Logger, tool, versionStr string) error {
 		return err
 	}
 
-	version := toolversions.Parse(versionStr)
+	version := toolversions.ExpandPath(toolversions.Parse(versionStr), currentDir)
 
 	if version.Type == "system" {
 		logger.Printf("System version is selected")
@@ -1457,8 +2340,8 @@ func whereCommand(logger *log.Logger, tool, versionStr string) error {
 		}
 
 		if found && len(versions.Versions) > 0 {
-			versionStruct := toolversions.Version{Type: "version", Value: versions.Versions[0]}
-			if installs.IsInstalled(conf, plugin, versionStruct) {
+			versionStruct := toolversions.Parse(versions.Versions[0])
+			if versionStruct.Type != "system" && installs.IsInstalled(conf, plugin, versionStruct) {
 				installPath := installs.InstallPath(conf, plugin, versionStruct)
 				fmt.Printf("%s", installPath)
 				return nil
@@ -1494,14 +2377,20 @@ func loadPlugin(logger *log.Logger, conf config.Config, pluginName string) (plug
 }
 
 func reshimToolVersion(conf config.Config, tool, versionStr string, out io.Writer, errOut io.Writer) error {
-	version := toolversions.Parse(versionStr)
+	version := toolversions.ExpandPath(toolversions.Parse(versionStr), "")
 	return shims.GenerateForVersion(conf, plugins.New(conf, tool), version, out, errOut)
 }
 
-func latestForPlugin(conf config.Config, toolName, pattern string, showStatus bool) error {
+func latestForPlugin(conf config.Config, toolName, pattern string, includePrereleases, showStatus bool) error {
 	// show single plugin
 	plugin := plugins.New(conf, toolName)
-	latest, err := versions.Latest(plugin, pattern)
+	var latest string
+	var err error
+	if includePrereleases {
+		latest, err = versions.LatestIncludingPrereleases(plugin, pattern)
+	} else {
+		latest, err = versions.Latest(plugin, pattern)
+	}
 	if err != nil && err.Error() != "no latest version found" {
 		fmt.Printf("unable to load latest version: %s\n", err)
 		return err
@@ -9,6 +9,7 @@ require (
 	github.com/rogpeppe/go-internal v1.11.0
 	github.com/sethvargo/go-envconfig v1.0.0
 	github.com/stretchr/testify v1.8.4
+	github.com/tetratelabs/wazero v1.8.2
 	github.com/urfave/cli/v2 v2.27.1
 	golang.org/x/sys v0.15.0
 	gopkg.in/ini.v1 v1.67.0
@@ -5,6 +5,9 @@ package config
 import (
 	"context"
 	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -15,12 +18,27 @@ import (
 
 const (
 	forcePrependDefault                = false
-	dataDirDefault                     = "~/.asdf"
-	configFileDefault                  = "~/.asdfrc"
 	defaultToolVersionsFilenameDefault = ".tool-versions"
 	defaultPluginIndexURL              = "https://github.com/asdf-vm/asdf-plugins.git"
+	pluginPolicyFileDefault            = "/etc/asdf/plugin-policy"
+	systemToolVersionsFileDefault      = "/etc/asdf/tool-versions"
+	pluginPinsSection                  = "plugin_pins"
+	legacyVersionFilesSection          = "legacy_version_files"
 )
 
+// legacyVersionFilesDefault maps the legacy version files asdf can parse itself
+// to the plugins they belong to
+var legacyVersionFilesDefault = map[string]string{
+	".nvmrc":              "nodejs",
+	".node-version":       "nodejs",
+	".python-version":     "python",
+	".ruby-version":       "ruby",
+	".java-version":       "java",
+	".terraform-version":  "terraform",
+	"go.mod":              "golang",
+	"rust-toolchain.toml": "rust",
+}
+
 /* PluginRepoCheckDuration represents the remote plugin repo check duration
 * (never or every N seconds). It's not clear to me how this should be
 * represented in Golang so using a struct for maximum flexibility. */
@@ -36,10 +54,25 @@ type Config struct {
 	Home                        string
 	ConfigFile                  string `env:"ASDF_CONFIG_FILE, overwrite"`
 	DefaultToolVersionsFilename string `env:"ASDF_DEFAULT_TOOL_VERSIONS_FILENAME, overwrite"`
+	// File to read tool versions from instead of searching the current
+	// directory and its parents
+	ToolVersionsFile string `env:"ASDF_TOOL_VERSIONS_FILE, overwrite"`
+	// Global .tool-versions file, consulted when no .tool-versions file in the
+	// current directory or its parents sets a version
+	GlobalToolVersionsFile string `env:"ASDF_GLOBAL_TOOL_VERSIONS_FILE, overwrite"`
+	// System-wide .tool-versions file, consulted after the global file
+	SystemToolVersionsFile string `env:"ASDF_SYSTEM_TOOL_VERSIONS_FILE, overwrite"`
 	// Unclear if this value will be needed with the golang implementation.
 	// AsdfDir string
 	DataDir      string `env:"ASDF_DATA_DIR, overwrite"`
+	CacheDir     string `env:"ASDF_CACHE_DIR, overwrite"`
 	ForcePrepend bool   `env:"ASDF_FORCE_PREPEND, overwrite"`
+	// System-wide plugin policy file, which may only be writable by admins. It
+	// deliberately can't be set from the environment, as that would let any
+	// user replace the admin's policy.
+	PluginPolicyFile string
+	// System-wide asdfrc file, loaded before the user's asdfrc
+	SystemConfigFile string `env:"ASDF_SYSTEM_CONFIG_FILE, overwrite"`
 	// Field that stores the settings struct if it is loaded
 	Settings       Settings
 	PluginIndexURL string
@@ -56,15 +89,54 @@ type Settings struct {
 	PluginRepositoryLastCheckDuration PluginRepoCheckDuration
 	DisablePluginShortNameRepository  bool
 	Concurrency                       string
+	GitCloneDepth                     int
+	GitTokenEnv                       string
+	GitTokenHosts                     []string
+	GitNetrcFile                      string
+	URLRewrites                       []URLRewrite
+	PluginPolicy                      PluginPolicy
+	// PluginOptions maps plugin names to the options set in their
+	// `[plugin "<name>"]` sections
+	PluginOptions map[string]map[string]string
+	// LegacyVersionFiles maps legacy version file names to the plugins they
+	// belong to
+	LegacyVersionFiles map[string]string
+	// Values are all settings along with where their values came from
+	Values []Value
+	// Ignored are settings from layers that aren't allowed to set them
+	Ignored []Value
+	// Warnings are problems with the settings, like unknown settings or
+	// invalid values
+	Warnings []string
 }
 
-func defaultConfig(dataDir, configFile string) *Config {
+// PluginPolicy restricts which sources plugins may be installed from, and
+// optionally pins plugins to specific commits. AllowedURLs contains glob
+// patterns as understood by path.Match. Pins maps plugin names to commit SHAs.
+type PluginPolicy struct {
+	AllowedURLs []string
+	Pins        map[string]string
+}
+
+// URLRewrite is a rule from a `[url "<base>"]` section in the asdfrc file.
+// URLs starting with any of the InsteadOf prefixes have that prefix replaced
+// with Base, just like Git's url.<base>.insteadOf setting.
+type URLRewrite struct {
+	Base      string
+	InsteadOf []string
+}
+
+func defaultConfig(dirs Dirs) *Config {
 	return &Config{
 		ForcePrepend:                forcePrependDefault,
-		DataDir:                     dataDir,
-		ConfigFile:                  configFile,
+		DataDir:                     dirs.DataDir,
+		CacheDir:                    dirs.CacheDir,
+		ConfigFile:                  dirs.ConfigFile,
 		DefaultToolVersionsFilename: defaultToolVersionsFilenameDefault,
 		PluginIndexURL:              defaultPluginIndexURL,
+		PluginPolicyFile:            pluginPolicyFileDefault,
+		SystemConfigFile:            systemConfigFileDefault,
+		SystemToolVersionsFile:      systemToolVersionsFileDefault,
 	}
 }
 
@@ -114,6 +186,39 @@ func LoadConfig() (Config, error) {
 // Settings struct, which is loaded from file on disk and therefor somewhat
 // "expensive".
 
+// ToolVersionsFilenames returns the names of the files storing tool versions,
+// in order of precedence. A tool's version is read from the first of these
+// files in a directory that has an entry for the tool, so a local file can
+// override a committed one, like `.tool-versions.local:.tool-versions`.
+func (c *Config) ToolVersionsFilenames() []string {
+	filenames := []string{}
+	for _, filename := range strings.Split(c.DefaultToolVersionsFilename, ":") {
+		if filename != "" && !slices.Contains(filenames, filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+
+	if len(filenames) == 0 {
+		return []string{defaultToolVersionsFilenameDefault}
+	}
+
+	return filenames
+}
+
+// FallbackToolVersionsFiles returns the global and system-wide .tool-versions
+// files, in the order they are consulted when no version file in the current
+// directory or its parents sets a version
+func (c *Config) FallbackToolVersionsFiles() []string {
+	files := []string{}
+	for _, file := range []string{c.GlobalToolVersionsFile, c.SystemToolVersionsFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
 // LegacyVersionFile loads the asdfrc if it isn't already loaded and fetches
 // the legacy version file support flag
 func (c *Config) LegacyVersionFile() (bool, error) {
@@ -125,6 +230,21 @@ func (c *Config) LegacyVersionFile() (bool, error) {
 	return c.Settings.LegacyVersionFile, nil
 }
 
+// LegacyVersionFiles returns the legacy version files asdf parses itself,
+// mapped to the plugins they belong to
+func (c *Config) LegacyVersionFiles() (map[string]string, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	if c.Settings.LegacyVersionFiles == nil {
+		return maps.Clone(legacyVersionFilesDefault), nil
+	}
+
+	return maps.Clone(c.Settings.LegacyVersionFiles), nil
+}
+
 // AlwaysKeepDownload loads the asdfrc if it isn't already loaded and fetches
 // the keep downloads boolean flag
 func (c *Config) AlwaysKeepDownload() (bool, error) {
@@ -168,6 +288,79 @@ func (c *Config) Concurrency() (string, error) {
 	return c.Settings.Concurrency, nil
 }
 
+// GitCloneDepth returns the number of commits to fetch when cloning plugin
+// repositories. Zero means full history.
+func (c *Config) GitCloneDepth() (int, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Settings.GitCloneDepth, nil
+}
+
+// GitTokenEnv returns the name of the environment variable holding a token for
+// HTTP(S) Git remotes, along with the hosts the token may be sent to
+func (c *Config) GitTokenEnv() (string, []string, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	return c.Settings.GitTokenEnv, c.Settings.GitTokenHosts, nil
+}
+
+// GitNetrcFile returns the netrc file to read Git credentials from if one is
+// set in the asdfrc file
+func (c *Config) GitNetrcFile() (string, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return "", err
+	}
+
+	return c.Settings.GitNetrcFile, nil
+}
+
+// URLRewrites returns the URL rewrite rules defined in the asdfrc file
+func (c *Config) URLRewrites() ([]URLRewrite, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return []URLRewrite{}, err
+	}
+
+	return c.Settings.URLRewrites, nil
+}
+
+// PluginPolicy returns the plugin policy defined in the asdfrc file
+func (c *Config) PluginPolicy() (PluginPolicy, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return PluginPolicy{}, err
+	}
+
+	return c.Settings.PluginPolicy, nil
+}
+
+// LoadPluginPolicyFile reads a plugin policy from a standalone file, such as the
+// system-wide policy file. The file uses the same keys as the asdfrc file. A
+// missing file results in an empty policy.
+func LoadPluginPolicyFile(path string) (PluginPolicy, error) {
+	if path == "" {
+		return PluginPolicy{}, nil
+	}
+
+	file, err := ini.Load(path)
+	if err != nil {
+		if _, ok := err.(*fs.PathError); ok {
+			return PluginPolicy{}, nil
+		}
+
+		return PluginPolicy{}, err
+	}
+
+	return loadPluginPolicy(file), nil
+}
+
 // GetHook returns a hook command from config if it is there
 func (c *Config) GetHook(hook string) (string, error) {
 	err := c.loadSettings()
@@ -182,68 +375,200 @@ func (c *Config) GetHook(hook string) (string, error) {
 	return "", nil
 }
 
-func (c *Config) loadSettings() error {
-	if c.Settings.Loaded {
-		return nil
+// Values returns every setting along with the layer its value came from
+func (c *Config) Values() ([]Value, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return []Value{}, err
 	}
 
-	settings, err := loadSettings(c.ConfigFile)
-
-	c.Settings = settings
+	return c.Settings.Values, nil
+}
 
+// Origin returns where the value of a setting came from. Settings outside the
+// main section are named `<section>.<key>`.
+func (c *Config) Origin(name string) (Origin, bool) {
+	values, err := c.Values()
 	if err != nil {
-		_, ok := err.(*fs.PathError)
-		if ok {
-			return nil
+		return Origin{}, false
+	}
+
+	for _, value := range values {
+		if value.Name() == name {
+			return value.Origin, true
 		}
+	}
+
+	return Origin{}, false
+}
+
+// loadSettings merges the settings from every layer: the system asdfrc, the
+// user's asdfrc, drop-in files, the project .asdfrc and ASDF_ environment
+// variables
+func (c *Config) loadSettings() error {
+	if c.Settings.Loaded {
+		return nil
+	}
 
+	layers, err := c.loadLayers()
+	if err != nil {
+		c.Settings = *defaultSettings()
 		return err
 	}
 
+	merged, values, ignored := mergeLayers(layers)
+	c.Settings = settingsFromFile(merged)
+	c.Settings.Values = values
+	c.Settings.Ignored = ignored
+	c.Settings.Warnings = validateValues(values, ignored)
+	// Every command loading settings reports problems with them, not just
+	// `asdf config`, so a bad value doesn't silently fall back to the default
+	writeWarnings(c.Settings.Warnings)
+
 	return nil
 }
 
 func loadConfigEnv() (Config, error) {
-	dataDir, err := homedir.Expand(dataDirDefault)
-	if err != nil {
-		return Config{}, err
-	}
-
-	configFile, err := homedir.Expand(configFileDefault)
+	home, err := homedir.Dir()
 	if err != nil {
 		return Config{}, err
 	}
 
-	config := defaultConfig(dataDir, configFile)
+	config := defaultConfig(defaultDirs(home))
 
 	context := context.Background()
 	err = envconfig.Process(context, config)
 
+	// The default depends on the tool versions filenames, which may be set by
+	// an environment variable
+	if config.GlobalToolVersionsFile == "" {
+		filenames := config.ToolVersionsFilenames()
+		config.GlobalToolVersionsFile = filepath.Join(home, filenames[len(filenames)-1])
+	}
+
+	if config.ToolVersionsFile != "" && err == nil {
+		config.ToolVersionsFile, err = filepath.Abs(config.ToolVersionsFile)
+	}
+
 	return *config, err
 }
 
+// loadSettings loads the settings from a single asdfrc file
 func loadSettings(asdfrcPath string) (Settings, error) {
-	settings := defaultSettings()
-
 	// asdfrc is effectively formatted as ini
 	config, err := ini.Load(asdfrcPath)
 	if err != nil {
-		return *settings, err
+		return *defaultSettings(), err
 	}
 
+	return settingsFromFile(config), nil
+}
+
+func settingsFromFile(config *ini.File) Settings {
+	settings := defaultSettings()
+
 	mainConf := config.Section("")
 
 	settings.Raw = mainConf
 
 	settings.Loaded = true
-	settings.PluginRepositoryLastCheckDuration = newPluginRepoCheckDuration(mainConf.Key("plugin_repository_last_check_duration").String())
+	settings.PluginRepositoryLastCheckDuration = newPluginRepoCheckDuration(validValue(mainConf, "plugin_repository_last_check_duration"))
 
 	boolOverride(&settings.LegacyVersionFile, mainConf, "legacy_version_file")
 	boolOverride(&settings.AlwaysKeepDownload, mainConf, "always_keep_download")
 	boolOverride(&settings.DisablePluginShortNameRepository, mainConf, "disable_plugin_short_name_repository")
-	settings.Concurrency = strings.ToLower(mainConf.Key("concurrency").String())
+	settings.Concurrency = strings.ToLower(validValue(mainConf, "concurrency"))
+	settings.GitCloneDepth, _ = strconv.Atoi(validValue(mainConf, "git_clone_depth"))
+	settings.GitTokenEnv = mainConf.Key("git_token_env").String()
+	settings.GitTokenHosts = strings.Fields(strings.ReplaceAll(mainConf.Key("git_token_hosts").String(), ",", " "))
+	settings.GitNetrcFile = mainConf.Key("git_netrc_file").String()
+	if settings.GitNetrcFile != "" {
+		settings.GitNetrcFile, _ = homedir.Expand(settings.GitNetrcFile)
+	}
+	settings.URLRewrites = urlRewrites(config)
+	settings.PluginPolicy = loadPluginPolicy(config)
+	settings.PluginOptions = pluginOptions(config)
+	settings.LegacyVersionFiles = legacyVersionFiles(config)
+
+	return *settings
+}
+
+// legacyVersionFiles returns the default legacy version files along with the
+// ones set in the `[legacy_version_files]` section. Setting a file to an empty
+// value stops asdf from reading it.
+func legacyVersionFiles(config *ini.File) map[string]string {
+	files := maps.Clone(legacyVersionFilesDefault)
+
+	if section, err := config.GetSection(legacyVersionFilesSection); err == nil {
+		for _, key := range section.Keys() {
+			pluginName := strings.TrimSpace(key.String())
+			if pluginName == "" {
+				delete(files, key.Name())
+			} else {
+				files[key.Name()] = pluginName
+			}
+		}
+	}
+
+	return files
+}
+
+func urlRewrites(config *ini.File) (rewrites []URLRewrite) {
+	for _, section := range config.Sections() {
+		base, ok := quotedSectionName(section.Name(), "url")
+		if !ok {
+			continue
+		}
+
+		insteadOf := strings.Fields(section.Key("insteadOf").String())
+		if len(insteadOf) > 0 {
+			rewrites = append(rewrites, URLRewrite{Base: base, InsteadOf: insteadOf})
+		}
+	}
+
+	return rewrites
+}
+
+func loadPluginPolicy(config *ini.File) PluginPolicy {
+	policy := PluginPolicy{
+		AllowedURLs: strings.Fields(config.Section("").Key("plugin_allowed_urls").String()),
+		Pins:        map[string]string{},
+	}
+
+	if pins, err := config.GetSection(pluginPinsSection); err == nil {
+		for _, key := range pins.Keys() {
+			policy.Pins[key.Name()] = strings.TrimSpace(key.String())
+		}
+	}
+
+	return policy
+}
+
+// quotedSectionName parses Git style section names like `url "<name>"` and
+// returns the quoted name if the section has the given prefix
+func quotedSectionName(sectionName, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(sectionName, prefix+" ")
+	if !ok {
+		return "", false
+	}
+
+	rest = strings.TrimSpace(rest)
+	if len(rest) < 2 || !strings.HasPrefix(rest, `"`) || !strings.HasSuffix(rest, `"`) {
+		return "", false
+	}
+
+	return rest[1 : len(rest)-1], true
+}
+
+// validValue returns the value of the setting, or an empty string if the value
+// isn't valid according to the schema so the default is used instead
+func validValue(section *ini.Section, key string) string {
+	value := section.Key(key).String()
+	if setting, ok := LookupSetting(key); ok && setting.Validate(value) != nil {
+		return ""
+	}
 
-	return *settings, nil
+	return value
 }
 
 func boolOverride(field *bool, section *ini.Section, key string) {
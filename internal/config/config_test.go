@@ -1,8 +1,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/mitchellh/go-homedir"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +25,67 @@ func TestLoadConfigEnv(t *testing.T) {
 	assert.Zero(t, config.Home, "Shouldn't set Home property when loading config")
 }
 
+func TestLoadConfigEnvToolVersionsFiles(t *testing.T) {
+	t.Run("defaults global file to tool versions filename in home directory", func(t *testing.T) {
+		home, err := homedir.Dir()
+		assert.Nil(t, err)
+		t.Setenv("ASDF_DEFAULT_TOOL_VERSIONS_FILENAME", ".tools")
+
+		config, err := loadConfigEnv()
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(home, ".tools"), config.GlobalToolVersionsFile)
+		assert.Equal(t, "/etc/asdf/tool-versions", config.SystemToolVersionsFile)
+	})
+
+	t.Run("uses files set by environment variables", func(t *testing.T) {
+		t.Setenv("ASDF_GLOBAL_TOOL_VERSIONS_FILE", "/opt/asdf/global")
+		t.Setenv("ASDF_SYSTEM_TOOL_VERSIONS_FILE", "/opt/asdf/system")
+
+		config, err := loadConfigEnv()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"/opt/asdf/global", "/opt/asdf/system"}, config.FallbackToolVersionsFiles())
+	})
+
+	t.Run("defaults global file to last of several tool versions filenames", func(t *testing.T) {
+		home, err := homedir.Dir()
+		assert.Nil(t, err)
+		t.Setenv("ASDF_DEFAULT_TOOL_VERSIONS_FILENAME", ".tool-versions.local:.tool-versions")
+
+		config, err := loadConfigEnv()
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(home, ".tool-versions"), config.GlobalToolVersionsFile)
+	})
+
+	t.Run("makes forced tool versions file absolute", func(t *testing.T) {
+		t.Setenv("ASDF_TOOL_VERSIONS_FILE", "tool-versions")
+		wd, err := os.Getwd()
+		assert.Nil(t, err)
+
+		config, err := loadConfigEnv()
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(wd, "tool-versions"), config.ToolVersionsFile)
+	})
+}
+
+func TestToolVersionsFilenames(t *testing.T) {
+	tests := []struct {
+		input  string
+		output []string
+	}{
+		{input: "", output: []string{".tool-versions"}},
+		{input: "custom-file", output: []string{"custom-file"}},
+		{input: ".tool-versions.local:.tool-versions", output: []string{".tool-versions.local", ".tool-versions"}},
+		{input: ":a::b:a", output: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			config := Config{DefaultToolVersionsFilename: tt.input}
+			assert.Equal(t, tt.output, config.ToolVersionsFilenames())
+		})
+	}
+}
+
 func TestLoadSettings(t *testing.T) {
 	t.Run("When given invalid path returns error", func(t *testing.T) {
 		settings, err := loadSettings("./foobar")
@@ -46,6 +110,14 @@ func TestLoadSettings(t *testing.T) {
 		assert.True(t, settings.PluginRepositoryLastCheckDuration.Never, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.Zero(t, settings.PluginRepositoryLastCheckDuration.Every, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.True(t, settings.DisablePluginShortNameRepository, "DisablePluginShortNameRepository field has wrong value")
+		assert.Equal(t, 1, settings.GitCloneDepth, "GitCloneDepth field has wrong value")
+		assert.Equal(t, "ASDF_TEST_TOKEN", settings.GitTokenEnv, "GitTokenEnv field has wrong value")
+		assert.Equal(t, []string{"github.com", "gitlab.com"}, settings.GitTokenHosts, "GitTokenHosts field has wrong value")
+		assert.Equal(t, "/tmp/asdf-netrc", settings.GitNetrcFile, "GitNetrcFile field has wrong value")
+		assert.Equal(t, []URLRewrite{{Base: "https://mirror.example.com/github/", InsteadOf: []string{"https://github.com/", "git@github.com:"}}}, settings.URLRewrites, "URLRewrites field has wrong value")
+		assert.Equal(t, []string{"https://github.com/asdf-vm/*", "https://github.com/asdf-community/*"}, settings.PluginPolicy.AllowedURLs, "PluginPolicy field has wrong value")
+		assert.Equal(t, map[string]string{"lua": "0123456789abcdef"}, settings.PluginPolicy.Pins, "PluginPolicy field has wrong value")
+		assert.Equal(t, map[string]map[string]string{"nodejs": {"node_build_flags": "--with-intl", "corepack": "yes"}}, settings.PluginOptions, "PluginOptions field has wrong value")
 	})
 
 	t.Run("When given path to empty file returns settings struct with defaults", func(t *testing.T) {
@@ -58,6 +130,8 @@ func TestLoadSettings(t *testing.T) {
 		assert.False(t, settings.PluginRepositoryLastCheckDuration.Never, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.Equal(t, settings.PluginRepositoryLastCheckDuration.Every, 60, "PluginRepositoryLastCheckDuration field has wrong value")
 		assert.False(t, settings.DisablePluginShortNameRepository, "DisablePluginShortNameRepository field has wrong value")
+		assert.Zero(t, settings.GitCloneDepth, "GitCloneDepth field has wrong value")
+		assert.Empty(t, settings.URLRewrites, "URLRewrites field has wrong value")
 	})
 }
 
@@ -74,6 +148,14 @@ func TestConfigMethods(t *testing.T) {
 		assert.True(t, legacyFile, "Expected LegacyVersionFile to be set")
 	})
 
+	t.Run("Returns LegacyVersionFiles from asdfrc file merged with defaults", func(t *testing.T) {
+		files, err := config.LegacyVersionFiles()
+		assert.Nil(t, err, "Returned error when loading settings")
+		assert.Equal(t, "go", files["go.mod"])
+		assert.Equal(t, "nodejs", files[".nvmrc"])
+		assert.NotContains(t, files, ".python-version")
+	})
+
 	t.Run("Returns AlwaysKeepDownload from asdfrc file", func(t *testing.T) {
 		alwaysKeepDownload, err := config.AlwaysKeepDownload()
 		assert.Nil(t, err, "Returned error when loading settings")
@@ -115,6 +197,26 @@ func TestConfigMethods(t *testing.T) {
 		shortName, err := config.DisablePluginShortNameRepository()
 		assert.Nil(t, err)
 		assert.False(t, shortName)
+
+		files, err := config.LegacyVersionFiles()
+		assert.Nil(t, err)
+		assert.Equal(t, legacyVersionFilesDefault, files)
+	})
+}
+
+func TestLoadPluginPolicyFile(t *testing.T) {
+	t.Run("returns empty policy when file does not exist", func(t *testing.T) {
+		policy, err := LoadPluginPolicyFile("testdata/non-existent")
+		assert.Nil(t, err)
+		assert.Empty(t, policy.AllowedURLs)
+		assert.Empty(t, policy.Pins)
+	})
+
+	t.Run("reads policy from file", func(t *testing.T) {
+		policy, err := LoadPluginPolicyFile("testdata/asdfrc")
+		assert.Nil(t, err)
+		assert.Len(t, policy.AllowedURLs, 2)
+		assert.Equal(t, "0123456789abcdef", policy.Pins["lua"])
 	})
 }
 
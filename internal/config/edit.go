@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+// LayerFile returns the file settings are written to for the user or project
+// layer. For the project layer this is the nearest .asdfrc file in the current
+// directory or its parents, or a new .asdfrc file in the current directory.
+func (c *Config) LayerFile(layer string) (string, error) {
+	switch layer {
+	case LayerUser:
+		return c.ConfigFile, nil
+	case LayerProject:
+		if projectFile, ok := c.projectConfigFile(); ok {
+			return projectFile, nil
+		}
+
+		dir, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, projectConfigFilename), nil
+	default:
+		return "", fmt.Errorf("settings can't be written to the %s layer", layer)
+	}
+}
+
+// Set validates the value against the schema and writes the setting to the
+// file of the user or project layer
+func (c *Config) Set(layer, key, value string) error {
+	if err := checkWritable(layer, key); err != nil {
+		return err
+	}
+
+	if setting, ok := LookupSetting(key); ok {
+		if err := setting.Validate(value); err != nil {
+			return err
+		}
+	}
+
+	path, err := c.LayerFile(layer)
+	if err != nil {
+		return err
+	}
+
+	return c.editFile(path, func(file *ini.File) error {
+		file.Section("").Key(key).SetValue(value)
+		return nil
+	})
+}
+
+// Unset removes the setting from the file of the user or project layer
+func (c *Config) Unset(layer, key string) error {
+	path, err := c.LayerFile(layer)
+	if err != nil {
+		return err
+	}
+
+	return c.editFile(path, func(file *ini.File) error {
+		if !file.Section("").HasKey(key) {
+			return fmt.Errorf("%s is not set in %s", key, path)
+		}
+
+		file.Section("").DeleteKey(key)
+		return nil
+	})
+}
+
+func checkWritable(layer, key string) error {
+	if _, ok := LookupSetting(key); !ok && !isHook(key) {
+		return fmt.Errorf("unknown setting %s", key)
+	}
+
+	if layer == LayerProject && !allowedInProject("", key) {
+		return fmt.Errorf("%s can't be set in a project file", key)
+	}
+
+	return nil
+}
+
+// editFile loads the ini file at path, creating it if it doesn't exist yet,
+// applies edit to it and writes it back
+func (c *Config) editFile(path string, edit func(*ini.File) error) error {
+	file, err := ini.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		file = ini.Empty()
+	} else if err != nil {
+		return fmt.Errorf("unable to load %s: %w", path, err)
+	}
+
+	err = edit(file)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o777)
+	if err != nil {
+		return err
+	}
+
+	err = file.SaveTo(path)
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	// Reload settings the next time they are needed
+	c.Settings.Loaded = false
+	return nil
+}
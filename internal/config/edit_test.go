@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	t.Run("writes setting to user file", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		err := conf.Set(LayerUser, "concurrency", "4")
+		assert.Nil(t, err)
+
+		origin, ok := conf.Origin("concurrency")
+		assert.True(t, ok)
+		assert.Equal(t, Origin{Layer: LayerUser, Source: conf.ConfigFile}, origin)
+
+		concurrency, err := conf.Concurrency()
+		assert.Nil(t, err)
+		assert.Equal(t, "4", concurrency)
+	})
+
+	t.Run("writes setting to nearest project file", func(t *testing.T) {
+		conf, dir := layeredConfig(t)
+		projectFile := filepath.Join(dir, "project", ".asdfrc")
+		writeFile(t, projectFile, "# project settings\nlegacy_version_file = yes\n")
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, "project", "src"), 0o777))
+		chdir(t, filepath.Join(dir, "project", "src"))
+
+		err := conf.Set(LayerProject, "always_keep_download", "yes")
+		assert.Nil(t, err)
+
+		contents, err := os.ReadFile(projectFile)
+		assert.Nil(t, err)
+		assert.Equal(t, "# project settings\nlegacy_version_file  = yes\nalways_keep_download = yes\n", string(contents))
+	})
+
+	t.Run("returns error for unknown setting", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		err := conf.Set(LayerUser, "concurency", "4")
+		assert.EqualError(t, err, "unknown setting concurency")
+	})
+
+	t.Run("returns error for invalid value", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		err := conf.Set(LayerUser, "always_keep_download", "sometimes")
+		assert.EqualError(t, err, `invalid value for always_keep_download: "sometimes", expected yes or no`)
+		assert.NoFileExists(t, conf.ConfigFile)
+	})
+
+	t.Run("returns error for setting denied in project file", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		err := conf.Set(LayerProject, "pre_asdf_install", "echo hi")
+		assert.EqualError(t, err, "pre_asdf_install can't be set in a project file")
+	})
+}
+
+func TestUnset(t *testing.T) {
+	t.Run("removes setting from user file", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+		writeFile(t, conf.ConfigFile, "concurrency = 4\nlegacy_version_file = yes\n")
+
+		err := conf.Unset(LayerUser, "concurrency")
+		assert.Nil(t, err)
+
+		_, ok := conf.Origin("concurrency")
+		assert.False(t, ok)
+
+		legacy, err := conf.LegacyVersionFile()
+		assert.Nil(t, err)
+		assert.True(t, legacy)
+	})
+
+	t.Run("returns error when setting is not set", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		err := conf.Unset(LayerUser, "concurrency")
+		assert.EqualError(t, err, "concurrency is not set in "+conf.ConfigFile)
+	})
+}
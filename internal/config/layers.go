@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Layers settings are loaded from, in the order they are merged. Values from
+// later layers override values from earlier ones.
+const (
+	LayerSystem  = "system"
+	LayerUser    = "user"
+	LayerDropIn  = "drop-in"
+	LayerProject = "project"
+	LayerEnv     = "env"
+	// LayerDefault is the origin of settings no layer sets
+	LayerDefault = "default"
+)
+
+var layerOrder = []string{LayerDefault, LayerSystem, LayerUser, LayerDropIn, LayerProject, LayerEnv}
+
+const (
+	systemConfigFileDefault = "/etc/asdfrc"
+	projectConfigFilename   = ".asdfrc"
+	dropInDirSuffix         = ".d"
+	settingEnvPrefix        = "ASDF_"
+)
+
+// projectDeniedKeys are settings a project .asdfrc file can't set. Project
+// files are checked into repositories the user may not control, so they must
+// not be able to run commands through hooks, weaken the plugin policy or
+// redirect Git credentials.
+var projectDeniedKeys = []string{"plugin_allowed_urls", "git_token_env", "git_token_hosts", "git_netrc_file"}
+
+// Origin records where the value of a setting came from. Source is the path
+// of the file, or the name of the environment variable for the env layer.
+type Origin struct {
+	Layer  string
+	Source string
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+
+	return fmt.Sprintf("%s:%s", o.Layer, o.Source)
+}
+
+// Overrides returns true if the origin's layer is merged after the given layer,
+// so its values take precedence
+func (o Origin) Overrides(layer string) bool {
+	return slices.Index(layerOrder, o.Layer) > slices.Index(layerOrder, layer)
+}
+
+// Value is a setting along with where its value came from. Section is empty
+// for settings in the main section of the asdfrc file.
+type Value struct {
+	Section string
+	Key     string
+	Value   string
+	Origin  Origin
+}
+
+// Name returns the name of the setting, prefixed with its section if it isn't
+// in the main section
+func (v Value) Name() string {
+	return settingName(v.Section, v.Key)
+}
+
+// layer is a parsed settings file, or the settings from the environment
+type layer struct {
+	origin Origin
+	file   *ini.File
+}
+
+// Layers returns the files settings are loaded from, in the order they are
+// merged, along with the layer each belongs to. Files that don't exist are
+// included so callers can show where settings would be read from.
+func (c *Config) Layers() []Origin {
+	origins := []Origin{}
+
+	if c.SystemConfigFile != "" {
+		origins = append(origins, Origin{Layer: LayerSystem, Source: c.SystemConfigFile})
+	}
+
+	if c.ConfigFile != "" {
+		origins = append(origins, Origin{Layer: LayerUser, Source: c.ConfigFile})
+
+		dropIns, _ := filepath.Glob(filepath.Join(c.ConfigFile+dropInDirSuffix, "*"))
+		sort.Strings(dropIns)
+		for _, dropIn := range dropIns {
+			if fileInfo, err := os.Stat(dropIn); err == nil && fileInfo.Mode().IsRegular() {
+				origins = append(origins, Origin{Layer: LayerDropIn, Source: dropIn})
+			}
+		}
+	}
+
+	if projectFile, ok := c.projectConfigFile(); ok {
+		origins = append(origins, Origin{Layer: LayerProject, Source: projectFile})
+	}
+
+	return origins
+}
+
+// projectConfigFile looks for a .asdfrc file in the current directory and its
+// parents. The user's own asdfrc is skipped when it is found on the way, as it
+// is already loaded as the user layer.
+func (c *Config) projectConfigFile() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+
+	userFileInfo, _ := os.Stat(c.ConfigFile)
+
+	for {
+		path := filepath.Join(dir, projectConfigFilename)
+		if fileInfo, err := os.Stat(path); err == nil && fileInfo.Mode().IsRegular() {
+			if userFileInfo == nil || !os.SameFile(userFileInfo, fileInfo) {
+				return path, true
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// loadLayers parses every layer, skipping files that don't exist
+func (c *Config) loadLayers() ([]layer, error) {
+	layers := []layer{}
+
+	for _, origin := range c.Layers() {
+		file, err := ini.Load(origin.Source)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return layers, fmt.Errorf("unable to load %s: %w", origin.Source, err)
+		}
+
+		layers = append(layers, layer{origin: origin, file: file})
+	}
+
+	return append(layers, envLayer()), nil
+}
+
+// EnvName returns the name of the environment variable that overrides the
+// setting
+func EnvName(key string) string {
+	return settingEnvPrefix + strings.ToUpper(key)
+}
+
+// envLayer returns the settings overridden with ASDF_<KEY> environment
+// variables
+func envLayer() layer {
+	file := ini.Empty()
+	section := file.Section("")
+
+	for _, setting := range Schema {
+		if value, ok := os.LookupEnv(EnvName(setting.Key)); ok {
+			section.Key(setting.Key).SetValue(value)
+		}
+	}
+
+	return layer{origin: Origin{Layer: LayerEnv, Source: settingEnvPrefix + "*"}, file: file}
+}
+
+// mergeLayers merges the layers into a single file. It returns the merged file,
+// every setting in it along with where its value came from, and the settings
+// that were ignored because their layer isn't allowed to set them.
+func mergeLayers(layers []layer) (*ini.File, []Value, []Value) {
+	merged := ini.Empty()
+	values := []Value{}
+	indexes := map[string]int{}
+	ignored := []Value{}
+
+	for _, layer := range layers {
+		for _, section := range layer.file.Sections() {
+			sectionName := section.Name()
+			if sectionName == ini.DefaultSection {
+				sectionName = ""
+			}
+
+			for _, key := range section.Keys() {
+				origin := layer.origin
+				if origin.Layer == LayerEnv {
+					origin.Source = EnvName(key.Name())
+				}
+
+				value := Value{Section: sectionName, Key: key.Name(), Value: key.String(), Origin: origin}
+				if layer.origin.Layer == LayerProject && !allowedInProject(sectionName, key.Name()) {
+					ignored = append(ignored, value)
+					continue
+				}
+
+				merged.Section(sectionName).Key(key.Name()).SetValue(key.String())
+
+				if index, ok := indexes[value.Name()]; ok {
+					values[index] = value
+				} else {
+					indexes[value.Name()] = len(values)
+					values = append(values, value)
+				}
+			}
+		}
+	}
+
+	return merged, values, ignored
+}
+
+func allowedInProject(section, key string) bool {
+	if section == "" {
+		for _, denied := range projectDeniedKeys {
+			if key == denied {
+				return false
+			}
+		}
+
+		return !isHook(key)
+	}
+
+	_, isURLRewrite := quotedSectionName(section, "url")
+	return section != pluginPinsSection && !isURLRewrite
+}
+
+func isHook(key string) bool {
+	return strings.HasPrefix(key, "pre_") || strings.HasPrefix(key, "post_")
+}
+
+func settingName(section, key string) string {
+	if section == "" {
+		return key
+	}
+
+	return section + "." + key
+}
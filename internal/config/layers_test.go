@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o777))
+	assert.Nil(t, os.WriteFile(path, []byte(contents), 0o666))
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	previous, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+func layeredConfig(t *testing.T) (Config, string) {
+	t.Helper()
+	dir := t.TempDir()
+	chdir(t, t.TempDir())
+
+	conf := Config{
+		SystemConfigFile: filepath.Join(dir, "etc", "asdfrc"),
+		ConfigFile:       filepath.Join(dir, "home", ".asdfrc"),
+	}
+
+	return conf, dir
+}
+
+func TestLayers(t *testing.T) {
+	t.Run("returns system and user files when nothing else exists", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		assert.Equal(t, []Origin{
+			{Layer: LayerSystem, Source: conf.SystemConfigFile},
+			{Layer: LayerUser, Source: conf.ConfigFile},
+		}, conf.Layers())
+	})
+
+	t.Run("returns drop-in files in order and project file from parent directory", func(t *testing.T) {
+		conf, dir := layeredConfig(t)
+		writeFile(t, filepath.Join(conf.ConfigFile+".d", "20-b"), "")
+		writeFile(t, filepath.Join(conf.ConfigFile+".d", "10-a"), "")
+		assert.Nil(t, os.Mkdir(filepath.Join(conf.ConfigFile+".d", "subdir"), 0o777))
+
+		projectDir := filepath.Join(dir, "project")
+		writeFile(t, filepath.Join(projectDir, ".asdfrc"), "")
+		assert.Nil(t, os.MkdirAll(filepath.Join(projectDir, "src"), 0o777))
+		chdir(t, filepath.Join(projectDir, "src"))
+
+		assert.Equal(t, []Origin{
+			{Layer: LayerSystem, Source: conf.SystemConfigFile},
+			{Layer: LayerUser, Source: conf.ConfigFile},
+			{Layer: LayerDropIn, Source: filepath.Join(conf.ConfigFile+".d", "10-a")},
+			{Layer: LayerDropIn, Source: filepath.Join(conf.ConfigFile+".d", "20-b")},
+			{Layer: LayerProject, Source: filepath.Join(projectDir, ".asdfrc")},
+		}, conf.Layers())
+	})
+
+	t.Run("does not return user asdfrc as project file", func(t *testing.T) {
+		conf, dir := layeredConfig(t)
+		writeFile(t, conf.ConfigFile, "")
+		chdir(t, filepath.Join(dir, "home"))
+
+		assert.Len(t, conf.Layers(), 2)
+	})
+}
+
+func TestValues(t *testing.T) {
+	t.Run("later layers override earlier layers", func(t *testing.T) {
+		conf, dir := layeredConfig(t)
+		writeFile(t, conf.SystemConfigFile, "legacy_version_file = yes\nconcurrency = 2\ngit_clone_depth = 1\n")
+		writeFile(t, conf.ConfigFile, "concurrency = 4\nalways_keep_download = yes\n")
+		writeFile(t, filepath.Join(conf.ConfigFile+".d", "10-clone"), "git_clone_depth = 10\n")
+		writeFile(t, filepath.Join(dir, "project", ".asdfrc"), "always_keep_download = no\n")
+		chdir(t, filepath.Join(dir, "project"))
+		t.Setenv("ASDF_CONCURRENCY", "8")
+
+		values, err := conf.Values()
+		assert.Nil(t, err)
+		assert.Equal(t, []Value{
+			{Key: "legacy_version_file", Value: "yes", Origin: Origin{Layer: LayerSystem, Source: conf.SystemConfigFile}},
+			{Key: "concurrency", Value: "8", Origin: Origin{Layer: LayerEnv, Source: "ASDF_CONCURRENCY"}},
+			{Key: "git_clone_depth", Value: "10", Origin: Origin{Layer: LayerDropIn, Source: filepath.Join(conf.ConfigFile+".d", "10-clone")}},
+			{Key: "always_keep_download", Value: "no", Origin: Origin{Layer: LayerProject, Source: filepath.Join(dir, "project", ".asdfrc")}},
+		}, values)
+
+		legacy, err := conf.LegacyVersionFile()
+		assert.Nil(t, err)
+		assert.True(t, legacy)
+
+		keepDownload, err := conf.AlwaysKeepDownload()
+		assert.Nil(t, err)
+		assert.False(t, keepDownload)
+
+		concurrency, err := conf.Concurrency()
+		assert.Nil(t, err)
+		assert.Equal(t, "8", concurrency)
+	})
+
+	t.Run("project file can't set hooks or plugin policy", func(t *testing.T) {
+		conf, dir := layeredConfig(t)
+		writeFile(t, conf.ConfigFile, "pre_asdf_install = echo user\n")
+		projectFile := filepath.Join(dir, "project", ".asdfrc")
+		writeFile(t, projectFile, "pre_asdf_install = echo project\nplugin_allowed_urls = https://evil.example.com/*\nconcurrency = 3\n\n[plugin_pins]\nnodejs = abc123\n")
+		chdir(t, filepath.Join(dir, "project"))
+
+		hook, err := conf.GetHook("pre_asdf_install")
+		assert.Nil(t, err)
+		assert.Equal(t, "echo user", hook)
+
+		policy, err := conf.PluginPolicy()
+		assert.Nil(t, err)
+		assert.Empty(t, policy.AllowedURLs)
+		assert.Empty(t, policy.Pins)
+
+		origin, ok := conf.Origin("concurrency")
+		assert.True(t, ok)
+		assert.Equal(t, Origin{Layer: LayerProject, Source: projectFile}, origin)
+
+		names := []string{}
+		for _, value := range conf.Settings.Ignored {
+			names = append(names, value.Name())
+		}
+		assert.Equal(t, []string{"pre_asdf_install", "plugin_allowed_urls", "plugin_pins.nodejs"}, names)
+	})
+
+	t.Run("returns origin of plugin options", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+		writeFile(t, conf.SystemConfigFile, "[plugin \"nodejs\"]\ncorepack = yes\n")
+
+		options, err := conf.PluginOptions("nodejs")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"corepack": "yes"}, options)
+
+		origin, ok := conf.Origin(`plugin "nodejs".corepack`)
+		assert.True(t, ok)
+		assert.Equal(t, Origin{Layer: LayerSystem, Source: conf.SystemConfigFile}, origin)
+	})
+
+	t.Run("returns no origin for unset setting", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+
+		_, ok := conf.Origin("concurrency")
+		assert.False(t, ok)
+	})
+
+	t.Run("returns error for invalid file", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+		writeFile(t, conf.SystemConfigFile, "[unterminated\n")
+
+		_, err := conf.Values()
+		assert.ErrorContains(t, err, "unable to load "+conf.SystemConfigFile)
+	})
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/ini.v1"
+)
+
+// pluginSectionPrefix is the prefix of `[plugin "<name>"]` sections in the
+// asdfrc file, which hold options for a single plugin
+const pluginSectionPrefix = "plugin"
+
+// PluginOptions returns the options set in the plugin's `[plugin "<name>"]`
+// section of the asdfrc file
+func (c *Config) PluginOptions(pluginName string) (map[string]string, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	options := map[string]string{}
+	for key, value := range c.Settings.PluginOptions[pluginName] {
+		options[key] = value
+	}
+
+	return options, nil
+}
+
+// PluginOptionKeys returns the names of the options set for the plugin, sorted
+func (c *Config) PluginOptionKeys(pluginName string) ([]string, error) {
+	options, err := c.PluginOptions(pluginName)
+	if err != nil {
+		return []string{}, err
+	}
+
+	keys := []string{}
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// SetPluginOption writes an option to the plugin's section of the asdfrc file,
+// creating the file and section if they don't exist yet
+func (c *Config) SetPluginOption(pluginName, key, value string) error {
+	return c.editFile(c.ConfigFile, func(file *ini.File) error {
+		file.Section(pluginSectionName(pluginName)).Key(key).SetValue(value)
+		return nil
+	})
+}
+
+func pluginSectionName(pluginName string) string {
+	return fmt.Sprintf(`%s "%s"`, pluginSectionPrefix, pluginName)
+}
+
+func pluginOptions(config *ini.File) map[string]map[string]string {
+	options := map[string]map[string]string{}
+
+	for _, section := range config.Sections() {
+		pluginName, ok := quotedSectionName(section.Name(), pluginSectionPrefix)
+		if !ok {
+			continue
+		}
+
+		if options[pluginName] == nil {
+			options[pluginName] = map[string]string{}
+		}
+
+		for _, key := range section.Keys() {
+			options[pluginName][key.Name()] = key.String()
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginOptions(t *testing.T) {
+	t.Run("returns options from plugin section", func(t *testing.T) {
+		conf := Config{ConfigFile: "testdata/asdfrc"}
+		options, err := conf.PluginOptions("nodejs")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"node_build_flags": "--with-intl", "corepack": "yes"}, options)
+
+		keys, err := conf.PluginOptionKeys("nodejs")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"corepack", "node_build_flags"}, keys)
+	})
+
+	t.Run("returns no options for plugin without section", func(t *testing.T) {
+		conf := Config{ConfigFile: "testdata/asdfrc"}
+		options, err := conf.PluginOptions("ruby")
+		assert.Nil(t, err)
+		assert.Empty(t, options)
+	})
+
+	t.Run("returns no options when asdfrc is missing", func(t *testing.T) {
+		conf := Config{ConfigFile: filepath.Join(t.TempDir(), "asdfrc")}
+		options, err := conf.PluginOptions("nodejs")
+		assert.Nil(t, err)
+		assert.Empty(t, options)
+	})
+}
+
+func TestSetPluginOption(t *testing.T) {
+	t.Run("creates asdfrc with plugin section", func(t *testing.T) {
+		conf := Config{ConfigFile: filepath.Join(t.TempDir(), "asdfrc")}
+		err := conf.SetPluginOption("nodejs", "corepack", "yes")
+		assert.Nil(t, err)
+
+		options, err := conf.PluginOptions("nodejs")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"corepack": "yes"}, options)
+	})
+
+	t.Run("keeps other settings and overwrites existing option", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "asdfrc")
+		err := os.WriteFile(path, []byte("legacy_version_file = yes\n\n[plugin \"nodejs\"]\ncorepack = no\n"), 0o666)
+		assert.Nil(t, err)
+
+		conf := Config{ConfigFile: path}
+		_, err = conf.PluginOptions("nodejs")
+		assert.Nil(t, err)
+
+		err = conf.SetPluginOption("nodejs", "corepack", "yes")
+		assert.Nil(t, err)
+
+		options, err := conf.PluginOptions("nodejs")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"corepack": "yes"}, options)
+
+		legacyVersionFile, err := conf.LegacyVersionFile()
+		assert.Nil(t, err)
+		assert.True(t, legacyVersionFile)
+	})
+}
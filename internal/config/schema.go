@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Types of the values settings accept
+const (
+	TypeBool   = "bool"
+	TypeInt    = "integer"
+	TypeString = "string"
+	TypeList   = "list"
+)
+
+// Setting describes a setting in the main section of the asdfrc file
+type Setting struct {
+	Key  string
+	Type string
+	// Keywords are values accepted in addition to values of the setting's type,
+	// like `auto` for concurrency
+	Keywords []string
+	// Default is the value used when no layer sets the setting
+	Default     string
+	Description string
+}
+
+// Schema lists every known setting in the main section of the asdfrc file
+var Schema = []Setting{
+	{Key: "legacy_version_file", Type: TypeBool, Default: "no", Description: "Read versions from other version managers' files"},
+	{Key: "use_release_candidates", Type: TypeBool, Default: "no", Description: "Unused, kept for compatibility with older versions of asdf"},
+	{Key: "always_keep_download", Type: TypeBool, Default: "no", Description: "Keep source code and binaries after installing"},
+	{Key: "plugin_repository_last_check_duration", Type: TypeInt, Keywords: []string{"never"}, Default: "60", Description: "Minutes between syncs of the plugin index repository"},
+	{Key: "disable_plugin_short_name_repository", Type: TypeBool, Default: "no", Description: "Don't use the plugin index to find plugins by short name"},
+	{Key: "concurrency", Type: TypeInt, Keywords: []string{"auto"}, Default: "auto", Description: "Number of cores to use when compiling"},
+	{Key: "git_clone_depth", Type: TypeInt, Default: "0", Description: "Commits to fetch when cloning plugins, zero for full history"},
+	{Key: "git_token_env", Type: TypeString, Description: "Environment variable holding a token for HTTP(S) Git remotes"},
+	{Key: "git_token_hosts", Type: TypeList, Description: "Hosts the Git token may be sent to"},
+	{Key: "git_netrc_file", Type: TypeString, Description: "netrc file to read Git credentials from"},
+	{Key: "plugin_allowed_urls", Type: TypeList, Description: "Glob patterns plugin URLs must match"},
+}
+
+// LookupSetting returns the setting with the given key from the schema
+func LookupSetting(key string) (Setting, bool) {
+	for _, setting := range Schema {
+		if setting.Key == key {
+			return setting, true
+		}
+	}
+
+	return Setting{}, false
+}
+
+// Validate returns an error if the value isn't valid for the setting
+func (s Setting) Validate(value string) error {
+	for _, keyword := range s.Keywords {
+		if strings.EqualFold(value, keyword) {
+			return nil
+		}
+	}
+
+	valid := true
+	switch s.Type {
+	case TypeBool:
+		lower := strings.ToLower(value)
+		valid = lower == "yes" || lower == "no"
+	case TypeInt:
+		number, err := strconv.Atoi(value)
+		valid = err == nil && number >= 0
+	}
+
+	if !valid {
+		return fmt.Errorf("invalid value for %s: %q, expected %s", s.Key, value, s.expected())
+	}
+
+	return nil
+}
+
+func (s Setting) expected() string {
+	expected := s.Type
+	switch s.Type {
+	case TypeBool:
+		expected = "yes or no"
+	case TypeInt:
+		expected = "a non-negative integer"
+	}
+
+	for _, keyword := range s.Keywords {
+		expected += " or " + keyword
+	}
+
+	return expected
+}
+
+// WarningOutput is where warnings about the settings are written, once per
+// process, when settings are first loaded. Nil silences them.
+var WarningOutput io.Writer = os.Stderr
+
+var printWarnings sync.Once
+
+// writeWarnings writes the warnings to WarningOutput unless warnings were
+// already written
+func writeWarnings(warnings []string) {
+	printWarnings.Do(func() {
+		if WarningOutput == nil {
+			return
+		}
+
+		for _, warning := range warnings {
+			fmt.Fprintf(WarningOutput, "warning: %s\n", warning)
+		}
+	})
+}
+
+// Warnings returns problems with the settings, like unknown settings, invalid
+// values and settings ignored because their layer can't set them. Settings
+// with invalid values fall back to their defaults.
+func (c *Config) Warnings() ([]string, error) {
+	err := c.loadSettings()
+	if err != nil {
+		return []string{}, err
+	}
+
+	return c.Settings.Warnings, nil
+}
+
+func validateValues(values, ignored []Value) []string {
+	warnings := []string{}
+	unknownSections := map[string]bool{}
+
+	for _, value := range values {
+		if value.Section != "" {
+			if !knownSection(value.Section) && !unknownSections[value.Section] {
+				unknownSections[value.Section] = true
+				warnings = append(warnings, fmt.Sprintf("%s: unknown section %s", value.Origin, value.Section))
+			}
+			continue
+		}
+
+		if isHook(value.Key) {
+			continue
+		}
+
+		setting, ok := LookupSetting(value.Key)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: unknown setting %s", value.Origin, value.Key))
+			continue
+		}
+
+		if err := setting.Validate(value.Value); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %s, using default %s", value.Origin, err, setting.Default))
+		}
+	}
+
+	for _, value := range ignored {
+		warnings = append(warnings, fmt.Sprintf("%s: %s can't be set in a project file, ignoring it", value.Origin, value.Name()))
+	}
+
+	return warnings
+}
+
+func knownSection(section string) bool {
+	if section == pluginPinsSection || section == legacyVersionFilesSection {
+		return true
+	}
+
+	for _, prefix := range []string{"url", pluginSectionPrefix} {
+		if _, ok := quotedSectionName(section, prefix); ok {
+			return true
+		}
+	}
+
+	return false
+}
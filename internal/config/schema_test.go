@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingValidate(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		err   string
+	}{
+		{key: "legacy_version_file", value: "yes"},
+		{key: "legacy_version_file", value: "No"},
+		{key: "legacy_version_file", value: "true", err: `invalid value for legacy_version_file: "true", expected yes or no`},
+		{key: "concurrency", value: "auto"},
+		{key: "concurrency", value: "8"},
+		{key: "concurrency", value: "lots", err: `invalid value for concurrency: "lots", expected a non-negative integer or auto`},
+		{key: "plugin_repository_last_check_duration", value: "never"},
+		{key: "plugin_repository_last_check_duration", value: "-1", err: `invalid value for plugin_repository_last_check_duration: "-1", expected a non-negative integer or never`},
+		{key: "git_token_hosts", value: "github.com gitlab.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			setting, ok := LookupSetting(tt.key)
+			assert.True(t, ok)
+
+			err := setting.Validate(tt.value)
+			if tt.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	t.Run("returns no warnings for valid settings and hooks", func(t *testing.T) {
+		conf := Config{ConfigFile: "testdata/asdfrc"}
+		chdir(t, t.TempDir())
+
+		warnings, err := conf.Warnings()
+		assert.Nil(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("returns warnings for unknown settings and sections and invalid values", func(t *testing.T) {
+		conf, _ := layeredConfig(t)
+		writeFile(t, conf.ConfigFile, "legacy_versoin_file = yes\nplugin_repository_last_check_duration = soon\ngit_clone_depth = deep\n\n[unknown]\na = 1\nb = 2\n")
+
+		warnings, err := conf.Warnings()
+		assert.Nil(t, err)
+		origin := "user:" + conf.ConfigFile
+		assert.Equal(t, []string{
+			origin + ": unknown setting legacy_versoin_file",
+			origin + `: invalid value for plugin_repository_last_check_duration: "soon", expected a non-negative integer or never, using default 60`,
+			origin + `: invalid value for git_clone_depth: "deep", expected a non-negative integer, using default 0`,
+			origin + ": unknown section unknown",
+		}, warnings)
+
+		duration, err := conf.PluginRepositoryLastCheckDuration()
+		assert.Nil(t, err)
+		assert.Equal(t, pluginRepoCheckDurationDefault, duration)
+	})
+
+	t.Run("writes warnings once when settings are loaded", func(t *testing.T) {
+		var output strings.Builder
+		WarningOutput = &output
+		printWarnings = sync.Once{}
+		defer func() { WarningOutput = os.Stderr }()
+
+		conf, _ := layeredConfig(t)
+		writeFile(t, conf.ConfigFile, "git_clone_depth = deep\n")
+
+		_, err := conf.GitCloneDepth()
+		assert.Nil(t, err)
+		otherConf := Config{ConfigFile: conf.ConfigFile}
+		_, err = otherConf.GitCloneDepth()
+		assert.Nil(t, err)
+
+		origin := "user:" + conf.ConfigFile
+		assert.Equal(t, "warning: "+origin+`: invalid value for git_clone_depth: "deep", expected a non-negative integer, using default 0`+"\n", output.String())
+	})
+
+	t.Run("returns warnings for settings ignored in project file", func(t *testing.T) {
+		conf, dir := layeredConfig(t)
+		writeFile(t, filepath.Join(dir, "project", ".asdfrc"), "post_asdf_install = rm -rf /\n")
+		chdir(t, filepath.Join(dir, "project"))
+
+		warnings, err := conf.Warnings()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"project:" + filepath.Join(dir, "project", ".asdfrc") + ": post_asdf_install can't be set in a project file, ignoring it"}, warnings)
+	})
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// xdgDirName is the name of asdf's directory inside each XDG base
+	// directory
+	xdgDirName = "asdf"
+	// xdgConfigFilename is the name of the asdfrc file inside
+	// $XDG_CONFIG_HOME/asdf
+	xdgConfigFilename = "asdfrc"
+)
+
+// Dirs are the roots asdf stores files in. An empty cache root means the data
+// root is used.
+type Dirs struct {
+	ConfigFile string
+	// DataDir holds plugins, installs and shims
+	DataDir string
+	// CacheDir holds files asdf can recreate, like downloads and the plugin
+	// index
+	CacheDir string
+}
+
+// LegacyDirs returns the roots used by asdf before XDG base directory support.
+// Everything but the asdfrc file is stored in ~/.asdf.
+func LegacyDirs(home string) Dirs {
+	return Dirs{ConfigFile: filepath.Join(home, ".asdfrc"), DataDir: filepath.Join(home, ".asdf")}
+}
+
+// XDGDirs returns the roots according to the XDG base directory variables.
+// Roots whose variable isn't set are the same as with LegacyDirs.
+func XDGDirs(home string) Dirs {
+	dirs := LegacyDirs(home)
+
+	if configHome, ok := xdgHome("XDG_CONFIG_HOME"); ok {
+		dirs.ConfigFile = filepath.Join(configHome, xdgDirName, xdgConfigFilename)
+	}
+
+	if dataHome, ok := xdgHome("XDG_DATA_HOME"); ok {
+		dirs.DataDir = filepath.Join(dataHome, xdgDirName)
+	}
+
+	if cacheHome, ok := xdgHome("XDG_CACHE_HOME"); ok {
+		dirs.CacheDir = filepath.Join(cacheHome, xdgDirName)
+	}
+
+	return dirs
+}
+
+// defaultDirs returns the roots used when they aren't set with ASDF_
+// environment variables. The legacy ~/.asdfrc and ~/.asdf paths are used as
+// long as they exist, so existing setups keep working until they are migrated
+// with `asdf migrate-dirs`. When ASDF_DATA_DIR is set the cache is kept in the
+// data directory, as it always has been.
+func defaultDirs(home string) Dirs {
+	legacy := LegacyDirs(home)
+	dirs := XDGDirs(home)
+
+	if exists(legacy.ConfigFile) {
+		dirs.ConfigFile = legacy.ConfigFile
+	}
+
+	if _, ok := os.LookupEnv("ASDF_DATA_DIR"); ok || exists(legacy.DataDir) {
+		dirs.DataDir, dirs.CacheDir = legacy.DataDir, ""
+	}
+
+	return dirs
+}
+
+// CacheDirectory returns the root of files asdf can recreate, like downloads
+// and the plugin index. It is the data directory unless a separate cache
+// directory is set.
+func (c *Config) CacheDirectory() string {
+	if c.CacheDir != "" {
+		return c.CacheDir
+	}
+
+	return c.DataDir
+}
+
+// xdgHome returns the value of an XDG base directory variable. Relative paths
+// are invalid according to the specification and ignored.
+func xdgHome(name string) (string, bool) {
+	value := os.Getenv(name)
+	if value == "" || !filepath.IsAbs(value) {
+		return "", false
+	}
+
+	return value, true
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
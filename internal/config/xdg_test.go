@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setXDGHomes(t *testing.T, root string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "share"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+}
+
+func TestXDGDirs(t *testing.T) {
+	t.Run("returns legacy dirs when XDG variables are not set", func(t *testing.T) {
+		home := t.TempDir()
+		for _, name := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME"} {
+			t.Setenv(name, "")
+		}
+
+		assert.Equal(t, LegacyDirs(home), XDGDirs(home))
+	})
+
+	t.Run("returns dirs inside XDG base directories", func(t *testing.T) {
+		home := t.TempDir()
+		setXDGHomes(t, home)
+
+		assert.Equal(t, Dirs{
+			ConfigFile: filepath.Join(home, "config", "asdf", "asdfrc"),
+			DataDir:    filepath.Join(home, "share", "asdf"),
+			CacheDir:   filepath.Join(home, "cache", "asdf"),
+		}, XDGDirs(home))
+	})
+
+	t.Run("ignores relative XDG paths", func(t *testing.T) {
+		home := t.TempDir()
+		setXDGHomes(t, home)
+		t.Setenv("XDG_CACHE_HOME", "cache")
+
+		assert.Empty(t, XDGDirs(home).CacheDir)
+	})
+}
+
+func TestDefaultDirs(t *testing.T) {
+	t.Run("uses XDG dirs when legacy paths don't exist", func(t *testing.T) {
+		home := t.TempDir()
+		setXDGHomes(t, home)
+		t.Setenv("ASDF_DATA_DIR", "")
+		os.Unsetenv("ASDF_DATA_DIR")
+
+		assert.Equal(t, XDGDirs(home), defaultDirs(home))
+	})
+
+	t.Run("uses legacy paths when they exist", func(t *testing.T) {
+		home := t.TempDir()
+		setXDGHomes(t, home)
+		t.Setenv("ASDF_DATA_DIR", "")
+		os.Unsetenv("ASDF_DATA_DIR")
+		assert.Nil(t, os.Mkdir(filepath.Join(home, ".asdf"), 0o777))
+		assert.Nil(t, os.WriteFile(filepath.Join(home, ".asdfrc"), []byte{}, 0o666))
+
+		assert.Equal(t, LegacyDirs(home), defaultDirs(home))
+	})
+
+	t.Run("keeps cache in data dir when ASDF_DATA_DIR is set", func(t *testing.T) {
+		home := t.TempDir()
+		setXDGHomes(t, home)
+		t.Setenv("ASDF_DATA_DIR", filepath.Join(home, "data"))
+
+		dirs := defaultDirs(home)
+		assert.Empty(t, dirs.CacheDir)
+		assert.Equal(t, filepath.Join(home, "config", "asdf", "asdfrc"), dirs.ConfigFile)
+	})
+}
+
+func TestCacheDirectory(t *testing.T) {
+	conf := Config{DataDir: "/data"}
+	assert.Equal(t, "/data", conf.CacheDirectory())
+
+	conf = Config{DataDir: "/data", CacheDir: "/cache"}
+	assert.Equal(t, "/cache", conf.CacheDirectory())
+}
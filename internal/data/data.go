@@ -1,5 +1,6 @@
 // Package data provides constants and functions pertaining to directories and
-// files in the asdf data directory on disk, specified by the $ASDF_DATA_DIR
+// files in the asdf data directory on disk, specified by the $ASDF_DATA_DIR,
+// and the cache directory, specified by $ASDF_CACHE_DIR
 package data
 
 import (
@@ -7,15 +8,29 @@ import (
 )
 
 const (
-	dataDirDownloads = "downloads"
-	dataDirInstalls  = "installs"
-	dataDirPlugins   = "plugins"
+	dataDirDownloads     = "downloads"
+	dataDirInstalls      = "installs"
+	dataDirPlugins       = "plugins"
+	dataDirPluginIndex   = "plugin-index"
+	dataDirCallbackCache = "callback-cache"
 )
 
-// DownloadDirectory returns the directory a plugin will be placing
-// downloads of version source code
-func DownloadDirectory(dataDir, pluginName string) string {
-	return filepath.Join(dataDir, dataDirDownloads, pluginName)
+// DownloadDirectory returns the directory in the cache directory a plugin will
+// be placing downloads of version source code
+func DownloadDirectory(cacheDir, pluginName string) string {
+	return filepath.Join(cacheDir, dataDirDownloads, pluginName)
+}
+
+// PluginIndexDirectory returns the directory in the cache directory the plugin
+// index repository is cloned to
+func PluginIndexDirectory(cacheDir string) string {
+	return filepath.Join(cacheDir, dataDirPluginIndex)
+}
+
+// CallbackCacheDirectory returns the directory in the cache directory the
+// output of plugin callbacks is cached in
+func CallbackCacheDirectory(cacheDir string) string {
+	return filepath.Join(cacheDir, dataDirCallbackCache)
 }
 
 // InstallDirectory returns the path to a plugin directory
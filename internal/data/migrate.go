@@ -0,0 +1,79 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+
+	cp "github.com/otiai10/copy"
+)
+
+// cacheEntries are the entries of the legacy data directory that belong in the
+// cache directory
+var cacheEntries = []string{dataDirDownloads, dataDirPluginIndex, dataDirCallbackCache}
+
+// Move is a file or directory to move to a new location
+type Move struct {
+	From string
+	To   string
+}
+
+// PlanMigration returns the moves that split the legacy data directory into the
+// data and cache directories. Downloads and the plugin index go to the cache
+// directory and everything else to the data directory. It returns an error if
+// any destination already exists.
+func PlanMigration(legacyDir, dataDir, cacheDir string) ([]Move, error) {
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return []Move{}, err
+	}
+
+	moves := []Move{}
+	for _, entry := range entries {
+		root := dataDir
+		if slices.Contains(cacheEntries, entry.Name()) {
+			root = cacheDir
+		}
+
+		move := Move{From: filepath.Join(legacyDir, entry.Name()), To: filepath.Join(root, entry.Name())}
+		if move.From == move.To {
+			continue
+		}
+
+		if _, err := os.Lstat(move.To); err == nil {
+			return moves, fmt.Errorf("unable to move %s, %s already exists", move.From, move.To)
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// Migrate performs the moves. Files are renamed when possible and copied when
+// the destination is on another file system.
+func Migrate(moves []Move) error {
+	for _, move := range moves {
+		err := os.MkdirAll(filepath.Dir(move.To), 0o777)
+		if err != nil {
+			return err
+		}
+
+		err = os.Rename(move.From, move.To)
+		if errors.Is(err, syscall.EXDEV) {
+			err = cp.Copy(move.From, move.To, cp.Options{OnSymlink: func(string) cp.SymlinkAction { return cp.Shallow }})
+			if err == nil {
+				err = os.RemoveAll(move.From)
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to move %s to %s: %w", move.From, move.To, err)
+		}
+	}
+
+	return nil
+}
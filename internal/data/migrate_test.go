@@ -0,0 +1,52 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanMigration(t *testing.T) {
+	t.Run("moves downloads and plugin index to cache dir", func(t *testing.T) {
+		root := t.TempDir()
+		legacyDir := filepath.Join(root, ".asdf")
+		for _, dir := range []string{"downloads", "installs", "plugin-index", "plugins", "shims"} {
+			assert.Nil(t, os.MkdirAll(filepath.Join(legacyDir, dir), 0o777))
+		}
+
+		moves, err := PlanMigration(legacyDir, filepath.Join(root, "data"), filepath.Join(root, "cache"))
+		assert.Nil(t, err)
+		assert.Equal(t, []Move{
+			{From: filepath.Join(legacyDir, "downloads"), To: filepath.Join(root, "cache", "downloads")},
+			{From: filepath.Join(legacyDir, "installs"), To: filepath.Join(root, "data", "installs")},
+			{From: filepath.Join(legacyDir, "plugin-index"), To: filepath.Join(root, "cache", "plugin-index")},
+			{From: filepath.Join(legacyDir, "plugins"), To: filepath.Join(root, "data", "plugins")},
+			{From: filepath.Join(legacyDir, "shims"), To: filepath.Join(root, "data", "shims")},
+		}, moves)
+	})
+
+	t.Run("returns error when destination exists", func(t *testing.T) {
+		root := t.TempDir()
+		legacyDir := filepath.Join(root, ".asdf")
+		assert.Nil(t, os.MkdirAll(filepath.Join(legacyDir, "plugins"), 0o777))
+		assert.Nil(t, os.MkdirAll(filepath.Join(root, "data", "plugins"), 0o777))
+
+		_, err := PlanMigration(legacyDir, filepath.Join(root, "data"), filepath.Join(root, "cache"))
+		assert.ErrorContains(t, err, "already exists")
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	root := t.TempDir()
+	from := filepath.Join(root, ".asdf", "plugins", "lua")
+	assert.Nil(t, os.MkdirAll(from, 0o777))
+	assert.Nil(t, os.WriteFile(filepath.Join(from, "README"), []byte("lua"), 0o666))
+
+	err := Migrate([]Move{{From: filepath.Join(root, ".asdf", "plugins"), To: filepath.Join(root, "data", "plugins")}})
+	assert.Nil(t, err)
+
+	assert.NoDirExists(t, filepath.Join(root, ".asdf", "plugins"))
+	assert.FileExists(t, filepath.Join(root, "data", "plugins", "lua", "README"))
+}
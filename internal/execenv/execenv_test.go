@@ -62,6 +62,19 @@ func TestGenerate(t *testing.T) {
 		assert.Equal(t, "test", env["ASDF_INSTALL_VERSION"])
 	})
 
+	t.Run("returns map of environment variables from protocol executable", func(t *testing.T) {
+		conf := config.Config{DataDir: testDataDir}
+		_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, "python")
+		assert.Nil(t, err)
+		plugin := plugins.New(conf, "python")
+		script := "#!/usr/bin/env bash\necho '{\"protocol\":2,\"result\":{\"env\":{\"BAZ\":\"bar\"}}}'"
+		assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, plugins.ProtocolExecutable, script))
+		env, err := Generate(plugin, map[string]string{"ASDF_INSTALL_VERSION": "test"})
+		assert.Nil(t, err)
+		assert.Equal(t, "bar", env["BAZ"])
+		assert.Equal(t, "test", env["ASDF_INSTALL_VERSION"])
+	})
+
 	t.Run("returns error when plugin lacks exec-env callback", func(t *testing.T) {
 		conf := config.Config{DataDir: testDataDir}
 		_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName2)
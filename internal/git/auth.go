@@ -0,0 +1,140 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// tokenUsername is sent along with tokens read from the environment. GitHub and
+// GitLab both ignore the username when a token is given as the password.
+const tokenUsername = "x-access-token"
+
+// Options controls how a Repo talks to remote Git repositories. The zero value
+// clones full history and only uses credentials embedded in the URL or provided
+// by the SSH agent.
+type Options struct {
+	// Depth limits clones and fetches to the given number of commits. Zero
+	// means full history.
+	Depth int
+	// TokenEnv is the name of an environment variable holding a token to use
+	// as the password for HTTP(S) remotes.
+	TokenEnv string
+	// TokenHosts lists the hosts the token from TokenEnv is sent to. When
+	// empty the token is never sent.
+	TokenHosts []string
+	// NetrcFile is the path to a netrc file to read HTTP(S) credentials from.
+	// When empty $NETRC or ~/.netrc is used.
+	NetrcFile string
+	// Rewrites are applied to remote URLs before they are accessed, the same
+	// way Git's url.<base>.insteadOf setting works.
+	Rewrites []Rewrite
+}
+
+// Rewrite replaces the InsteadOf prefix of a URL with Base
+type Rewrite struct {
+	Base      string
+	InsteadOf string
+}
+
+// RewriteURL applies the rewrite with the longest matching InsteadOf prefix to
+// the URL. If no rewrite matches the URL is returned unchanged.
+func (o Options) RewriteURL(url string) string {
+	var match Rewrite
+
+	for _, rewrite := range o.Rewrites {
+		if rewrite.InsteadOf == "" || !strings.HasPrefix(url, rewrite.InsteadOf) {
+			continue
+		}
+
+		if len(rewrite.InsteadOf) > len(match.InsteadOf) {
+			match = rewrite
+		}
+	}
+
+	if match.InsteadOf == "" {
+		return url
+	}
+
+	return match.Base + strings.TrimPrefix(url, match.InsteadOf)
+}
+
+// auth returns the credentials to use when accessing the remote at url, or nil
+// if go-git's defaults should be used.
+func (o Options) auth(url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		// Let go-git report the invalid URL when it tries to use it
+		return nil, nil
+	}
+
+	switch endpoint.Protocol {
+	case "ssh":
+		if os.Getenv("SSH_AUTH_SOCK") == "" {
+			return nil, nil
+		}
+
+		user := endpoint.User
+		if user == "" {
+			user = "git"
+		}
+
+		auth, err := ssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("unable to use SSH agent: %w", err)
+		}
+
+		return auth, nil
+	case "http", "https":
+		if endpoint.User != "" || endpoint.Password != "" {
+			// credentials in the URL take precedence
+			return nil, nil
+		}
+
+		if token := o.token(endpoint.Host); token != "" {
+			return &http.BasicAuth{Username: tokenUsername, Password: token}, nil
+		}
+
+		login, password, found, err := lookupNetrc(o.netrcPath(), endpoint.Host)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			return &http.BasicAuth{Username: login, Password: password}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (o Options) token(host string) string {
+	if o.TokenEnv == "" || !slices.Contains(o.TokenHosts, host) {
+		return ""
+	}
+
+	return os.Getenv(o.TokenEnv)
+}
+
+func (o Options) netrcPath() string {
+	if o.NetrcFile != "" {
+		return o.NetrcFile
+	}
+
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".netrc")
+}
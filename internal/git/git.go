@@ -4,10 +4,12 @@ package git
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // DefaultRemoteName for Git repositories in asdf
@@ -30,6 +32,7 @@ type Repoer interface {
 type Repo struct {
 	Directory string
 	URL       string
+	Options   Options
 }
 
 // NewRepo builds a new Repo instance
@@ -37,10 +40,27 @@ func NewRepo(directory string) Repo {
 	return Repo{Directory: directory}
 }
 
-// Clone installs a plugin via Git
+// NewRepoWithOptions builds a new Repo instance that uses the given options
+// when cloning and fetching
+func NewRepoWithOptions(directory string, options Options) Repo {
+	return Repo{Directory: directory, Options: options}
+}
+
+// Clone installs a plugin via Git. If a URL rewrite applies the rewritten URL
+// is cloned, but the original URL is kept as the remote so rewrites are applied
+// again on every update.
 func (r Repo) Clone(pluginURL, ref string) error {
+	cloneURL := r.Options.RewriteURL(pluginURL)
+
+	auth, err := r.Options.auth(cloneURL)
+	if err != nil {
+		return fmt.Errorf("unable to clone plugin: %w", err)
+	}
+
 	options := git.CloneOptions{
-		URL: pluginURL,
+		URL:   cloneURL,
+		Auth:  auth,
+		Depth: r.Options.Depth,
 	}
 
 	// if ref is provided set it on CloneOptions
@@ -48,12 +68,23 @@ func (r Repo) Clone(pluginURL, ref string) error {
 		options.ReferenceName = plumbing.NewBranchReferenceName(ref)
 	}
 
-	_, err := git.PlainClone(r.Directory, false, &options)
+	repo, err := git.PlainClone(r.Directory, false, &options)
 	if err != nil {
 		return fmt.Errorf("unable to clone plugin: %w", err)
 	}
 
-	return nil
+	if cloneURL == pluginURL {
+		return nil
+	}
+
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("unable to read plugin Git config: %w", err)
+	}
+
+	repoConfig.Remotes[DefaultRemoteName].URLs = []string{pluginURL}
+
+	return repo.SetConfig(repoConfig)
 }
 
 // Head returns the current HEAD ref of the plugin's Git repository
@@ -71,6 +102,106 @@ func (r Repo) Head() (string, error) {
 	return ref.Hash().String(), nil
 }
 
+// Branch returns the short name of the branch currently checked out in the
+// plugin's Git repository, or an empty string if HEAD is detached
+func (r Repo) Branch() (string, error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !ref.Name().IsBranch() {
+		return "", nil
+	}
+
+	return ref.Name().Short(), nil
+}
+
+// RemoteHead queries the default remote for the commit at the tip of the
+// currently checked out branch, without fetching any objects
+func (r Repo) RemoteHead() (string, error) {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("not on a branch, unable to check for updates")
+	}
+
+	remote, err := repo.Remote(DefaultRemoteName)
+	if err != nil {
+		return "", err
+	}
+
+	remoteURL := r.Options.RewriteURL(remote.Config().URLs[0])
+	auth, err := r.Options.auth(remoteURL)
+	if err != nil {
+		return "", err
+	}
+
+	remote = git.NewRemote(nil, &config.RemoteConfig{Name: DefaultRemoteName, URLs: []string{remoteURL}})
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+
+	for _, ref := range refs {
+		if ref.Name() == head.Name() {
+			return ref.Hash().String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("branch %s not found on remote", head.Name().Short())
+}
+
+// RemoteRef queries the repository at url for the commit a branch or tag
+// currently points to, like `git ls-remote`, without cloning the repository.
+// Annotated tags are resolved to the commit they point to.
+func RemoteRef(url, ref string, options Options) (string, error) {
+	url = options.RewriteURL(url)
+	auth, err := options.auth(url)
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(nil, &config.RemoteConfig{Name: DefaultRemoteName, URLs: []string{url}})
+	refs, err := remote.List(&git.ListOptions{Auth: auth, PeelingOption: git.AppendPeeled})
+	if err != nil {
+		return "", err
+	}
+
+	hashes := map[plumbing.ReferenceName]string{}
+	for _, remoteRef := range refs {
+		hashes[remoteRef.Name()] = remoteRef.Hash().String()
+	}
+
+	tag := plumbing.NewTagReferenceName(ref)
+	names := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(ref),
+		plumbing.ReferenceName(tag.String() + "^{}"),
+		tag,
+		plumbing.ReferenceName(ref),
+	}
+	for _, name := range names {
+		if hash, ok := hashes[name]; ok {
+			return hash, nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %s not found on %s", ref, url)
+}
+
 // RemoteURL returns the URL of the default remote for the plugin's Git repository
 func (r Repo) RemoteURL() (string, error) {
 	repo, err := gitOpen(r.Directory)
@@ -83,9 +214,67 @@ func (r Repo) RemoteURL() (string, error) {
 		return "", err
 	}
 
+	if len(remotes) == 0 || len(remotes[0].Config().URLs) == 0 {
+		return "", fmt.Errorf("plugin Git repository has no remote")
+	}
+
 	return remotes[0].Config().URLs[0], nil
 }
 
+// Init creates a new Git repository in the directory and commits every file in
+// it. The author is read from the user's Git config, falling back to a generic
+// asdf author when no user is configured. The hash of the commit is returned.
+func (r Repo) Init(message string) (string, error) {
+	repo, err := git.PlainInit(r.Directory, false)
+	if err != nil {
+		return "", fmt.Errorf("unable to create Git repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("unable to open Git worktree: %w", err)
+	}
+
+	err = worktree.AddGlob(".")
+	if err != nil {
+		return "", fmt.Errorf("unable to add files to Git repository: %w", err)
+	}
+
+	author := object.Signature{Name: "asdf", Email: "asdf@localhost", When: time.Now()}
+	if userConfig, err := config.LoadConfig(config.GlobalScope); err == nil && userConfig.User.Name != "" && userConfig.User.Email != "" {
+		author.Name, author.Email = userConfig.User.Name, userConfig.User.Email
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: &author})
+	if err != nil {
+		return "", fmt.Errorf("unable to commit to Git repository: %w", err)
+	}
+
+	return hash.String(), nil
+}
+
+// Reset moves the current branch, or HEAD if not on a branch, to the commit
+// with the given hash and updates the worktree to match. The commit must
+// already be present in the repository.
+func (r Repo) Reset(hash string) error {
+	repo, err := gitOpen(r.Directory)
+	if err != nil {
+		return err
+	}
+
+	commit, err := repo.ResolveRevision(plumbing.Revision(hash))
+	if err != nil {
+		return fmt.Errorf("unable to find commit %s: %w", hash, err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return worktree.Reset(&git.ResetOptions{Commit: *commit, Mode: git.HardReset})
+}
+
 // Update updates the plugin's Git repository to the ref if provided, or the
 // latest commit on the current branch
 func (r Repo) Update(ref string) (string, string, string, error) {
@@ -121,10 +310,26 @@ func (r Repo) Update(ref string) (string, string, string, error) {
 		checkoutOptions = git.CheckoutOptions{Hash: plumbing.NewHash(ref), Force: true}
 	}
 
-	fetchOptions := git.FetchOptions{RemoteName: DefaultRemoteName, Force: true, RefSpecs: []config.RefSpec{
+	fetchOptions := git.FetchOptions{RemoteName: DefaultRemoteName, Force: true, Depth: r.Options.Depth, RefSpecs: []config.RefSpec{
 		config.RefSpec(ref + ":" + ref),
 	}}
 
+	remote, err := repo.Remote(DefaultRemoteName)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	remoteURL := remote.Config().URLs[0]
+	fetchURL := r.Options.RewriteURL(remoteURL)
+	if fetchURL != remoteURL {
+		fetchOptions.RemoteURL = fetchURL
+	}
+
+	fetchOptions.Auth, err = r.Options.auth(fetchURL)
+	if err != nil {
+		return "", "", "", err
+	}
+
 	err = repo.Fetch(&fetchOptions)
 
 	if err != nil && err != git.NoErrAlreadyUpToDate {
@@ -4,10 +4,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/asdf-vm/asdf/repotest"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,6 +66,37 @@ func TestRepoClone(t *testing.T) {
 	})
 }
 
+func TestRepoCloneWithOptions(t *testing.T) {
+	t.Run("clones rewritten URL but keeps original URL as remote", func(t *testing.T) {
+		repoDir := generateRepo(t)
+		directory := t.TempDir()
+		options := Options{Rewrites: []Rewrite{{Base: repoDir, InsteadOf: "https://example.com/lua"}}}
+		repo := NewRepoWithOptions(directory, options)
+
+		err := repo.Clone("https://example.com/lua", "")
+		assert.Nil(t, err)
+
+		url, err := repo.RemoteURL()
+		assert.Nil(t, err)
+		assert.Equal(t, "https://example.com/lua", url)
+
+		_, _, _, err = repo.Update("")
+		assert.Nil(t, err)
+	})
+
+	t.Run("clones only the requested number of commits when depth is set", func(t *testing.T) {
+		repoDir := generateRepo(t)
+		directory := t.TempDir()
+		repo := NewRepoWithOptions(directory, Options{Depth: 1})
+
+		err := repo.Clone("file://"+repoDir, "")
+		assert.Nil(t, err)
+
+		_, err = getCommit(directory, "HEAD~")
+		assert.NotNil(t, err)
+	})
+}
+
 func TestRepoHead(t *testing.T) {
 	repoDir := generateRepo(t)
 	directory := t.TempDir()
@@ -92,6 +125,125 @@ func TestRepoRemoteURL(t *testing.T) {
 	assert.NotZero(t, url)
 }
 
+func TestRepoInit(t *testing.T) {
+	directory := t.TempDir()
+	err := os.WriteFile(filepath.Join(directory, "README.md"), []byte("# lua\n"), 0o666)
+	assert.Nil(t, err)
+
+	repo := NewRepo(directory)
+	hash, err := repo.Init("Initial commit")
+	assert.Nil(t, err)
+
+	currentHash, err := getCurrentCommit(directory)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, currentHash)
+
+	// the new repository can be cloned like any other plugin
+	clone := NewRepo(t.TempDir())
+	err = clone.Clone(directory, "")
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(clone.Directory, "README.md"))
+
+	t.Run("returns error when repository already exists", func(t *testing.T) {
+		_, err := repo.Init("Initial commit")
+		assert.ErrorContains(t, err, "unable to create Git repository")
+	})
+}
+
+func TestRepoReset(t *testing.T) {
+	repoDir := generateRepo(t)
+	directory := t.TempDir()
+
+	repo := NewRepo(directory)
+	err := repo.Clone(repoDir, "")
+	assert.Nil(t, err)
+
+	previousHash, err := getCommit(directory, "HEAD~")
+	assert.Nil(t, err)
+
+	t.Run("moves branch to the given commit", func(t *testing.T) {
+		err := repo.Reset(previousHash)
+		assert.Nil(t, err)
+
+		currentHash, err := getCurrentCommit(directory)
+		assert.Nil(t, err)
+		assert.Equal(t, previousHash, currentHash)
+
+		// still on a branch so updates still work
+		updatedToRef, _, _, err := repo.Update("")
+		assert.Nil(t, err)
+		assert.Equal(t, "refs/heads/master", updatedToRef)
+	})
+
+	t.Run("returns error when commit does not exist", func(t *testing.T) {
+		err := repo.Reset("0000000000000000000000000000000000000000")
+		assert.ErrorContains(t, err, "unable to find commit")
+	})
+}
+
+func TestRepoBranch(t *testing.T) {
+	repoDir := generateRepo(t)
+	directory := t.TempDir()
+
+	repo := NewRepo(directory)
+	err := repo.Clone(repoDir, "")
+	assert.Nil(t, err)
+
+	branch, err := repo.Branch()
+	assert.Nil(t, err)
+	assert.Equal(t, "master", branch)
+}
+
+func TestRepoRemoteHead(t *testing.T) {
+	repoDir := generateRepo(t)
+	directory := t.TempDir()
+
+	repo := NewRepo(directory)
+	err := repo.Clone(repoDir, "")
+	assert.Nil(t, err)
+
+	latestHash, err := getCurrentCommit(directory)
+	assert.Nil(t, err)
+
+	_, err = checkoutPreviousCommit(directory)
+	assert.Nil(t, err)
+
+	remoteHead, err := repo.RemoteHead()
+	assert.Nil(t, err)
+	assert.Equal(t, latestHash, remoteHead)
+}
+
+func TestRemoteRef(t *testing.T) {
+	repoDir := generateRepo(t)
+	latestHash, err := getCurrentCommit(repoDir)
+	assert.Nil(t, err)
+
+	repo, err := git.PlainOpen(repoDir)
+	assert.Nil(t, err)
+	tagger := &object.Signature{Name: "asdf", Email: "asdf@example.com", When: time.Now()}
+	tag, err := repo.CreateTag("v1.0.0", plumbing.NewHash(latestHash), &git.CreateTagOptions{Tagger: tagger, Message: "v1.0.0"})
+	assert.Nil(t, err)
+
+	t.Run("returns commit of branch", func(t *testing.T) {
+		commit, err := RemoteRef(repoDir, "master", Options{})
+		assert.Nil(t, err)
+		assert.Equal(t, latestHash, commit)
+	})
+
+	t.Run("returns commit annotated tag points to", func(t *testing.T) {
+		assert.NotEqual(t, latestHash, tag.Hash().String())
+
+		commit, err := RemoteRef(repoDir, "v1.0.0", Options{})
+		assert.Nil(t, err)
+		assert.Equal(t, latestHash, commit)
+	})
+
+	t.Run("returns error when ref doesn't exist", func(t *testing.T) {
+		_, err := RemoteRef(repoDir, "non-existent", Options{})
+		assert.ErrorContains(t, err, "ref non-existent not found")
+	})
+}
+
 func TestRepoUpdate(t *testing.T) {
 	repoDir := generateRepo(t)
 	directory := t.TempDir()
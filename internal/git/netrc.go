@@ -0,0 +1,96 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// lookupNetrc reads the netrc file at path and returns the login and password
+// for the machine matching host, falling back to the default entry if there is
+// one. A missing netrc file is not an error.
+func lookupNetrc(path, host string) (login, password string, found bool, err error) {
+	if path == "" {
+		return "", "", false, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", "", false, nil
+	}
+
+	if err != nil {
+		return "", "", false, err
+	}
+
+	login, password, found = parseNetrc(string(content), host)
+	return login, password, found, nil
+}
+
+type netrcEntry struct {
+	login    string
+	password string
+}
+
+func parseNetrc(content, host string) (login, password string, found bool) {
+	var machine, fallback *netrcEntry
+	var current *netrcEntry
+	inMacro := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if inMacro {
+			// macro definitions end with an empty line
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+
+		tokens := strings.Fields(line)
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			if strings.HasPrefix(token, "#") {
+				break
+			}
+
+			switch token {
+			case "machine":
+				current = &netrcEntry{}
+				if i+1 < len(tokens) {
+					i++
+					if tokens[i] == host && machine == nil {
+						machine = current
+					}
+				}
+			case "default":
+				current = &netrcEntry{}
+				if fallback == nil {
+					fallback = current
+				}
+			case "login", "password":
+				if current == nil || i+1 >= len(tokens) {
+					continue
+				}
+				i++
+				if token == "login" {
+					current.login = tokens[i]
+				} else {
+					current.password = tokens[i]
+				}
+			case "macdef":
+				inMacro = true
+				i = len(tokens)
+			}
+		}
+	}
+
+	if machine != nil {
+		return machine.login, machine.password, true
+	}
+
+	if fallback != nil {
+		return fallback.login, fallback.password, true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetrc(t *testing.T) {
+	content := `# comment line
+machine github.com
+  login octocat
+  password token123
+
+macdef init
+  machine evil.example.com login bad password bad
+
+machine gitlab.com login gl password glpass
+default login anon password anonpass
+`
+
+	t.Run("returns entry for matching machine", func(t *testing.T) {
+		login, password, found := parseNetrc(content, "github.com")
+		assert.True(t, found)
+		assert.Equal(t, "octocat", login)
+		assert.Equal(t, "token123", password)
+	})
+
+	t.Run("parses entries on a single line", func(t *testing.T) {
+		login, password, found := parseNetrc(content, "gitlab.com")
+		assert.True(t, found)
+		assert.Equal(t, "gl", login)
+		assert.Equal(t, "glpass", password)
+	})
+
+	t.Run("ignores macro definitions", func(t *testing.T) {
+		login, _, found := parseNetrc(content, "evil.example.com")
+		assert.True(t, found)
+		assert.Equal(t, "anon", login)
+	})
+
+	t.Run("returns not found without a match or default", func(t *testing.T) {
+		_, _, found := parseNetrc("machine github.com login a password b", "gitlab.com")
+		assert.False(t, found)
+	})
+}
+
+func TestLookupNetrc(t *testing.T) {
+	t.Run("returns not found when file is missing", func(t *testing.T) {
+		_, _, found, err := lookupNetrc(filepath.Join(t.TempDir(), "missing"), "github.com")
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+}
@@ -107,10 +107,16 @@ func writePluginHelp(conf config.Config, toolName, toolVersion string, writer io
 		return err
 	}
 
-	err := plugin.RunCallback("help.overview", []string{}, env, writer, errWriter)
+	return writeBackendHelp(plugin, plugin.Name, env, writer, errWriter)
+}
+
+// writeBackendHelp writes the overview of the tool followed by whichever of
+// the dependencies, configuration and links topics the backend documents
+func writeBackendHelp(backend plugins.Backend, toolName string, env map[string]string, writer io.Writer, errWriter io.Writer) error {
+	err := backend.Help("overview", env, writer, errWriter)
 	if _, ok := err.(plugins.NoCallbackError); ok {
 		// No such callback, print err msg
-		errWriter.Write([]byte(fmt.Sprintf("No documentation for plugin %s\n", plugin.Name)))
+		errWriter.Write([]byte(fmt.Sprintf("No documentation for plugin %s\n", toolName)))
 		return err
 	}
 
@@ -118,19 +124,11 @@ func writePluginHelp(conf config.Config, toolName, toolVersion string, writer io
 		return err
 	}
 
-	err = plugin.RunCallback("help.deps", []string{}, env, writer, errWriter)
-	if _, ok := err.(plugins.NoCallbackError); !ok {
-		return err
-	}
-
-	err = plugin.RunCallback("help.config", []string{}, env, writer, errWriter)
-	if _, ok := err.(plugins.NoCallbackError); !ok {
-		return err
-	}
-
-	err = plugin.RunCallback("help.links", []string{}, env, writer, errWriter)
-	if _, ok := err.(plugins.NoCallbackError); !ok {
-		return err
+	for _, topic := range []string{"deps", "config", "links"} {
+		err = backend.Help(topic, env, writer, errWriter)
+		if _, ok := err.(plugins.NoCallbackError); err != nil && !ok {
+			return err
+		}
 	}
 
 	return nil
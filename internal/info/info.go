@@ -48,7 +48,9 @@ func Write(conf config.Config, version string, writer io.Writer) error {
 
 	fmt.Fprintln(writer, "\nASDF INTERNAL VARIABLES:")
 	fmt.Fprintf(writer, "ASDF_DEFAULT_TOOL_VERSIONS_FILENAME=%s\n", conf.DefaultToolVersionsFilename)
+	fmt.Fprintf(writer, "ASDF_TOOL_VERSIONS_FILE=%s\n", conf.ToolVersionsFile)
 	fmt.Fprintf(writer, "ASDF_DATA_DIR=%s\n", conf.DataDir)
+	fmt.Fprintf(writer, "ASDF_CACHE_DIR=%s\n", conf.CacheDirectory())
 	fmt.Fprintf(writer, "ASDF_CONFIG_FILE=%s\n", conf.ConfigFile)
 
 	fmt.Fprintln(writer, "\nASDF INSTALLED PLUGINS:")
@@ -0,0 +1,317 @@
+package info
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/wasm"
+)
+
+// PluginInfo describes a single installed plugin and its state
+type PluginInfo struct {
+	Name              string             `json:"name"`
+	Dir               string             `json:"dir"`
+	URL               string             `json:"url"`
+	Link              string             `json:"link,omitempty"`
+	Ref               string             `json:"ref"`
+	Branch            string             `json:"branch"`
+	ProtocolVersion   int                `json:"protocol_version"`
+	Update            UpdateInfo         `json:"update"`
+	Callbacks         []CallbackInfo     `json:"callbacks"`
+	ExtensionCommands []string           `json:"extension_commands"`
+	LegacyFilenames   []string           `json:"legacy_filenames"`
+	InstalledVersions []InstalledVersion `json:"installed_versions"`
+}
+
+// UpdateInfo records whether the plugin's upstream branch has moved past the
+// commit currently checked out
+type UpdateInfo struct {
+	Checked   bool   `json:"checked"`
+	Available bool   `json:"available"`
+	RemoteRef string `json:"remote_ref,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CallbackInfo records the state of one of the callbacks asdf knows about.
+// Manifest is true when the callback has no script and is implemented by asdf
+// using the plugin's manifest instead. Protocol is true when the callback is
+// implemented by the plugin's JSON protocol executable. Sandboxed is true when
+// the callback is a WebAssembly module run in a sandbox.
+type CallbackInfo struct {
+	Name       string `json:"name"`
+	Required   bool   `json:"required"`
+	Present    bool   `json:"present"`
+	Executable bool   `json:"executable"`
+	Manifest   bool   `json:"manifest"`
+	Protocol   bool   `json:"protocol"`
+	Sandboxed  bool   `json:"sandboxed"`
+}
+
+// InstalledVersion is a tool version installed with the plugin along with
+// the size of its install directory in bytes
+type InstalledVersion struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+}
+
+// Plugin gathers information about an installed plugin. When checkUpdate is
+// true the plugin's remote is queried to find out if an update is available.
+func Plugin(conf config.Config, plugin plugins.Plugin, checkUpdate bool) (PluginInfo, error) {
+	if err := plugin.Exists(); err != nil {
+		return PluginInfo{}, err
+	}
+
+	info := PluginInfo{
+		Name:              plugin.Name,
+		Dir:               plugin.Dir,
+		ExtensionCommands: []string{},
+		LegacyFilenames:   []string{},
+		InstalledVersions: []InstalledVersion{},
+	}
+
+	repo := git.NewRepoWithOptions(plugin.Dir, plugins.GitOptions(conf))
+	// A plugin isn't required to be a Git repository, so errors here only mean
+	// there is no Git information to show.
+	info.URL, _ = repo.RemoteURL()
+	info.Ref, _ = repo.Head()
+	info.Branch, _ = repo.Branch()
+	info.Link, _ = plugin.Linked()
+	info.ProtocolVersion = plugin.ProtocolVersion()
+
+	if checkUpdate && info.Ref != "" {
+		info.Update.Checked = true
+		remoteRef, err := repo.RemoteHead()
+		if err != nil {
+			info.Update.Error = err.Error()
+		} else {
+			info.Update.RemoteRef = remoteRef
+			info.Update.Available = remoteRef != info.Ref
+		}
+	}
+
+	for _, callback := range plugins.AllCallbacks {
+		info.Callbacks = append(info.Callbacks, callbackInfo(plugin, callback))
+	}
+
+	commands, err := plugin.GetExtensionCommands()
+	if err != nil {
+		return info, err
+	}
+	info.ExtensionCommands = append(info.ExtensionCommands, commands...)
+
+	legacyFilenames, err := plugin.LegacyFilenames()
+	if err != nil {
+		return info, err
+	}
+	for _, filename := range legacyFilenames {
+		if filename != "" {
+			info.LegacyFilenames = append(info.LegacyFilenames, filename)
+		}
+	}
+
+	versions, err := installs.Installed(conf, plugin)
+	if err != nil {
+		return info, err
+	}
+
+	for _, version := range versions {
+		installPath := installs.InstallPath(conf, plugin, toolversions.Parse(version))
+		size, err := directorySize(installPath)
+		if err != nil {
+			return info, err
+		}
+
+		info.InstalledVersions = append(info.InstalledVersions, InstalledVersion{Version: version, Path: installPath, Size: size})
+	}
+
+	return info, nil
+}
+
+// WritePlugin writes a human readable description of the plugin
+func WritePlugin(info PluginInfo, writer io.Writer) error {
+	w := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", info.Name)
+	fmt.Fprintf(w, "Directory:\t%s\n", info.Dir)
+	fmt.Fprintf(w, "URL:\t%s\n", valueOrNone(info.URL))
+	if info.Link != "" {
+		fmt.Fprintf(w, "Linked to:\t%s\n", info.Link)
+	}
+	fmt.Fprintf(w, "Ref:\t%s\n", valueOrNone(info.Ref))
+	fmt.Fprintf(w, "Branch:\t%s\n", valueOrNone(info.Branch))
+	fmt.Fprintf(w, "Protocol version:\t%d\n", info.ProtocolVersion)
+	fmt.Fprintf(w, "Update:\t%s\n", formatUpdate(info.Update))
+	fmt.Fprintf(w, "Extension commands:\t%s\n", formatExtensionCommands(info.Name, info.ExtensionCommands))
+	fmt.Fprintf(w, "Legacy filenames:\t%s\n", valueOrNone(strings.Join(info.LegacyFilenames, " ")))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(writer, "\nCallbacks:")
+	w = tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	for _, callback := range info.Callbacks {
+		fmt.Fprintf(w, "  %s\t%s\n", callback.Name, formatCallbackState(callback))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(writer, "\nInstalled versions:")
+	if len(info.InstalledVersions) == 0 {
+		fmt.Fprintln(writer, "  No versions installed")
+		return nil
+	}
+
+	w = tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	for _, version := range info.InstalledVersions {
+		fmt.Fprintf(w, "  %s\t%s\n", version.Version, formatSize(version.Size))
+	}
+
+	return w.Flush()
+}
+
+// WritePluginJSON writes the plugin description as JSON
+func WritePluginJSON(info PluginInfo, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(info)
+}
+
+func callbackInfo(plugin plugins.Plugin, name string) CallbackInfo {
+	callback := CallbackInfo{Name: name, Required: slices.Contains(plugins.RequiredCallbacks, name)}
+
+	if plugin.ProtocolCallback(name) {
+		callback.Protocol = true
+		return callback
+	}
+
+	path, err := plugin.CallbackPath(name)
+	if err != nil {
+		callback.Manifest = plugin.ManifestCallback(name)
+		return callback
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return callback
+	}
+
+	callback.Present = true
+	callback.Sandboxed = filepath.Ext(path) == wasm.Extension
+	callback.Executable = fileInfo.Mode()&0o111 != 0
+	return callback
+}
+
+func directorySize(dir string) (size int64, err error) {
+	err = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += fileInfo.Size()
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+
+	return size, err
+}
+
+func formatUpdate(update UpdateInfo) string {
+	switch {
+	case !update.Checked:
+		return "not checked"
+	case update.Error != "":
+		return fmt.Sprintf("unknown (%s)", update.Error)
+	case update.Available:
+		return fmt.Sprintf("available (%s)", update.RemoteRef)
+	default:
+		return "up to date"
+	}
+}
+
+func formatCallbackState(callback CallbackInfo) string {
+	state := "ok"
+	if callback.Protocol {
+		state = "protocol v2"
+	} else if callback.Manifest {
+		state = "manifest"
+	} else if !callback.Present {
+		state = "missing"
+	} else if callback.Sandboxed {
+		state = "sandboxed (wasm)"
+	} else if !callback.Executable {
+		state = "not executable"
+	}
+
+	if callback.Required {
+		return state + " (required)"
+	}
+
+	return state
+}
+
+func formatExtensionCommands(pluginName string, commands []string) string {
+	if len(commands) == 0 {
+		return "none"
+	}
+
+	var formatted []string
+	for _, command := range commands {
+		if command == "" {
+			formatted = append(formatted, fmt.Sprintf("asdf %s", pluginName))
+		} else {
+			formatted = append(formatted, fmt.Sprintf("asdf %s %s", pluginName, command))
+		}
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+
+	return value
+}
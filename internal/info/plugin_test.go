@@ -0,0 +1,83 @@
+package info
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestPlugin(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	err = plugins.Add(conf, testPluginName, repoPath, "")
+	assert.Nil(t, err)
+	plugin := plugins.New(conf, testPluginName)
+
+	installDir := filepath.Join(testDataDir, "installs", testPluginName, "1.0.0")
+	err = os.MkdirAll(installDir, 0o777)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(installDir, "file"), []byte("12345"), 0o666)
+	assert.Nil(t, err)
+
+	t.Run("returns plugin details", func(t *testing.T) {
+		info, err := Plugin(conf, plugin, true)
+		assert.Nil(t, err)
+
+		assert.Equal(t, testPluginName, info.Name)
+		assert.Equal(t, plugin.Dir, info.Dir)
+		assert.Equal(t, repoPath, info.URL)
+		assert.NotZero(t, info.Ref)
+		assert.Equal(t, "master", info.Branch)
+		assert.True(t, info.Update.Checked)
+		assert.False(t, info.Update.Available)
+		assert.Equal(t, []string{".dummy-version", ".dummyrc"}, info.LegacyFilenames)
+		assert.Equal(t, []InstalledVersion{{Version: "1.0.0", Path: installDir, Size: 5}}, info.InstalledVersions)
+		assert.Len(t, info.Callbacks, len(plugins.AllCallbacks))
+		assert.Equal(t, CallbackInfo{Name: "download", Required: true, Present: true, Executable: true}, info.Callbacks[0])
+		assert.Contains(t, info.Callbacks, CallbackInfo{Name: "exec-env"})
+	})
+
+	t.Run("skips update check when not requested", func(t *testing.T) {
+		info, err := Plugin(conf, plugin, false)
+		assert.Nil(t, err)
+		assert.False(t, info.Update.Checked)
+	})
+
+	t.Run("returns error when plugin does not exist", func(t *testing.T) {
+		_, err := Plugin(conf, plugins.New(conf, "non-existent"), false)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("writes text and JSON output", func(t *testing.T) {
+		info, err := Plugin(conf, plugin, false)
+		assert.Nil(t, err)
+
+		var stdout strings.Builder
+		err = WritePlugin(info, &stdout)
+		assert.Nil(t, err)
+		assert.Contains(t, stdout.String(), "Branch:")
+		assert.Contains(t, stdout.String(), "download               ok (required)")
+		assert.Contains(t, stdout.String(), "1.0.0  5 B")
+
+		var jsonOut strings.Builder
+		err = WritePluginJSON(info, &jsonOut)
+		assert.Nil(t, err)
+
+		var decoded PluginInfo
+		err = json.Unmarshal([]byte(jsonOut.String()), &decoded)
+		assert.Nil(t, err)
+		assert.Equal(t, info, decoded)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/asdf-vm/asdf/internal/config"
 	"github.com/asdf-vm/asdf/internal/data"
@@ -27,7 +28,9 @@ func Installed(conf config.Config, plugin plugins.Plugin) (versions []string, er
 	}
 
 	for _, file := range files {
-		if !file.IsDir() {
+		// Hidden directories hold previous builds kept while a version is
+		// reinstalled
+		if !file.IsDir() || strings.HasPrefix(file.Name(), ".") {
 			continue
 		}
 
@@ -52,7 +55,7 @@ func DownloadPath(conf config.Config, plugin plugins.Plugin, version toolversion
 		return ""
 	}
 
-	return filepath.Join(data.DownloadDirectory(conf.DataDir, plugin.Name), toolversions.FormatForFS(version))
+	return filepath.Join(data.DownloadDirectory(conf.CacheDirectory(), plugin.Name), toolversions.FormatForFS(version))
 }
 
 // IsInstalled checks if a specific version of a tool is installed
@@ -63,6 +63,45 @@ func TestInstalled(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, installedVersions, []string{"1.0.0"})
 	})
+
+	t.Run("ignores hidden directories", func(t *testing.T) {
+		hidden := filepath.Join(conf.DataDir, "installs", "lua", ".ref-main.previous")
+		assert.Nil(t, os.MkdirAll(hidden, 0o777))
+
+		installedVersions, err := Installed(conf, plugin)
+		assert.Nil(t, err)
+		assert.Equal(t, installedVersions, []string{"1.0.0"})
+	})
+}
+
+func TestMetadata(t *testing.T) {
+	conf, plugin := generateConfig(t)
+	version := toolversions.Version{Type: "ref", Value: "main"}
+	assert.Nil(t, os.MkdirAll(filepath.Join(conf.DataDir, "installs", "lua"), 0o777))
+
+	t.Run("returns empty metadata when none recorded", func(t *testing.T) {
+		metadata, err := ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Equal(t, Metadata{}, metadata)
+	})
+
+	t.Run("returns recorded metadata", func(t *testing.T) {
+		assert.Nil(t, WriteMetadata(conf, plugin, version, Metadata{Commit: "abc123"}))
+		assert.FileExists(t, filepath.Join(conf.DataDir, "installs", "lua", ".ref-main.json"))
+
+		metadata, err := ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Equal(t, Metadata{Commit: "abc123"}, metadata)
+	})
+
+	t.Run("removes recorded metadata", func(t *testing.T) {
+		assert.Nil(t, RemoveMetadata(conf, plugin, version))
+		assert.Nil(t, RemoveMetadata(conf, plugin, version))
+
+		metadata, err := ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Equal(t, Metadata{}, metadata)
+	})
 }
 
 func TestIsInstalled(t *testing.T) {
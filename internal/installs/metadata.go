@@ -0,0 +1,67 @@
+package installs
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+)
+
+// Metadata is what asdf records about an installed version, in addition to
+// what the plugin put in the install directory
+type Metadata struct {
+	// Commit is the commit the upstream ref of a ref version pointed to when
+	// the version was installed
+	Commit string `json:"commit,omitempty"`
+}
+
+// MetadataPath returns the path to the metadata of a tool installation. It is a
+// hidden file next to the install directory, so it is never mistaken for part
+// of the installation or for another version.
+func MetadataPath(conf config.Config, plugin plugins.Plugin, version toolversions.Version) string {
+	return filepath.Join(data.InstallDirectory(conf.DataDir, plugin.Name), "."+toolversions.FormatForFS(version)+".json")
+}
+
+// ReadMetadata returns the metadata of a tool installation. Empty metadata is
+// returned if none was recorded.
+func ReadMetadata(conf config.Config, plugin plugins.Plugin, version toolversions.Version) (Metadata, error) {
+	metadata := Metadata{}
+
+	contents, err := os.ReadFile(MetadataPath(conf, plugin, version))
+	if errors.Is(err, fs.ErrNotExist) {
+		return metadata, nil
+	}
+
+	if err != nil {
+		return metadata, err
+	}
+
+	err = json.Unmarshal(contents, &metadata)
+	return metadata, err
+}
+
+// WriteMetadata records the metadata of a tool installation
+func WriteMetadata(conf config.Config, plugin plugins.Plugin, version toolversions.Version, metadata Metadata) error {
+	contents, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(MetadataPath(conf, plugin, version), contents, 0o666)
+}
+
+// RemoveMetadata removes the metadata of a tool installation, if any
+func RemoveMetadata(conf config.Config, plugin plugins.Plugin, version toolversions.Version) error {
+	err := os.Remove(MetadataPath(conf, plugin, version))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -45,7 +45,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionType, v
 		return fmt.Errorf("unable to create download dir: %w", err)
 	}
 
-	err = plugin.RunCallback("download", []string{}, env, &stdOut, &stdErr)
+	err = plugin.Download(env, &stdOut, &stdErr)
 	if _, ok := err.(plugins.NoCallbackError); err != nil && !ok {
 		return fmt.Errorf("failed to run download callback: %w", err)
 	}
@@ -55,7 +55,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionType, v
 		return fmt.Errorf("unable to create install dir: %w", err)
 	}
 
-	err = plugin.RunCallback("install", []string{}, env, &stdOut, &stdErr)
+	err = plugin.Install(env, &stdOut, &stdErr)
 	if err != nil {
 		return fmt.Errorf("failed to run install callback: %w", err)
 	}
@@ -70,7 +70,7 @@ func InstallPath(conf config.Config, plugin plugins.Plugin, version string) stri
 
 // DownloadPath returns the download path for a particular plugin and version
 func DownloadPath(conf config.Config, plugin plugins.Plugin, version string) string {
-	return filepath.Join(conf.DataDir, dataDirDownloads, plugin.Name, version)
+	return filepath.Join(conf.CacheDirectory(), dataDirDownloads, plugin.Name, version)
 }
 
 func pluginInstallPath(conf config.Config, plugin plugins.Plugin) string {
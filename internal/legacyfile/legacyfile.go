@@ -0,0 +1,200 @@
+// Package legacyfile parses the version files of other version managers and
+// tools, like `.nvmrc` or `go.mod`, without invoking plugin callbacks. Which
+// plugin a file belongs to is up to the caller.
+package legacyfile
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// parser returns the versions in the contents of a legacy version file
+type parser func(contents string) []string
+
+var parsers = map[string]parser{
+	".nvmrc":              parseNvmrc,
+	".node-version":       parseNodeVersion,
+	".python-version":     parseLines,
+	".ruby-version":       parseRubyVersion,
+	".java-version":       parseFirstLine,
+	".terraform-version":  parseFirstLine,
+	"go.mod":              parseGoMod,
+	"rust-toolchain.toml": parseRustToolchain,
+}
+
+// Filenames returns the names of the files that can be parsed, sorted
+func Filenames() []string {
+	filenames := []string{}
+	for filename := range parsers {
+		filenames = append(filenames, filename)
+	}
+
+	slices.Sort(filenames)
+	return filenames
+}
+
+// Supported returns true if files with the given name can be parsed
+func Supported(filename string) bool {
+	_, ok := parsers[filename]
+	return ok
+}
+
+// Parse returns the versions in the legacy version file at path. The file name
+// determines how the file is parsed. An empty slice is returned when the file
+// doesn't specify a version.
+func Parse(path string) ([]string, error) {
+	parse, ok := parsers[filepath.Base(path)]
+	if !ok {
+		return []string{}, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return parse(string(contents)), nil
+}
+
+// parseNvmrc parses .nvmrc files. LTS aliases like `lts/*` and `lts/iron` are
+// turned into `lts` and `lts-iron`, as versions can't contain slashes, and
+// left for the plugin to resolve.
+func parseNvmrc(contents string) []string {
+	versions := parseNodeVersion(contents)
+	for i, version := range versions {
+		if alias, ok := strings.CutPrefix(version, "lts/"); ok {
+			versions[i] = "lts"
+			if alias != "*" {
+				versions[i] += "-" + strings.ToLower(alias)
+			}
+		}
+	}
+
+	return versions
+}
+
+func parseNodeVersion(contents string) []string {
+	versions := parseFirstLine(contents)
+	for i, version := range versions {
+		versions[i] = trimVersionPrefix(version, "v")
+	}
+
+	return versions
+}
+
+// parseRubyVersion parses .ruby-version files, which may prefix the version
+// with the name of the implementation, like `ruby-3.3.0`
+func parseRubyVersion(contents string) []string {
+	versions := parseFirstLine(contents)
+	for i, version := range versions {
+		versions[i] = trimVersionPrefix(version, "ruby-")
+	}
+
+	return versions
+}
+
+// parseGoMod returns the version of the toolchain directive, or of the go
+// directive if there is no toolchain directive
+func parseGoMod(contents string) []string {
+	goVersion, toolchain := "", ""
+	for _, line := range lines(contents) {
+		// go.mod comments start with //, not #
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "go":
+			goVersion = fields[1]
+		case "toolchain":
+			if fields[1] != "default" {
+				toolchain = trimVersionPrefix(fields[1], "go")
+			}
+		}
+	}
+
+	if toolchain != "" {
+		return []string{toolchain}
+	}
+
+	if goVersion != "" {
+		return []string{goVersion}
+	}
+
+	return []string{}
+}
+
+// parseRustToolchain returns the channel of the toolchain table of a
+// rust-toolchain.toml file
+func parseRustToolchain(contents string) []string {
+	table := ""
+	for _, line := range lines(contents) {
+		if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || table != "toolchain" || strings.TrimSpace(key) != "channel" {
+			continue
+		}
+
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value != "" {
+			return []string{value}
+		}
+	}
+
+	return []string{}
+}
+
+// parseLines returns a version for each line, like pyenv does for
+// .python-version files
+func parseLines(contents string) []string {
+	versions := []string{}
+	for _, line := range lines(contents) {
+		versions = append(versions, strings.Fields(line)...)
+	}
+
+	return versions
+}
+
+func parseFirstLine(contents string) []string {
+	lines := lines(contents)
+	if len(lines) == 0 {
+		return []string{}
+	}
+
+	return strings.Fields(lines[0])[:1]
+}
+
+// lines returns the lines of the contents without comments, surrounding
+// whitespace and empty lines
+func lines(contents string) []string {
+	lines := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// trimVersionPrefix removes the prefix if it is followed by a digit
+func trimVersionPrefix(version, prefix string) string {
+	trimmed, ok := strings.CutPrefix(version, prefix)
+	if ok && trimmed != "" && trimmed[0] >= '0' && trimmed[0] <= '9' {
+		return trimmed
+	}
+
+	return version
+}
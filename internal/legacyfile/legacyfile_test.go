@@ -0,0 +1,59 @@
+package legacyfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		filename string
+		contents string
+		versions []string
+	}{
+		{filename: ".nvmrc", contents: "v20.11.1\n", versions: []string{"20.11.1"}},
+		{filename: ".nvmrc", contents: "lts/*\n", versions: []string{"lts"}},
+		{filename: ".nvmrc", contents: "lts/Iron\n", versions: []string{"lts-iron"}},
+		{filename: ".nvmrc", contents: "# comment\n20\n", versions: []string{"20"}},
+		{filename: ".node-version", contents: "20.11.1", versions: []string{"20.11.1"}},
+		{filename: ".python-version", contents: "3.12.1\n3.11.7\n", versions: []string{"3.12.1", "3.11.7"}},
+		{filename: ".ruby-version", contents: "ruby-3.3.0\n", versions: []string{"3.3.0"}},
+		{filename: ".ruby-version", contents: "jruby-9.4.5.0\n", versions: []string{"jruby-9.4.5.0"}},
+		{filename: ".java-version", contents: "17\n", versions: []string{"17"}},
+		{filename: ".terraform-version", contents: "1.6.6\n", versions: []string{"1.6.6"}},
+		{filename: "go.mod", contents: "module example.com/m\n\ngo 1.21\n", versions: []string{"1.21"}},
+		{filename: "go.mod", contents: "module example.com/m\n\ngo 1.21\n\ntoolchain go1.22.1\n", versions: []string{"1.22.1"}},
+		{filename: "go.mod", contents: "module example.com/m\n", versions: []string{}},
+		{filename: "go.mod", contents: "// example module\nmodule example.com/m\n\ngo 1.22 // minimum version\n\ntoolchain go1.23.4 // used by CI\n", versions: []string{"1.23.4"}},
+		{filename: "go.mod", contents: "module example.com/m\n\ngo 1.22 // minimum version\n", versions: []string{"1.22"}},
+		{filename: "rust-toolchain.toml", contents: "[toolchain]\nchannel = \"1.75.0\"\ncomponents = [\"rustfmt\"]\n", versions: []string{"1.75.0"}},
+		{filename: "rust-toolchain.toml", contents: "[other]\nchannel = \"nightly\"\n", versions: []string{}},
+		{filename: ".node-version", contents: "\n", versions: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename+" "+tt.contents, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			err := os.WriteFile(path, []byte(tt.contents), 0o666)
+			assert.Nil(t, err)
+
+			versions, err := Parse(path)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+
+	t.Run("returns error when file can't be read", func(t *testing.T) {
+		_, err := Parse(filepath.Join(t.TempDir(), ".nvmrc"))
+		assert.Error(t, err)
+	})
+}
+
+func TestFilenames(t *testing.T) {
+	assert.Equal(t, []string{".java-version", ".node-version", ".nvmrc", ".python-version", ".ruby-version", ".terraform-version", "go.mod", "rust-toolchain.toml"}, Filenames())
+	assert.True(t, Supported("go.mod"))
+	assert.False(t, Supported(".tool-versions"))
+}
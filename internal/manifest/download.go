@@ -0,0 +1,191 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Download fetches the release artifact for the version into downloadPath and
+// verifies its checksum if the manifest has a checksum URL. The path of the
+// downloaded artifact is returned.
+func (m Manifest) Download(version, downloadPath string) (string, error) {
+	artifactURL, err := m.ArtifactURL(version)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(downloadPath, 0o777)
+	if err != nil {
+		return "", fmt.Errorf("unable to create download dir: %w", err)
+	}
+
+	artifactPath := filepath.Join(downloadPath, artifactName(artifactURL))
+	err = fetchToFile(artifactURL, artifactPath)
+	if err != nil {
+		return "", err
+	}
+
+	checksumURL, err := m.ArtifactChecksumURL(version)
+	if err != nil || checksumURL == "" {
+		return artifactPath, err
+	}
+
+	var checksums strings.Builder
+	err = fetch(checksumURL, &checksums)
+	if err != nil {
+		return "", err
+	}
+
+	err = verifyChecksum(artifactPath, artifactName(artifactURL), checksums.String())
+	if err != nil {
+		os.Remove(artifactPath)
+		return "", err
+	}
+
+	return artifactPath, nil
+}
+
+// DownloadedArtifact returns the path the release artifact for the version is
+// downloaded to
+func (m Manifest) DownloadedArtifact(version, downloadPath string) (string, error) {
+	artifactURL, err := m.ArtifactURL(version)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(downloadPath, artifactName(artifactURL)), nil
+}
+
+func artifactName(artifactURL string) string {
+	if parsed, err := url.Parse(artifactURL); err == nil && parsed.Path != "" {
+		return path.Base(parsed.Path)
+	}
+
+	return path.Base(artifactURL)
+}
+
+func fetchToFile(sourceURL, destination string) error {
+	file, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+
+	err = fetch(sourceURL, file)
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(destination)
+		return err
+	}
+
+	return closeErr
+}
+
+// downloadTimeout limits how long a single download may take, so a stalled
+// server can't hang an install forever
+const downloadTimeout = 10 * time.Minute
+
+var httpClient = http.Client{Timeout: downloadTimeout}
+
+// fetch writes the contents of an HTTP(S) or file URL to writer
+func fetch(sourceURL string, writer io.Writer) error {
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %s: %w", sourceURL, err)
+	}
+
+	if parsed.Scheme == "file" {
+		file, err := os.Open(parsed.Path)
+		if err != nil {
+			return fmt.Errorf("unable to download %s: %w", sourceURL, err)
+		}
+		defer file.Close()
+
+		_, err = io.Copy(writer, file)
+		return err
+	}
+
+	response, err := httpClient.Get(sourceURL)
+	if err != nil {
+		return fmt.Errorf("unable to download %s: %w", sourceURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download %s: %s", sourceURL, response.Status)
+	}
+
+	_, err = io.Copy(writer, response.Body)
+	if err != nil {
+		return fmt.Errorf("unable to download %s: %w", sourceURL, err)
+	}
+
+	return nil
+}
+
+// verifyChecksum checks the file against the checksum for name in checksums.
+// checksums is either a single hex encoded checksum or lines in the format
+// written by sha256sum, each containing a checksum and a filename. The hash
+// function is chosen based on the length of the checksum.
+func verifyChecksum(filePath, name, checksums string) error {
+	expected, err := findChecksum(name, checksums)
+	if err != nil {
+		return err
+	}
+
+	var hasher hash.Hash
+	switch len(expected) {
+	case sha256.Size * 2:
+		hasher = sha256.New()
+	case sha512.Size * 2:
+		hasher = sha512.New()
+	default:
+		return fmt.Errorf("unsupported checksum for %s: %s", name, expected)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(hasher, file)
+	if err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(hasher.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+	}
+
+	return nil
+}
+
+func findChecksum(name, checksums string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(checksums), "\n")
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 1 && len(lines) == 1 {
+			return strings.ToLower(fields[0]), nil
+		}
+
+		// sha256sum marks binary mode files with a leading asterisk
+		if len(fields) >= 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("no checksum found for %s", name)
+}
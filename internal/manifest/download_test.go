@@ -0,0 +1,99 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownload(t *testing.T) {
+	artifact := []byte("tool contents")
+	sum := sha256.Sum256(artifact)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1.0.0/tool":
+			w.Write(artifact)
+		case "/1.0.0/SHA256SUMS":
+			fmt.Fprintf(w, "0000000000000000000000000000000000000000000000000000000000000000  other\n%s *tool\n", checksum)
+		case "/1.0.0/tool.sha256":
+			fmt.Fprintln(w, checksum)
+		case "/1.0.0/bad.sha256":
+			fmt.Fprintln(w, "0000000000000000000000000000000000000000000000000000000000000000")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("downloads artifact to download path", func(t *testing.T) {
+		downloadPath := t.TempDir()
+		manifest := Manifest{URL: server.URL + "/{{.Version}}/tool"}
+
+		path, err := manifest.Download("1.0.0", downloadPath)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(downloadPath, "tool"), path)
+
+		contents, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, artifact, contents)
+	})
+
+	t.Run("verifies checksum from checksums file", func(t *testing.T) {
+		manifest := Manifest{URL: server.URL + "/{{.Version}}/tool", ChecksumURL: server.URL + "/{{.Version}}/SHA256SUMS"}
+		_, err := manifest.Download("1.0.0", t.TempDir())
+		assert.Nil(t, err)
+	})
+
+	t.Run("verifies checksum from single checksum file", func(t *testing.T) {
+		manifest := Manifest{URL: server.URL + "/{{.Version}}/tool", ChecksumURL: server.URL + "/{{.Version}}/tool.sha256"}
+		_, err := manifest.Download("1.0.0", t.TempDir())
+		assert.Nil(t, err)
+	})
+
+	t.Run("returns error and removes artifact when checksum does not match", func(t *testing.T) {
+		downloadPath := t.TempDir()
+		manifest := Manifest{URL: server.URL + "/{{.Version}}/tool", ChecksumURL: server.URL + "/{{.Version}}/bad.sha256"}
+		_, err := manifest.Download("1.0.0", downloadPath)
+		assert.ErrorContains(t, err, "checksum mismatch for tool")
+		assert.NoFileExists(t, filepath.Join(downloadPath, "tool"))
+	})
+
+	t.Run("returns error when artifact does not exist", func(t *testing.T) {
+		manifest := Manifest{URL: server.URL + "/{{.Version}}/tool"}
+		_, err := manifest.Download("2.0.0", t.TempDir())
+		assert.ErrorContains(t, err, "404 Not Found")
+	})
+
+	t.Run("downloads file URLs", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(sourceDir, "tool-1.0.0"), artifact, 0o666)
+		assert.Nil(t, err)
+
+		manifest := Manifest{URL: "file://" + sourceDir + "/tool-{{.Version}}"}
+		path, err := manifest.Download("1.0.0", t.TempDir())
+		assert.Nil(t, err)
+		assert.FileExists(t, path)
+	})
+}
+
+func TestFindChecksum(t *testing.T) {
+	t.Run("returns error when name is not in checksums file", func(t *testing.T) {
+		_, err := findChecksum("tool", "abc  other\ndef  another\n")
+		assert.ErrorContains(t, err, "no checksum found for tool")
+	})
+
+	t.Run("lowercases checksum", func(t *testing.T) {
+		checksum, err := findChecksum("tool", "ABC  tool\n")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", checksum)
+	})
+}
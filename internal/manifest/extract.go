@@ -0,0 +1,361 @@
+package manifest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const binaryFormat = "binary"
+
+// formats maps each supported archive format to the file extension it is
+// detected by. No extension is a suffix of another so the order they are
+// checked in doesn't matter.
+var formats = map[string]string{
+	"tar.gz":     ".tar.gz",
+	"tgz":        ".tgz",
+	"tar.bz2":    ".tar.bz2",
+	"tar":        ".tar",
+	"zip":        ".zip",
+	binaryFormat: "",
+}
+
+func isFormat(format string) bool {
+	_, ok := formats[format]
+	return ok
+}
+
+// artifactFormat returns the format of the artifact, derived from its name if
+// the manifest doesn't specify one. Artifacts without a known archive
+// extension are installed as a binary.
+func (m Manifest) artifactFormat(name string) string {
+	if m.Format != "" {
+		return m.Format
+	}
+
+	for format, extension := range formats {
+		if extension != "" && strings.HasSuffix(name, extension) {
+			return format
+		}
+	}
+
+	return binaryFormat
+}
+
+// Install installs the downloaded release artifact for the version into
+// installPath. Archives are extracted, binaries are copied into the first bin
+// path under the manifest's binary name.
+func (m Manifest) Install(version, downloadPath, installPath string) error {
+	artifactPath, err := m.DownloadedArtifact(version, downloadPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(artifactPath); err != nil {
+		return fmt.Errorf("downloaded artifact not found: %w", err)
+	}
+
+	err = os.MkdirAll(installPath, 0o777)
+	if err != nil {
+		return fmt.Errorf("unable to create install dir: %w", err)
+	}
+
+	// Entries are checked against the real install dir, as the paths they are
+	// written to are resolved
+	installPath, err = filepath.EvalSymlinks(installPath)
+	if err != nil {
+		return fmt.Errorf("unable to create install dir: %w", err)
+	}
+
+	switch format := m.artifactFormat(filepath.Base(artifactPath)); format {
+	case "tar.gz", "tgz":
+		err = extractTar(artifactPath, installPath, m.StripComponents, func(reader io.Reader) (io.Reader, error) {
+			return gzip.NewReader(reader)
+		})
+	case "tar.bz2":
+		err = extractTar(artifactPath, installPath, m.StripComponents, func(reader io.Reader) (io.Reader, error) {
+			return bzip2.NewReader(reader), nil
+		})
+	case "tar":
+		err = extractTar(artifactPath, installPath, m.StripComponents, func(reader io.Reader) (io.Reader, error) {
+			return reader, nil
+		})
+	case "zip":
+		err = extractZip(artifactPath, installPath, m.StripComponents)
+	default:
+		binDir := filepath.Join(installPath, m.BinPaths[0])
+		err := os.MkdirAll(binDir, 0o777)
+		if err != nil {
+			return err
+		}
+
+		source, err := os.Open(artifactPath)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+
+		return writeFile(filepath.Join(binDir, m.BinaryName), source, 0o777)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return checkSymlinks(installPath)
+}
+
+func extractTar(archivePath, destination string, strip int, decompress func(io.Reader) (io.Reader, error)) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := decompress(file)
+	if err != nil {
+		return fmt.Errorf("unable to read archive %s: %w", archivePath, err)
+	}
+
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to read archive %s: %w", archivePath, err)
+		}
+
+		target, ok, err := entryPath(destination, header.Name, strip)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		err = prepareEntry(destination, target)
+		if err != nil {
+			return fmt.Errorf("unable to extract %s: %w", header.Name, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o777)
+		case tar.TypeReg:
+			err = writeFile(target, archive, header.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = writeSymlink(destination, target, header.Linkname)
+		case tar.TypeLink:
+			var linkTarget string
+			linkTarget, ok, err = entryPath(destination, header.Linkname, strip)
+			if err == nil && ok {
+				err = checkParent(destination, linkTarget)
+			}
+			if err == nil && ok {
+				err = os.Link(linkTarget, target)
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+func extractZip(archivePath, destination string, strip int) error {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("unable to read archive %s: %w", archivePath, err)
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		target, ok, err := entryPath(destination, entry.Name, strip)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		err = prepareEntry(destination, target)
+		if err != nil {
+			return fmt.Errorf("unable to extract %s: %w", entry.Name, err)
+		}
+
+		if entry.FileInfo().IsDir() {
+			err = os.MkdirAll(target, 0o777)
+		} else {
+			err = extractZipFile(entry, destination, target)
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to extract %s: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func extractZipFile(entry *zip.File, destination, target string) error {
+	reader, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	mode := entry.FileInfo().Mode()
+	if mode&fs.ModeSymlink != 0 {
+		linkname, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		return writeSymlink(destination, target, string(linkname))
+	}
+
+	return writeFile(target, reader, mode.Perm())
+}
+
+// entryPath returns the path an archive entry is extracted to after removing
+// strip leading path components. The boolean is false if nothing is left of the
+// entry's name. Entries that would be written outside the destination are
+// rejected.
+func entryPath(destination, name string, strip int) (string, bool, error) {
+	parts := strings.Split(strings.Trim(filepath.ToSlash(name), "/"), "/")
+	if len(parts) <= strip {
+		return "", false, nil
+	}
+
+	target := filepath.Join(destination, filepath.Join(parts[strip:]...))
+	if !withinDir(destination, target) {
+		return "", false, fmt.Errorf("archive entry %s is outside the install directory", name)
+	}
+
+	return target, true, nil
+}
+
+// prepareEntry returns an error if the entry would be written outside the
+// destination through a symlink extracted earlier, and removes any symlink
+// already at target so the entry replaces it rather than being written to the
+// file it points to
+func prepareEntry(destination, target string) error {
+	err := checkParent(destination, target)
+	if err != nil {
+		return err
+	}
+
+	if fileInfo, err := os.Lstat(target); err == nil && fileInfo.Mode()&fs.ModeSymlink != 0 {
+		return os.Remove(target)
+	}
+
+	return nil
+}
+
+// checkParent returns an error if the directory containing target is outside
+// the destination once symlinks are resolved. Directories that don't exist yet
+// are created by the entry, so their closest existing parent is checked.
+func checkParent(destination, target string) error {
+	for dir := filepath.Dir(target); ; dir = filepath.Dir(dir) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if errors.Is(err, fs.ErrNotExist) && dir != destination {
+			if fileInfo, err := os.Lstat(dir); err == nil && fileInfo.Mode()&fs.ModeSymlink != 0 {
+				return fmt.Errorf("%s is inside a broken symlink", target)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !withinDir(destination, resolved) {
+			return fmt.Errorf("%s is outside the install directory", target)
+		}
+
+		return nil
+	}
+}
+
+// checkSymlinks returns an error if any symlink in the extracted archive
+// resolves to a file outside the destination. Symlinks are checked as they are
+// extracted, but a later entry can change what an earlier symlink resolves to.
+func checkSymlinks(destination string) error {
+	return filepath.WalkDir(destination, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.Type()&fs.ModeSymlink == 0 {
+			return err
+		}
+
+		resolved, err := filepath.EvalSymlinks(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !withinDir(destination, resolved) {
+			return fmt.Errorf("symlink %s is outside the install directory", path)
+		}
+
+		return nil
+	})
+}
+
+func writeSymlink(destination, target, linkname string) error {
+	resolved := linkname
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(target), linkname)
+	}
+
+	if !withinDir(destination, resolved) {
+		return fmt.Errorf("symlink to %s is outside the install directory", linkname)
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), 0o777)
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(linkname, target)
+}
+
+func writeFile(target string, reader io.Reader, mode fs.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0o777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, reader)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+func withinDir(dir, path string) bool {
+	relative, err := filepath.Rel(dir, path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,211 @@
+package manifest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type archiveEntry struct {
+	name     string
+	contents string
+	mode     int64
+	linkname string
+}
+
+func TestInstall(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "tool-1.0.0/"},
+		{name: "tool-1.0.0/bin/tool", contents: "#!/bin/sh\n", mode: 0o755},
+		{name: "tool-1.0.0/README", contents: "readme", mode: 0o644},
+		{name: "tool-1.0.0/bin/tool-link", linkname: "tool"},
+	}
+
+	t.Run("extracts tar.gz stripping components", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		writeTarGz(t, filepath.Join(downloadPath, "tool-1.0.0.tar.gz"), entries)
+
+		manifest := Manifest{URL: "https://example.com/tool-{{.Version}}.tar.gz", StripComponents: 1}
+		err := manifest.Install("1.0.0", downloadPath, installPath)
+		assert.Nil(t, err)
+
+		fileInfo, err := os.Stat(filepath.Join(installPath, "bin", "tool"))
+		assert.Nil(t, err)
+		assert.NotZero(t, fileInfo.Mode()&0o111)
+		assert.FileExists(t, filepath.Join(installPath, "README"))
+
+		linkname, err := os.Readlink(filepath.Join(installPath, "bin", "tool-link"))
+		assert.Nil(t, err)
+		assert.Equal(t, "tool", linkname)
+	})
+
+	t.Run("extracts zip using format from manifest", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		writeZip(t, filepath.Join(downloadPath, "download"), entries[:3])
+
+		manifest := Manifest{URL: "https://example.com/download", Format: "zip", StripComponents: 1}
+		err := manifest.Install("1.0.0", downloadPath, installPath)
+		assert.Nil(t, err)
+
+		fileInfo, err := os.Stat(filepath.Join(installPath, "bin", "tool"))
+		assert.Nil(t, err)
+		assert.NotZero(t, fileInfo.Mode()&0o111)
+	})
+
+	t.Run("installs binary into first bin path", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		err := os.WriteFile(filepath.Join(downloadPath, "tool-linux-amd64"), []byte("binary"), 0o666)
+		assert.Nil(t, err)
+
+		manifest := Manifest{URL: "https://example.com/tool-linux-amd64", BinPaths: []string{"bin"}, BinaryName: "tool"}
+		err = manifest.Install("1.0.0", downloadPath, installPath)
+		assert.Nil(t, err)
+
+		fileInfo, err := os.Stat(filepath.Join(installPath, "bin", "tool"))
+		assert.Nil(t, err)
+		assert.NotZero(t, fileInfo.Mode()&0o111)
+	})
+
+	t.Run("returns error when artifact was not downloaded", func(t *testing.T) {
+		manifest := Manifest{URL: "https://example.com/tool.tar.gz"}
+		err := manifest.Install("1.0.0", t.TempDir(), t.TempDir())
+		assert.ErrorContains(t, err, "downloaded artifact not found")
+	})
+
+	t.Run("rejects entries outside the install directory", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		writeTarGz(t, filepath.Join(downloadPath, "tool.tar.gz"), []archiveEntry{{name: "../evil", contents: "evil", mode: 0o644}})
+
+		err := Manifest{URL: "https://example.com/tool.tar.gz"}.Install("1.0.0", downloadPath, installPath)
+		assert.ErrorContains(t, err, "outside the install directory")
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(installPath), "evil"))
+	})
+
+	t.Run("rejects symlinks outside the install directory", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		writeTarGz(t, filepath.Join(downloadPath, "tool.tar.gz"), []archiveEntry{{name: "passwd", linkname: "/etc/passwd"}})
+
+		err := Manifest{URL: "https://example.com/tool.tar.gz"}.Install("1.0.0", downloadPath, installPath)
+		assert.ErrorContains(t, err, "outside the install directory")
+	})
+
+	t.Run("rejects entries written through chained symlinks", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		writeTarGz(t, filepath.Join(downloadPath, "tool.tar.gz"), []archiveEntry{
+			{name: "b/"},
+			{name: "b/c", linkname: ".."},
+			{name: "a", linkname: "b/c/.."},
+			{name: "a/evil", contents: "evil", mode: 0o644},
+		})
+
+		err := Manifest{URL: "https://example.com/tool.tar.gz"}.Install("1.0.0", downloadPath, installPath)
+		assert.ErrorContains(t, err, "outside the install directory")
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(installPath), "evil"))
+	})
+
+	t.Run("rejects symlinks that resolve outside the install directory", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		writeTarGz(t, filepath.Join(downloadPath, "tool.tar.gz"), []archiveEntry{
+			{name: "b/"},
+			{name: "b/c", linkname: ".."},
+			{name: "a", linkname: "b/c/.."},
+		})
+
+		err := Manifest{URL: "https://example.com/tool.tar.gz"}.Install("1.0.0", downloadPath, installPath)
+		assert.ErrorContains(t, err, "outside the install directory")
+	})
+
+	t.Run("replaces symlinks instead of writing through them", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		outside := filepath.Join(filepath.Dir(installPath), "outside")
+		writeTarGz(t, filepath.Join(downloadPath, "tool.tar.gz"), []archiveEntry{
+			{name: "b/"},
+			{name: "b/c", linkname: ".."},
+			{name: "a", linkname: "b/c/../outside"},
+			{name: "a", contents: "tool", mode: 0o644},
+		})
+
+		err := Manifest{URL: "https://example.com/tool.tar.gz"}.Install("1.0.0", downloadPath, installPath)
+		assert.Nil(t, err)
+		assert.NoFileExists(t, outside)
+		contents, err := os.ReadFile(filepath.Join(installPath, "a"))
+		assert.Nil(t, err)
+		assert.Equal(t, "tool", string(contents))
+	})
+}
+
+func TestArtifactFormat(t *testing.T) {
+	tests := map[string]string{
+		"tool.tar.gz":  "tar.gz",
+		"tool.tgz":     "tgz",
+		"tool.tar.bz2": "tar.bz2",
+		"tool.tar":     "tar",
+		"tool.zip":     "zip",
+		"tool":         "binary",
+		"tool.exe":     "binary",
+	}
+
+	for name, format := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, format, Manifest{}.artifactFormat(name))
+		})
+	}
+
+	t.Run("manifest format takes precedence", func(t *testing.T) {
+		assert.Equal(t, "zip", Manifest{Format: "zip"}.artifactFormat("tool.tar.gz"))
+	})
+}
+
+func writeTarGz(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode, Size: int64(len(entry.contents)), Typeflag: tar.TypeReg}
+		switch {
+		case entry.linkname != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, entry.linkname, 0
+		case entry.name[len(entry.name)-1] == '/':
+			header.Typeflag, header.Mode = tar.TypeDir, 0o755
+		}
+
+		assert.Nil(t, tarWriter.WriteHeader(header))
+		_, err := tarWriter.Write([]byte(entry.contents))
+		assert.Nil(t, err)
+	}
+}
+
+func writeZip(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	defer zipWriter.Close()
+
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name}
+		header.SetMode(os.FileMode(entry.mode))
+		if entry.name[len(entry.name)-1] == '/' {
+			header.SetMode(os.ModeDir | 0o755)
+		}
+
+		writer, err := zipWriter.CreateHeader(header)
+		assert.Nil(t, err)
+		_, err = writer.Write([]byte(entry.contents))
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,212 @@
+// Package manifest implements declarative plugins. Instead of bash callbacks
+// these plugins contain a manifest file describing where to find the tool's
+// versions and release artifacts, and asdf downloads, verifies, extracts and
+// installs the artifacts itself.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/asdf-vm/asdf/internal/execute"
+	"gopkg.in/ini.v1"
+)
+
+// Filename is the name of the manifest file in the root of a plugin
+const Filename = "manifest.ini"
+
+const (
+	osSection   = "os"
+	archSection = "arch"
+)
+
+// Callbacks are the callbacks a manifest can provide in place of bin/ scripts
+var Callbacks = []string{"list-all", "download", "install", "list-bin-paths"}
+
+// Manifest describes how to install a tool that is distributed as prebuilt
+// release artifacts
+type Manifest struct {
+	// Dir is the plugin directory the manifest was loaded from
+	Dir string
+	// Versions is a static list of the versions available
+	Versions []string
+	// VersionsCommand is a shell command that prints the versions available,
+	// separated by whitespace. It is used when Versions is empty.
+	VersionsCommand string
+	// URL is a template for the URL of the release artifact
+	URL string
+	// ChecksumURL is a template for the URL of a file containing the SHA-256
+	// or SHA-512 checksum of the release artifact. Checksums aren't verified
+	// when it is empty.
+	ChecksumURL string
+	// Format is the archive format of the release artifact. When empty it is
+	// derived from the artifact URL.
+	Format string
+	// StripComponents is the number of leading path components removed from
+	// archive entries when extracting them
+	StripComponents int
+	// BinPaths are the directories, relative to the install path, containing
+	// the tool's executables
+	BinPaths []string
+	// BinaryName is the name the artifact is installed as when the format is
+	// binary
+	BinaryName string
+	// OSNames and ArchNames map Go's operating system and architecture names
+	// to the names used in release artifact URLs
+	OSNames   map[string]string
+	ArchNames map[string]string
+}
+
+// TemplateData is available to the URL and checksum URL templates
+type TemplateData struct {
+	Version string
+	OS      string
+	Arch    string
+}
+
+// Load reads the manifest from the plugin directory. The boolean is false if
+// the plugin has no manifest.
+func Load(pluginDir string) (Manifest, bool, error) {
+	path := filepath.Join(pluginDir, Filename)
+	file, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Manifest{}, false, nil
+		}
+
+		return Manifest{}, false, fmt.Errorf("unable to load plugin manifest %s: %w", path, err)
+	}
+
+	main := file.Section("")
+	manifest := Manifest{
+		Dir:             pluginDir,
+		Versions:        strings.Fields(main.Key("versions").String()),
+		VersionsCommand: strings.TrimSpace(main.Key("versions_command").String()),
+		URL:             strings.TrimSpace(main.Key("url").String()),
+		ChecksumURL:     strings.TrimSpace(main.Key("checksum_url").String()),
+		Format:          strings.TrimSpace(main.Key("format").String()),
+		BinPaths:        strings.Fields(main.Key("bin_paths").MustString("bin")),
+		BinaryName:      strings.TrimSpace(main.Key("binary_name").String()),
+		OSNames:         sectionMap(file, osSection),
+		ArchNames:       sectionMap(file, archSection),
+	}
+
+	if value := main.Key("strip_components").String(); value != "" {
+		manifest.StripComponents, err = strconv.Atoi(value)
+		if err != nil || manifest.StripComponents < 0 {
+			return manifest, true, fmt.Errorf("invalid strip_components value in plugin manifest %s: %s", path, value)
+		}
+	}
+
+	if len(manifest.BinPaths) == 0 {
+		manifest.BinPaths = []string{"bin"}
+	}
+
+	if manifest.BinaryName == "" {
+		manifest.BinaryName = filepath.Base(pluginDir)
+	}
+
+	if len(manifest.Versions) == 0 && manifest.VersionsCommand == "" {
+		return manifest, true, fmt.Errorf("plugin manifest %s must set versions or versions_command", path)
+	}
+
+	if manifest.URL == "" {
+		return manifest, true, fmt.Errorf("plugin manifest %s must set url", path)
+	}
+
+	if manifest.Format != "" && !isFormat(manifest.Format) {
+		return manifest, true, fmt.Errorf("unsupported format in plugin manifest %s: %s", path, manifest.Format)
+	}
+
+	return manifest, true, nil
+}
+
+// ListAll returns the versions of the tool that can be installed
+func (m Manifest) ListAll(env map[string]string) ([]string, error) {
+	if len(m.Versions) > 0 {
+		return m.Versions, nil
+	}
+
+	var stdout, stderr strings.Builder
+	cmd := execute.NewExpression(fmt.Sprintf("cd '%s' && %s", m.Dir, m.VersionsCommand), []string{})
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("versions_command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.Fields(stdout.String()), nil
+}
+
+// ArtifactURL returns the URL of the release artifact for the version on the
+// current operating system and architecture
+func (m Manifest) ArtifactURL(version string) (string, error) {
+	return m.render("url", m.URL, version)
+}
+
+// ArtifactChecksumURL returns the URL of the checksum file for the version's
+// release artifact, or an empty string if the manifest has no checksum URL
+func (m Manifest) ArtifactChecksumURL(version string) (string, error) {
+	if m.ChecksumURL == "" {
+		return "", nil
+	}
+
+	return m.render("checksum_url", m.ChecksumURL, version)
+}
+
+// templateData returns the values available to templates for the version
+func (m Manifest) templateData(version string) TemplateData {
+	return TemplateData{
+		Version: version,
+		OS:      mapName(m.OSNames, runtime.GOOS),
+		Arch:    mapName(m.ArchNames, runtime.GOARCH),
+	}
+}
+
+func (m Manifest) render(name, text, version string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template in plugin manifest: %w", name, err)
+	}
+
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, m.templateData(version))
+	if err != nil {
+		return "", fmt.Errorf("unable to render %s template in plugin manifest: %w", name, err)
+	}
+
+	return rendered.String(), nil
+}
+
+func sectionMap(file *ini.File, name string) map[string]string {
+	values := map[string]string{}
+
+	section, err := file.GetSection(name)
+	if err != nil {
+		return values
+	}
+
+	for _, key := range section.Keys() {
+		values[key.Name()] = strings.TrimSpace(key.String())
+	}
+
+	return values
+}
+
+func mapName(names map[string]string, name string) string {
+	if mapped, ok := names[name]; ok {
+		return mapped
+	}
+
+	return name
+}
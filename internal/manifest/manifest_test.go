@@ -0,0 +1,144 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("returns false when plugin has no manifest", func(t *testing.T) {
+		_, found, err := Load(t.TempDir())
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("returns manifest with defaults", func(t *testing.T) {
+		dir := writeManifest(t, "versions = 1.0.0 2.0.0\nurl = https://example.com/{{.Version}}.tar.gz\n")
+
+		manifest, found, err := Load(dir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"1.0.0", "2.0.0"}, manifest.Versions)
+		assert.Equal(t, []string{"bin"}, manifest.BinPaths)
+		assert.Equal(t, filepath.Base(dir), manifest.BinaryName)
+		assert.Zero(t, manifest.StripComponents)
+	})
+
+	t.Run("returns manifest with every setting", func(t *testing.T) {
+		contents := `versions_command = echo 1.0.0 # not a comment
+url = https://example.com/{{.Version}}/tool-{{.OS}}-{{.Arch}}
+checksum_url = https://example.com/{{.Version}}/SHA256SUMS
+format = binary
+strip_components = 2
+bin_paths = bin libexec
+binary_name = tool
+
+[os]
+darwin = macos
+
+[arch]
+amd64 = x86_64
+`
+		manifest, found, err := Load(writeManifest(t, contents))
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "echo 1.0.0 # not a comment", manifest.VersionsCommand)
+		assert.Equal(t, "https://example.com/{{.Version}}/SHA256SUMS", manifest.ChecksumURL)
+		assert.Equal(t, "binary", manifest.Format)
+		assert.Equal(t, 2, manifest.StripComponents)
+		assert.Equal(t, []string{"bin", "libexec"}, manifest.BinPaths)
+		assert.Equal(t, "tool", manifest.BinaryName)
+		assert.Equal(t, map[string]string{"darwin": "macos"}, manifest.OSNames)
+		assert.Equal(t, map[string]string{"amd64": "x86_64"}, manifest.ArchNames)
+	})
+
+	t.Run("returns error when versions are missing", func(t *testing.T) {
+		_, _, err := Load(writeManifest(t, "url = https://example.com/tool\n"))
+		assert.ErrorContains(t, err, "must set versions or versions_command")
+	})
+
+	t.Run("returns error when url is missing", func(t *testing.T) {
+		_, _, err := Load(writeManifest(t, "versions = 1.0.0\n"))
+		assert.ErrorContains(t, err, "must set url")
+	})
+
+	t.Run("returns error when format is unsupported", func(t *testing.T) {
+		_, _, err := Load(writeManifest(t, "versions = 1.0.0\nurl = https://example.com/tool\nformat = rar\n"))
+		assert.ErrorContains(t, err, "unsupported format")
+	})
+
+	t.Run("returns error when strip_components is invalid", func(t *testing.T) {
+		_, _, err := Load(writeManifest(t, "versions = 1.0.0\nurl = https://example.com/tool\nstrip_components = -1\n"))
+		assert.ErrorContains(t, err, "invalid strip_components")
+	})
+}
+
+func TestListAll(t *testing.T) {
+	t.Run("returns static versions", func(t *testing.T) {
+		versions, err := Manifest{Versions: []string{"1.0.0"}}.ListAll(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0"}, versions)
+	})
+
+	t.Run("returns versions printed by command run in plugin directory", func(t *testing.T) {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "versions.txt"), []byte("1.0.0\n1.1.0\n"), 0o666)
+		assert.Nil(t, err)
+
+		manifest := Manifest{Dir: dir, VersionsCommand: "cat versions.txt"}
+		versions, err := manifest.ListAll(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, versions)
+	})
+
+	t.Run("returns error when command fails", func(t *testing.T) {
+		manifest := Manifest{Dir: t.TempDir(), VersionsCommand: "echo broken >&2; false"}
+		_, err := manifest.ListAll(map[string]string{})
+		assert.ErrorContains(t, err, "versions_command failed")
+		assert.ErrorContains(t, err, "broken")
+	})
+}
+
+func TestArtifactURL(t *testing.T) {
+	manifest := Manifest{
+		URL:         "https://example.com/{{.Version}}/tool-{{.OS}}-{{.Arch}}.tar.gz",
+		ChecksumURL: "https://example.com/{{.Version}}/SHA256SUMS",
+		OSNames:     map[string]string{runtime.GOOS: "myos"},
+		ArchNames:   map[string]string{},
+	}
+
+	t.Run("renders version, mapped os and arch", func(t *testing.T) {
+		url, err := manifest.ArtifactURL("1.2.3")
+		assert.Nil(t, err)
+		assert.Equal(t, "https://example.com/1.2.3/tool-myos-"+runtime.GOARCH+".tar.gz", url)
+	})
+
+	t.Run("renders checksum URL", func(t *testing.T) {
+		url, err := manifest.ArtifactChecksumURL("1.2.3")
+		assert.Nil(t, err)
+		assert.Equal(t, "https://example.com/1.2.3/SHA256SUMS", url)
+	})
+
+	t.Run("returns empty checksum URL when not set", func(t *testing.T) {
+		url, err := Manifest{}.ArtifactChecksumURL("1.2.3")
+		assert.Nil(t, err)
+		assert.Empty(t, url)
+	})
+
+	t.Run("returns error for unknown template field", func(t *testing.T) {
+		_, err := Manifest{URL: "https://example.com/{{.Platform}}"}.ArtifactURL("1.2.3")
+		assert.ErrorContains(t, err, "unable to render url template")
+	})
+}
+
+func writeManifest(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, Filename), []byte(contents), 0o666)
+	assert.Nil(t, err)
+	return dir
+}
@@ -9,12 +9,12 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/asdf-vm/asdf/internal/data"
 	"github.com/asdf-vm/asdf/internal/git"
 	"gopkg.in/ini.v1"
 )
 
 const (
-	pluginIndexDir      = "plugin-index"
 	repoUpdatedFilename = "repo-updated"
 )
 
@@ -34,10 +34,12 @@ type Plugin struct {
 	URL  string
 }
 
-// Build returns a complete PluginIndex struct with default values set
-func Build(dataDir string, URL string, disableUpdate bool, updateDurationMinutes int) PluginIndex {
-	directory := filepath.Join(dataDir, pluginIndexDir)
-	return New(directory, URL, disableUpdate, updateDurationMinutes, &git.Repo{Directory: directory})
+// Build returns a complete PluginIndex struct with default values set. The Git
+// options are used when cloning and updating the index repository.
+func Build(cacheDir string, URL string, disableUpdate bool, updateDurationMinutes int, options git.Options) PluginIndex {
+	directory := data.PluginIndexDirectory(cacheDir)
+	repo := git.NewRepoWithOptions(directory, options)
+	return New(directory, URL, disableUpdate, updateDurationMinutes, &repo)
 }
 
 // New initializes a new PluginIndex instance with the options passed in.
@@ -0,0 +1,354 @@
+package plugins
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/execute"
+)
+
+// Backend implements the tool specific operations asdf performs when managing
+// versions of a tool. Plugin implements Backend by running the plugin's
+// callbacks. Other implementations manage tools in-process, such as tools
+// built into asdf or fakes in tests, and are wrapped in a Plugin with
+// NewWithBackend.
+//
+// The env passed to the methods contains the ASDF_INSTALL_TYPE,
+// ASDF_INSTALL_VERSION, ASDF_INSTALL_PATH and ASDF_DOWNLOAD_PATH variables
+// describing the tool version being operated on, as documented for plugin
+// callbacks. Methods return NoCallbackError for optional operations the
+// backend doesn't support.
+type Backend interface {
+	// ListAll returns all versions of the tool that can be installed, oldest
+	// first
+	ListAll() ([]string, error)
+	// Latest returns the latest stable version starting with query
+	Latest(query string) (string, error)
+	// Download downloads the tool version to ASDF_DOWNLOAD_PATH
+	Download(env map[string]string, stdOut, stdErr io.Writer) error
+	// Install installs the tool version to ASDF_INSTALL_PATH
+	Install(env map[string]string, stdOut, stdErr io.Writer) error
+	// BinPaths returns the directories relative to the install path that
+	// contain the tool's executables
+	BinPaths(env map[string]string) ([]string, error)
+	// ExecEnv returns env with the environment variables the tool version
+	// needs to run added
+	ExecEnv(env map[string]string) (map[string]string, error)
+	// LegacyFilenames returns the names of other version managers' version
+	// files the backend can read versions from
+	LegacyFilenames() ([]string, error)
+	// ParseLegacyFile returns the versions in a legacy version file
+	ParseLegacyFile(path string) ([]string, error)
+	// Uninstall removes anything the tool version left outside
+	// ASDF_INSTALL_PATH. asdf removes the install path itself.
+	Uninstall(env map[string]string, stdOut, stdErr io.Writer) error
+	// Help writes the tool's documentation on topic, one of overview, deps,
+	// config and links
+	Help(topic string, env map[string]string, stdOut, stdErr io.Writer) error
+}
+
+// NewWithBackend returns a Plugin named name whose tool operations are
+// implemented by backend rather than by callback scripts. Plugins are compared
+// and used as map keys so the backend must be comparable, e.g. a pointer.
+func NewWithBackend(config config.Config, name string, backend Backend) Plugin {
+	plugin := New(config, name)
+	plugin.backend = backend
+	return plugin
+}
+
+// ListAll invokes the list-all callback and returns the versions it prints
+func (p Plugin) ListAll() ([]string, error) {
+	if p.backend != nil {
+		return p.backend.ListAll()
+	}
+
+	var stdOut strings.Builder
+	var stdErr strings.Builder
+
+	if response, handled, err := p.protocolCallback("list-all", []string{}, map[string]string{}, &stdErr); handled {
+		if err != nil {
+			return []string{}, err
+		}
+
+		return response.Result.Versions, nil
+	}
+
+	err := p.RunCallback("list-all", []string{}, map[string]string{}, &stdOut, &stdErr)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return strings.Fields(stdOut.String()), nil
+}
+
+// Latest invokes the latest-stable callback and returns the version it prints
+func (p Plugin) Latest(query string) (string, error) {
+	if p.backend != nil {
+		return p.backend.Latest(query)
+	}
+
+	var stdOut strings.Builder
+	var stdErr strings.Builder
+
+	if response, handled, err := p.protocolCallback("latest-stable", []string{query}, map[string]string{}, &stdErr); handled {
+		return response.Result.Version, err
+	}
+
+	err := p.RunCallback("latest-stable", []string{query}, map[string]string{}, &stdOut, &stdErr)
+	return strings.TrimSpace(stdOut.String()), err
+}
+
+// Help invokes the help.<topic> callback
+func (p Plugin) Help(topic string, env map[string]string, stdOut, stdErr io.Writer) error {
+	if p.backend != nil {
+		return p.backend.Help(topic, env, stdOut, stdErr)
+	}
+
+	return p.RunCallback("help."+topic, []string{}, env, stdOut, stdErr)
+}
+
+// ResolveRef invokes the resolve-ref callback and returns the commit the
+// branch or tag currently points to upstream, as printed by the callback.
+// Backends can't resolve refs.
+func (p Plugin) ResolveRef(ref string) (string, error) {
+	if p.backend != nil {
+		return "", NoCallbackError{callback: "resolve-ref", plugin: p.Name}
+	}
+
+	var stdOut strings.Builder
+	var stdErr strings.Builder
+
+	err := p.RunCallback("resolve-ref", []string{ref}, map[string]string{}, &stdOut, &stdErr)
+	return strings.TrimSpace(stdOut.String()), err
+}
+
+// Download invokes the download callback
+func (p Plugin) Download(env map[string]string, stdOut, stdErr io.Writer) error {
+	if p.backend != nil {
+		return p.backend.Download(env, stdOut, stdErr)
+	}
+
+	return p.RunCallback("download", []string{}, env, stdOut, stdErr)
+}
+
+// Install invokes the install callback
+func (p Plugin) Install(env map[string]string, stdOut, stdErr io.Writer) error {
+	if p.backend != nil {
+		return p.backend.Install(env, stdOut, stdErr)
+	}
+
+	return p.RunCallback("install", []string{}, env, stdOut, stdErr)
+}
+
+// BinPaths invokes the list-bin-paths callback and returns the directories it
+// prints. If the callback is missing all executables are assumed to be in the
+// bin directory.
+func (p Plugin) BinPaths(env map[string]string) ([]string, error) {
+	if p.backend != nil {
+		return p.backend.BinPaths(env)
+	}
+
+	return p.cached("list-bin-paths", []string{}, func() ([]string, error) {
+		var stdOut strings.Builder
+		var stdErr strings.Builder
+
+		if response, handled, err := p.protocolCallback("list-bin-paths", []string{}, env, &stdErr); handled {
+			if err != nil {
+				return []string{}, err
+			}
+
+			return response.Result.Paths, nil
+		}
+
+		err := p.RunCallback("list-bin-paths", []string{}, env, &stdOut, &stdErr)
+		if err != nil {
+			if _, ok := err.(NoCallbackError); ok {
+				return []string{"bin"}, nil
+			}
+
+			return []string{}, err
+		}
+
+		var dirs []string
+		for _, dir := range strings.Split(stdOut.String(), " ") {
+			dirs = append(dirs, strings.TrimSpace(dir))
+		}
+
+		return dirs, nil
+	})
+}
+
+// ExecPath invokes the exec-path callback and returns the path of the
+// executable relative to the install path that it prints
+func (p Plugin) ExecPath(env map[string]string, installPath, shimName, relativePath string) (string, error) {
+	args := []string{installPath, shimName, relativePath}
+	lines, err := p.cached("exec-path", args, func() ([]string, error) {
+		var stdOut strings.Builder
+		var stdErr strings.Builder
+
+		err := p.RunCallback("exec-path", args, env, &stdOut, &stdErr)
+		return []string{strings.TrimSpace(stdOut.String())}, err
+	})
+
+	return strings.Join(lines, ""), err
+}
+
+// ExecEnv runs the exec-env callback if available and captures the
+// environment variables it sets. Plugins using the JSON protocol return the
+// variables directly instead.
+func (p Plugin) ExecEnv(env map[string]string) (map[string]string, error) {
+	if p.backend != nil {
+		return p.backend.ExecEnv(env)
+	}
+
+	callbackEnv := p.CallbackEnv(env)
+
+	if response, handled, err := p.protocolCallback(execEnvCallbackName, []string{}, env, os.Stderr); handled {
+		execEnv := maps.Clone(env)
+		if execEnv == nil {
+			execEnv = map[string]string{}
+		}
+		maps.Copy(execEnv, response.Result.Env)
+		return execEnv, err
+	}
+
+	execEnvPath, err := p.CallbackPath(execEnvCallbackName)
+	if err != nil {
+		return env, err
+	}
+
+	if p.WasmCallback(execEnvCallbackName) {
+		// WebAssembly callbacks can't be sourced so they print the variables
+		// to set instead
+		var stdOut strings.Builder
+		err = p.RunCallback(execEnvCallbackName, []string{}, env, &stdOut, os.Stderr)
+		execEnv := maps.Clone(env)
+		if execEnv == nil {
+			execEnv = map[string]string{}
+		}
+		maps.Copy(execEnv, envMap(stdOut.String()))
+		return execEnv, err
+	}
+
+	err = p.Verify()
+	if err != nil {
+		return env, err
+	}
+
+	var stdout strings.Builder
+
+	// This is done to support the legacy behavior. exec-env is the only asdf
+	// callback that works by exporting environment variables. Because of this,
+	// executing the callback isn't enough. We actually need to source it (.) so
+	// the environment variables get set, and then run `env` so they get printed
+	// to STDOUT.
+	expression := execute.NewExpression(fmt.Sprintf(". \"%s\"; env", execEnvPath), []string{})
+	expression.Env = callbackEnv
+	expression.Stdout = &stdout
+	err = expression.Run()
+
+	return envMap(stdout.String()), err
+}
+
+// LegacyFilenames returns a slice of filenames if the plugin contains the
+// list-legacy-filenames callback.
+func (p Plugin) LegacyFilenames() (filenames []string, err error) {
+	if p.backend != nil {
+		return p.backend.LegacyFilenames()
+	}
+
+	return p.cached("list-legacy-filenames", []string{}, func() (filenames []string, err error) {
+		var stdOut strings.Builder
+		var stdErr strings.Builder
+
+		if response, handled, err := p.protocolCallback("list-legacy-filenames", []string{}, map[string]string{}, &stdErr); handled {
+			if err != nil {
+				return []string{}, err
+			}
+
+			return response.Result.Filenames, nil
+		}
+
+		err = p.RunCallback("list-legacy-filenames", []string{}, map[string]string{}, &stdOut, &stdErr)
+		if err != nil {
+			_, ok := err.(NoCallbackError)
+			if ok {
+				return []string{}, nil
+			}
+
+			return []string{}, err
+		}
+
+		for _, filename := range strings.Split(stdOut.String(), " ") {
+			filenames = append(filenames, strings.TrimSpace(filename))
+		}
+		return filenames, nil
+	})
+}
+
+// ParseLegacyFile takes a file and uses the parse-legacy-file callback to parse
+// it if the plugin provides one. Otherwise just reads the file directly. In
+// either case the returned string is split on spaces and a slice of versions
+// is returned.
+func (p Plugin) ParseLegacyFile(path string) (versions []string, err error) {
+	if p.backend != nil {
+		return p.backend.ParseLegacyFile(path)
+	}
+
+	var stdOut strings.Builder
+	var stdErr strings.Builder
+
+	var rawVersions string
+
+	if response, handled, err := p.protocolCallback("parse-legacy-file", []string{path}, map[string]string{}, &stdErr); handled {
+		return response.Result.Versions, err
+	}
+
+	err = p.RunCallback("parse-legacy-file", []string{path}, map[string]string{}, &stdOut, &stdErr)
+	if _, ok := err.(NoCallbackError); ok {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return versions, err
+		}
+
+		rawVersions = string(bytes)
+	} else if err != nil {
+		return versions, err
+	} else {
+		rawVersions = stdOut.String()
+	}
+
+	for _, version := range strings.Split(rawVersions, " ") {
+		versions = append(versions, strings.TrimSpace(version))
+	}
+
+	return versions, nil
+}
+
+// Uninstall invokes the uninstall callback
+func (p Plugin) Uninstall(env map[string]string, stdOut, stdErr io.Writer) error {
+	if p.backend != nil {
+		return p.backend.Uninstall(env, stdOut, stdErr)
+	}
+
+	return p.RunCallback("uninstall", []string{}, env, stdOut, stdErr)
+}
+
+const execEnvCallbackName = "exec-env"
+
+func envMap(env string) map[string]string {
+	slice := map[string]string{}
+
+	for _, envVar := range strings.Split(env, "\n") {
+		varValue := strings.Split(envVar, "=")
+		if len(varValue) == 2 {
+			slice[varValue[0]] = varValue[1]
+		}
+	}
+
+	return slice
+}
@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginBackend(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+	_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+	plugin := New(conf, testPluginName)
+
+	t.Run("ListAll returns versions printed by list-all callback", func(t *testing.T) {
+		versions, err := plugin.ListAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.1.0", "2.0.0"}, versions)
+	})
+
+	t.Run("Latest returns version printed by latest-stable callback", func(t *testing.T) {
+		version, err := plugin.Latest("")
+		assert.Nil(t, err)
+		assert.Equal(t, "2.0.0", version)
+	})
+
+	t.Run("BinPaths defaults to bin when list-bin-paths callback is missing", func(t *testing.T) {
+		paths, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"bin"}, paths)
+	})
+
+	t.Run("ExecEnv returns environment with variables exported by exec-env callback", func(t *testing.T) {
+		assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, "exec-env", "#!/usr/bin/env bash\nexport FOO=bar"))
+		defer os.Remove(filepath.Join(plugin.Dir, "bin", "exec-env"))
+
+		env, err := plugin.ExecEnv(map[string]string{"ASDF_INSTALL_VERSION": "1.0.0"})
+		assert.Nil(t, err)
+		assert.Equal(t, "bar", env["FOO"])
+		assert.Equal(t, "1.0.0", env["ASDF_INSTALL_VERSION"])
+	})
+
+	t.Run("ExecEnv returns NoCallbackError when exec-env callback is missing", func(t *testing.T) {
+		_, err := plugin.ExecEnv(map[string]string{})
+		assert.IsType(t, NoCallbackError{}, err)
+	})
+}
+
+func TestNewWithBackend(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+	backend := &stubBackend{}
+	plugin := NewWithBackend(conf, "builtin", backend)
+
+	t.Run("exists without a plugin directory", func(t *testing.T) {
+		assert.Nil(t, plugin.Exists())
+	})
+
+	t.Run("delegates tool operations to backend", func(t *testing.T) {
+		versions, err := plugin.ListAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0"}, versions)
+
+		var stdout strings.Builder
+		err = plugin.Install(map[string]string{}, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, "installed\n", stdout.String())
+
+		stdout.Reset()
+		err = plugin.Help("overview", map[string]string{}, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, "overview\n", stdout.String())
+	})
+
+	t.Run("can be used as map key", func(t *testing.T) {
+		plugins := map[Plugin]bool{plugin: true}
+		assert.True(t, plugins[NewWithBackend(conf, "builtin", backend)])
+	})
+}
+
+// stubBackend embeds Plugin to provide the Backend methods it doesn't override.
+// The embedded plugin doesn't exist so those methods return errors.
+type stubBackend struct {
+	Plugin
+}
+
+func (b *stubBackend) ListAll() ([]string, error) {
+	return []string{"1.0.0"}, nil
+}
+
+func (b *stubBackend) Install(_ map[string]string, stdOut, _ io.Writer) error {
+	_, err := stdOut.Write([]byte("installed\n"))
+	return err
+}
+
+func (b *stubBackend) Help(topic string, _ map[string]string, stdOut, _ io.Writer) error {
+	_, err := stdOut.Write([]byte(topic + "\n"))
+	return err
+}
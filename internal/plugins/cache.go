@@ -0,0 +1,166 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/git"
+)
+
+// callbackCache is the cached output of a plugin's metadata callbacks, like
+// list-bin-paths, which only changes when the plugin itself changes. It is
+// stored in a JSON file per plugin in the cache directory.
+type callbackCache struct {
+	// Key identifies the state of the plugin the results were cached for. The
+	// results are discarded when the plugin's key changes.
+	Key string `json:"key"`
+	// Results maps callback names and arguments to the lines they printed
+	Results map[string][]string `json:"results"`
+}
+
+// ClearCache removes the cached callback output of the plugin
+func (p Plugin) ClearCache() error {
+	if p.cacheDir == "" {
+		return nil
+	}
+
+	err := os.Remove(p.cacheFile())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// ClearCaches removes the cached callback output of every plugin
+func ClearCaches(conf config.Config) error {
+	return os.RemoveAll(data.CallbackCacheDirectory(conf.CacheDirectory()))
+}
+
+// cached returns the cached output of the callback with the given arguments,
+// or runs it with run and caches the output if it isn't cached for the
+// plugin's current state. Errors are never cached.
+func (p Plugin) cached(callbackName string, args []string, run func() ([]string, error)) ([]string, error) {
+	if p.cacheDir == "" {
+		return run()
+	}
+
+	key, ok := p.cacheKey()
+	if !ok {
+		return run()
+	}
+
+	entry := strings.Join(append([]string{callbackName}, args...), " ")
+	cache := p.loadCache(key)
+	if result, ok := cache.Results[entry]; ok {
+		return result, nil
+	}
+
+	result, err := run()
+	if err != nil {
+		return result, err
+	}
+
+	cache.Results[entry] = result
+	// The cache only saves callback invocations, failing to write it doesn't
+	// affect the result
+	p.saveCache(cache)
+
+	return result, nil
+}
+
+// cacheKey returns the key identifying the plugin's current state. This is
+// the commit checked out for plugins managed with Git, combined with the
+// latest modification time of the plugin's callbacks, as the callbacks of
+// linked plugins and plugins under development may change without a commit.
+func (p Plugin) cacheKey() (string, bool) {
+	dir := filepath.Join(p.Dir, "bin")
+	latest, err := os.Stat(dir)
+	if err != nil {
+		return "", false
+	}
+
+	modTime := latest.ModTime()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	key := fmt.Sprintf("mtime-%d", modTime.UnixNano())
+	if _, linked := p.Linked(); !linked {
+		if head, err := git.NewRepo(p.Dir).Head(); err == nil {
+			key = "git-" + head + "-" + key
+		}
+	}
+
+	return key, true
+}
+
+func (p Plugin) cacheFile() string {
+	return filepath.Join(p.cacheDir, p.Name+".json")
+}
+
+// loadCache returns the plugin's cache, or an empty cache if it doesn't exist
+// or was stored for a different key
+func (p Plugin) loadCache(key string) callbackCache {
+	cache := callbackCache{}
+
+	contents, err := os.ReadFile(p.cacheFile())
+	if err == nil {
+		err = json.Unmarshal(contents, &cache)
+	}
+
+	if err != nil || cache.Key != key || cache.Results == nil {
+		return callbackCache{Key: key, Results: map[string][]string{}}
+	}
+
+	return cache
+}
+
+// saveCache writes the cache to a temporary file first and renames it, so
+// concurrent asdf processes never read a partially written cache
+func (p Plugin) saveCache(cache callbackCache) error {
+	contents, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(p.cacheDir, 0o777)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(p.cacheDir, p.Name+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(contents)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), p.cacheFile())
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,121 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCached(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+	plugin := New(conf, testPluginName)
+	callback := filepath.Join(plugin.Dir, "bin", "list-bin-paths")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(callback), 0o777))
+
+	writeCallback := func(t *testing.T, contents string, modTime time.Time) {
+		t.Helper()
+		assert.Nil(t, os.WriteFile(callback, []byte(contents), 0o777))
+		assert.Nil(t, os.Chtimes(callback, modTime, modTime))
+		assert.Nil(t, os.Chtimes(filepath.Dir(callback), modTime, modTime))
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	writeCallback(t, "#!/usr/bin/env bash\necho 'foo bar'\n", modTime)
+
+	t.Run("returns callback output", func(t *testing.T) {
+		dirs, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, dirs)
+		assert.FileExists(t, plugin.cacheFile())
+	})
+
+	t.Run("returns cached output while the plugin is unchanged", func(t *testing.T) {
+		writeCallback(t, "#!/usr/bin/env bash\necho 'baz'\n", modTime)
+
+		dirs, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, dirs)
+	})
+
+	t.Run("runs callback again when the plugin changes", func(t *testing.T) {
+		writeCallback(t, "#!/usr/bin/env bash\necho 'baz'\n", modTime.Add(time.Minute))
+
+		dirs, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"baz"}, dirs)
+	})
+
+	t.Run("runs callback again once the cache is cleared", func(t *testing.T) {
+		writeCallback(t, "#!/usr/bin/env bash\necho 'qux'\n", modTime.Add(time.Minute))
+
+		assert.Nil(t, plugin.ClearCache())
+		assert.NoFileExists(t, plugin.cacheFile())
+
+		dirs, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"qux"}, dirs)
+	})
+
+	t.Run("caches output separately for each set of arguments", func(t *testing.T) {
+		execPath := filepath.Join(plugin.Dir, "bin", "exec-path")
+		assert.Nil(t, os.WriteFile(execPath, []byte("#!/usr/bin/env bash\necho \"custom/$2\"\n"), 0o777))
+
+		path, err := plugin.ExecPath(map[string]string{}, "/install", "foo", "bin/foo")
+		assert.Nil(t, err)
+		assert.Equal(t, "custom/foo", path)
+
+		path, err = plugin.ExecPath(map[string]string{}, "/install", "bar", "bin/bar")
+		assert.Nil(t, err)
+		assert.Equal(t, "custom/bar", path)
+	})
+
+	t.Run("doesn't cache errors", func(t *testing.T) {
+		assert.Nil(t, os.Remove(callback))
+		assert.Nil(t, plugin.ClearCache())
+
+		_, err := plugin.LegacyFilenames()
+		assert.Nil(t, err)
+
+		writeCallback(t, "#!/usr/bin/env bash\nexit 1\n", time.Now())
+		_, err = plugin.BinPaths(map[string]string{})
+		assert.NotNil(t, err)
+
+		writeCallback(t, "#!/usr/bin/env bash\necho 'foo'\n", time.Now())
+		dirs, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"foo"}, dirs)
+	})
+}
+
+func TestClearCaches(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+	plugin := New(conf, testPluginName)
+	assert.Nil(t, plugin.saveCache(callbackCache{Key: "key", Results: map[string][]string{}}))
+	assert.FileExists(t, plugin.cacheFile())
+
+	assert.Nil(t, ClearCaches(conf))
+	assert.NoFileExists(t, plugin.cacheFile())
+}
+
+func TestCachedListedPlugins(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir()}
+	callback := filepath.Join(New(conf, testPluginName).Dir, "bin", "list-bin-paths")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(callback), 0o777))
+	assert.Nil(t, os.WriteFile(callback, []byte("#!/usr/bin/env bash\necho 'foo bar'\n"), 0o777))
+	modTime := time.Now().Add(-time.Hour)
+	assert.Nil(t, os.Chtimes(callback, modTime, modTime))
+	assert.Nil(t, os.Chtimes(filepath.Dir(callback), modTime, modTime))
+
+	plugins, err := List(conf, false, false)
+	assert.Nil(t, err)
+	assert.Len(t, plugins, 1)
+
+	dirs, err := plugins[0].BinPaths(map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, dirs)
+	assert.FileExists(t, plugins[0].cacheFile())
+}
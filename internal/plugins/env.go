@@ -0,0 +1,81 @@
+package plugins
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+)
+
+// OptionEnvPrefix is the prefix of the environment variables the options in a
+// plugin's `[plugin "<name>"]` asdfrc section are passed to callbacks in
+const OptionEnvPrefix = "ASDF_PLUGIN_OPT_"
+
+// CallbackEnv returns env with the variables every callback receives added:
+// ASDF_PLUGIN_NAME, ASDF_PLUGIN_PATH, ASDF_DATA_DIR and an ASDF_PLUGIN_OPT_
+// variable for each of the plugin's options. An empty env means the callback
+// inherits the environment of asdf, so the current environment is used as the
+// base in that case.
+func (p Plugin) CallbackEnv(env map[string]string) map[string]string {
+	callbackEnv := map[string]string{}
+	if len(env) == 0 {
+		for _, variable := range os.Environ() {
+			if key, value, ok := strings.Cut(variable, "="); ok {
+				callbackEnv[key] = value
+			}
+		}
+	}
+
+	for key, value := range env {
+		callbackEnv[key] = value
+	}
+
+	for _, variable := range strings.Split(p.optionEnv, optionEnvSeparator) {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			callbackEnv[key] = value
+		}
+	}
+
+	callbackEnv["ASDF_PLUGIN_NAME"] = p.Name
+	callbackEnv["ASDF_PLUGIN_PATH"] = p.Dir
+	callbackEnv["ASDF_DATA_DIR"] = p.dataDir
+
+	return callbackEnv
+}
+
+// optionEnvSeparator separates variables in an encoded option env. Variables
+// can't contain NUL characters, so it never appears in a name or value.
+const optionEnvSeparator = "\x00"
+
+// encodeOptionEnv returns the variables the plugin's options are passed to
+// callbacks in as KEY=value pairs, sorted and joined by optionEnvSeparator.
+// They are read from the config the plugin is created with, so callbacks don't
+// reload the asdfrc file. Errors are ignored, like in GitOptions, leaving the
+// plugin without options.
+func encodeOptionEnv(conf config.Config, pluginName string) string {
+	options, _ := conf.PluginOptions(pluginName)
+
+	variables := []string{}
+	for key, value := range options {
+		variables = append(variables, OptionEnvName(key)+"="+value)
+	}
+	sort.Strings(variables)
+
+	return strings.Join(variables, optionEnvSeparator)
+}
+
+// OptionEnvName returns the name of the environment variable a plugin option
+// is passed to callbacks in. The key is upper cased and characters that aren't
+// valid in variable names are replaced with underscores.
+func OptionEnvName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, strings.ToUpper(key))
+
+	return OptionEnvPrefix + name
+}
@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/manifest"
+)
+
+// ManifestCallback returns true if the callback is provided by the plugin's
+// manifest rather than a script in bin/
+func (p Plugin) ManifestCallback(name string) bool {
+	if _, err := p.CallbackPath(name); err == nil {
+		return false
+	}
+
+	_, found, err := p.manifest(name)
+	return err == nil && found
+}
+
+// manifest loads the plugin's manifest if it provides the callback
+func (p Plugin) manifest(callback string) (manifest.Manifest, bool, error) {
+	if !slices.Contains(manifest.Callbacks, callback) {
+		return manifest.Manifest{}, false, nil
+	}
+
+	return manifest.Load(p.Dir)
+}
+
+// runManifestCallback implements a callback natively using the plugin's
+// manifest, with the same environment and output as the bash callback
+func runManifestCallback(pluginManifest manifest.Manifest, name string, env map[string]string, stdOut io.Writer) error {
+	switch name {
+	case "list-all":
+		versions, err := pluginManifest.ListAll(env)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(stdOut, strings.Join(versions, " "))
+		return err
+	case "list-bin-paths":
+		_, err := fmt.Fprintln(stdOut, strings.Join(pluginManifest.BinPaths, " "))
+		return err
+	}
+
+	if env["ASDF_INSTALL_TYPE"] != "version" {
+		return fmt.Errorf("plugin manifests only support installing versions, not %s", env["ASDF_INSTALL_TYPE"])
+	}
+
+	version := env["ASDF_INSTALL_VERSION"]
+
+	switch name {
+	case "download":
+		artifactURL, err := pluginManifest.ArtifactURL(version)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdOut, "Downloading %s\n", artifactURL)
+		_, err = pluginManifest.Download(version, env["ASDF_DOWNLOAD_PATH"])
+		return err
+	case "install":
+		return pluginManifest.Install(version, env["ASDF_DOWNLOAD_PATH"], env["ASDF_INSTALL_PATH"])
+	}
+
+	return fmt.Errorf("plugin manifests do not provide a %s callback", name)
+}
@@ -0,0 +1,92 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCallbackWithManifest(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	artifactDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(artifactDir, "tool-1.0.0"), []byte("#!/bin/sh\n"), 0o666)
+	assert.Nil(t, err)
+
+	pluginDir := data.PluginDirectory(testDataDir, "tool")
+	err = os.MkdirAll(pluginDir, 0o777)
+	assert.Nil(t, err)
+	contents := "versions = 1.0.0 2.0.0\nurl = file://" + artifactDir + "/tool-{{.Version}}\n"
+	err = os.WriteFile(filepath.Join(pluginDir, manifest.Filename), []byte(contents), 0o666)
+	assert.Nil(t, err)
+
+	plugin := New(conf, "tool")
+
+	t.Run("lists versions from manifest", func(t *testing.T) {
+		var stdout strings.Builder
+		err := plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, "1.0.0 2.0.0\n", stdout.String())
+	})
+
+	t.Run("lists bin paths from manifest", func(t *testing.T) {
+		var stdout strings.Builder
+		err := plugin.RunCallback("list-bin-paths", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, "bin\n", stdout.String())
+	})
+
+	t.Run("downloads and installs artifact from manifest", func(t *testing.T) {
+		var stdout strings.Builder
+		env := map[string]string{
+			"ASDF_INSTALL_TYPE":    "version",
+			"ASDF_INSTALL_VERSION": "1.0.0",
+			"ASDF_DOWNLOAD_PATH":   filepath.Join(t.TempDir(), "download"),
+			"ASDF_INSTALL_PATH":    filepath.Join(t.TempDir(), "install"),
+		}
+
+		err := plugin.RunCallback("download", []string{}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+		err = plugin.RunCallback("install", []string{}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.FileExists(t, filepath.Join(env["ASDF_INSTALL_PATH"], "bin", "tool"))
+	})
+
+	t.Run("returns error when installing a ref", func(t *testing.T) {
+		var stdout strings.Builder
+		env := map[string]string{"ASDF_INSTALL_TYPE": "ref", "ASDF_INSTALL_VERSION": "main"}
+		err := plugin.RunCallback("download", []string{}, env, &stdout, &stdout)
+		assert.ErrorContains(t, err, "only support installing versions")
+	})
+
+	t.Run("returns NoCallbackError for callbacks the manifest does not provide", func(t *testing.T) {
+		var stdout strings.Builder
+		err := plugin.RunCallback("exec-env", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.IsType(t, NoCallbackError{}, err)
+		assert.False(t, plugin.ManifestCallback("exec-env"))
+	})
+
+	t.Run("prefers callback scripts over the manifest", func(t *testing.T) {
+		assert.True(t, plugin.ManifestCallback("list-all"))
+
+		err := os.MkdirAll(filepath.Join(pluginDir, "bin"), 0o777)
+		assert.Nil(t, err)
+		err = os.WriteFile(filepath.Join(pluginDir, "bin", "list-all"), []byte("#!/usr/bin/env bash\necho 3.0.0\n"), 0o777)
+		assert.Nil(t, err)
+		defer os.RemoveAll(filepath.Join(pluginDir, "bin"))
+
+		var stdout strings.Builder
+		err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, "3.0.0\n", stdout.String())
+		assert.False(t, plugin.ManifestCallback("list-all"))
+	})
+}
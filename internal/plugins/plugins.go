@@ -2,6 +2,7 @@
 package plugins
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/asdf-vm/asdf/internal/config"
 	"github.com/asdf-vm/asdf/internal/data"
@@ -17,6 +19,7 @@ import (
 	"github.com/asdf-vm/asdf/internal/git"
 	"github.com/asdf-vm/asdf/internal/hook"
 	"github.com/asdf-vm/asdf/internal/pluginindex"
+	"github.com/asdf-vm/asdf/internal/wasm"
 )
 
 // NewPluginAlreadyExists generates a new PluginAlreadyExists error instance for
@@ -74,84 +77,81 @@ const (
 	pluginMissingMsg       = "Plugin named %s not installed"
 	hasNoCallbackMsg       = "Plugin named %s does not have a callback named %s"
 	hasNoCommandMsg        = "Plugin named %s does not have a extension command named %s"
+	pluginLinkedMsg        = "Plugin named %s is linked to %s, skipping update\n"
 )
 
+// RequiredCallbacks are the callbacks every plugin must provide
+var RequiredCallbacks = []string{"download", "install", "list-all"}
+
+// AllCallbacks are all the callbacks asdf knows how to invoke, required and
+// optional
+var AllCallbacks = []string{"download", "install", "list-all", "latest-stable", "help.overview", "help.deps", "help.config", "help.links", "list-bin-paths", "exec-env", "exec-path", "uninstall", "resolve-ref", "list-legacy-filenames", "parse-legacy-file", "post-plugin-add", "post-plugin-update", "pre-plugin-remove"}
+
+// DefaultUpdateConcurrency is the number of plugins updated at the same time
+// by UpdateAll when the user doesn't specify a different value
+const DefaultUpdateConcurrency = 4
+
 // Plugin struct represents an asdf plugin to all asdf code. The name and dir
 // fields are the most used fields. Ref and Dir only still git info, which is
 // only information and shown to the user at times.
 type Plugin struct {
-	Name string
-	Dir  string
-	Ref  string
-	URL  string
+	Name    string
+	Dir     string
+	Ref     string
+	URL     string
+	policy  policySource
+	backend Backend
+	// cacheDir is the directory callback output is cached in, empty if it
+	// isn't cached
+	cacheDir string
+	// dataDir is the data directory the plugin was created for
+	dataDir string
+	// optionEnv holds the variables the plugin's options are passed to
+	// callbacks in, encoded by encodeOptionEnv so Plugin stays comparable
+	optionEnv string
 }
 
 // New takes config and a plugin name and returns a Plugin struct. It is
 // intended for functions that need to quickly initialize a plugin.
 func New(config config.Config, name string) Plugin {
 	pluginsDir := data.PluginDirectory(config.DataDir, name)
-	return Plugin{Dir: pluginsDir, Name: name}
-}
-
-// LegacyFilenames returns a slice of filenames if the plugin contains the
-// list-legacy-filenames callback.
-func (p Plugin) LegacyFilenames() (filenames []string, err error) {
-	var stdOut strings.Builder
-	var stdErr strings.Builder
-	err = p.RunCallback("list-legacy-filenames", []string{}, map[string]string{}, &stdOut, &stdErr)
-	if err != nil {
-		_, ok := err.(NoCallbackError)
-		if ok {
-			return []string{}, nil
-		}
-
-		return []string{}, err
+	plugin := Plugin{
+		Dir:       pluginsDir,
+		Name:      name,
+		policy:    newPolicySource(config),
+		dataDir:   config.DataDir,
+		optionEnv: encodeOptionEnv(config, name),
 	}
-
-	for _, filename := range strings.Split(stdOut.String(), " ") {
-		filenames = append(filenames, strings.TrimSpace(filename))
+	if cacheDir := config.CacheDirectory(); cacheDir != "" {
+		plugin.cacheDir = data.CallbackCacheDirectory(cacheDir)
 	}
-	return filenames, nil
-}
-
-// ParseLegacyVersionFile takes a file and uses the parse-legacy-file callback
-// script to parse it if the script is present. Otherwise just reads the file
-// directly. In either case the returned string is split on spaces and a slice
-// of versions is returned.
-func (p Plugin) ParseLegacyVersionFile(path string) (versions []string, err error) {
-	parseLegacyFileName := "parse-legacy-file"
-	parseCallbackPath := filepath.Join(p.Dir, "bin", parseLegacyFileName)
 
-	var rawVersions string
-
-	if _, err := os.Stat(parseCallbackPath); err == nil {
-		var stdOut strings.Builder
-		var stdErr strings.Builder
-
-		err = p.RunCallback(parseLegacyFileName, []string{path}, map[string]string{}, &stdOut, &stdErr)
-		if err != nil {
-			return versions, err
-		}
-
-		rawVersions = stdOut.String()
-	} else {
-		bytes, err := os.ReadFile(path)
-		if err != nil {
-			return versions, err
-		}
+	return plugin
+}
 
-		rawVersions = string(bytes)
+// Linked returns the path of the working copy the plugin is linked to and true
+// if the plugin was added with AddLink. Linked plugins are not managed with Git.
+func (p Plugin) Linked() (string, bool) {
+	fileInfo, err := os.Lstat(p.Dir)
+	if err != nil || fileInfo.Mode()&fs.ModeSymlink == 0 {
+		return "", false
 	}
 
-	for _, version := range strings.Split(rawVersions, " ") {
-		versions = append(versions, strings.TrimSpace(version))
+	target, err := os.Readlink(p.Dir)
+	if err != nil {
+		return "", false
 	}
 
-	return versions, err
+	return target, true
 }
 
 // Exists returns a boolean indicating whether or not the plugin exists on disk.
+// Plugins with an in-process backend always exist.
 func (p Plugin) Exists() error {
+	if p.backend != nil {
+		return nil
+	}
+
 	exists, err := directoryExists(p.Dir)
 	if err != nil {
 		return err
@@ -164,13 +164,51 @@ func (p Plugin) Exists() error {
 	return nil
 }
 
-// RunCallback invokes a callback with the given name if it exists for the plugin
+// RunCallback invokes a callback with the given name if it exists for the
+// plugin. The callback receives environment with the variables from
+// CallbackEnv added.
 func (p Plugin) RunCallback(name string, arguments []string, environment map[string]string, stdOut io.Writer, errOut io.Writer) error {
+	environment = p.CallbackEnv(environment)
+
+	response, handled, err := p.protocolCallback(name, arguments, environment, errOut)
+	if handled {
+		if err != nil {
+			return err
+		}
+
+		return writeProtocolResult(name, response.Result, stdOut)
+	}
+
 	callback, err := p.CallbackPath(name)
+	if _, ok := err.(NoCallbackError); ok {
+		pluginManifest, found, manifestErr := p.manifest(name)
+		if manifestErr != nil {
+			return manifestErr
+		}
+
+		if found {
+			err = p.Verify()
+			if err != nil {
+				return err
+			}
+
+			return runManifestCallback(pluginManifest, name, environment, stdOut)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = p.Verify()
 	if err != nil {
 		return err
 	}
 
+	if filepath.Ext(callback) == wasm.Extension {
+		return p.runWasmCallback(name, callback, arguments, environment, stdOut, errOut)
+	}
+
 	cmd := execute.New(fmt.Sprintf("'%s'", callback), arguments)
 	cmd.Env = environment
 
@@ -180,10 +218,16 @@ func (p Plugin) RunCallback(name string, arguments []string, environment map[str
 	return cmd.Run()
 }
 
-// CallbackPath returns the full file path to a callback script
+// CallbackPath returns the full file path to a callback script, or to a
+// WebAssembly callback if the plugin has no script for the callback
 func (p Plugin) CallbackPath(name string) (string, error) {
 	path := filepath.Join(p.Dir, "bin", name)
 	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		path += wasm.Extension
+		_, err = os.Stat(path)
+	}
+
 	if errors.Is(err, os.ErrNotExist) {
 		return "", NoCallbackError{callback: name, plugin: p.Name}
 	}
@@ -237,21 +281,86 @@ func (p Plugin) ExtensionCommandPath(name string) (string, error) {
 	return path, nil
 }
 
+// UpdateResult records the outcome of updating a single plugin. OldSHA and
+// NewSHA are the plugin's HEAD commit before and after the update.
+type UpdateResult struct {
+	Name   string
+	Ref    string
+	OldSHA string
+	NewSHA string
+	Linked bool
+	Err    error
+}
+
 // Update a plugin to a specific ref, or if no ref provided update to latest
 func (p Plugin) Update(conf config.Config, ref string, out, errout io.Writer) (string, error) {
+	result := p.update(conf, ref, out, errout)
+	return result.Ref, result.Err
+}
+
+func (p Plugin) update(conf config.Config, ref string, out, errout io.Writer) UpdateResult {
+	result := UpdateResult{Name: p.Name}
+
 	err := p.Exists()
 	if err != nil {
-		return "", fmt.Errorf("no such plugin: %s", p.Name)
+		result.Err = fmt.Errorf("no such plugin: %s", p.Name)
+		return result
 	}
 
-	repo := git.NewRepo(p.Dir)
+	if target, linked := p.Linked(); linked {
+		// Changes to a linked plugin are made in the working copy itself
+		fmt.Fprintf(out, pluginLinkedMsg, p.Name, target)
+		result.Linked = true
+		return result
+	}
 
-	hook.Run(conf, "pre_asdf_plugin_update", []string{p.Name})
-	hook.Run(conf, fmt.Sprintf("pre_asdf_plugin_update_%s", p.Name), []string{p.Name})
+	policy, err := LoadPolicy(conf)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	gitOptions := GitOptions(conf)
+	if _, pinned := policy.Pin(p.Name); pinned {
+		// A shallow fetch may not include the pinned commit
+		gitOptions.Depth = 0
+	}
+	repo := git.NewRepoWithOptions(p.Dir, gitOptions)
+
+	if policy.RestrictsURLs() {
+		url, err := repo.RemoteURL()
+		if err != nil {
+			result.Err = PolicyViolationError{plugin: p.Name, reason: fmt.Sprintf("unable to determine plugin source: %s", err)}
+			return result
+		}
+
+		// The URL is checked after rewrites, as that is the URL actually fetched
+		err = policy.CheckURL(p.Name, gitOptions.RewriteURL(url))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	hook.RunWithOutput(conf, "pre_asdf_plugin_update", []string{p.Name}, out, errout)
+	hook.RunWithOutput(conf, fmt.Sprintf("pre_asdf_plugin_update_%s", p.Name), []string{p.Name}, out, errout)
 
 	newRef, oldSHA, newSHA, err := repo.Update(ref)
+	result.Ref, result.OldSHA, result.NewSHA = newRef, oldSHA, newSHA
+	// The cache is keyed by commit, clearing it just discards the stale entry
+	p.ClearCache()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	err = policy.CheckCommit(p.Name, newSHA)
 	if err != nil {
-		return newRef, err
+		// Go back to the commit the plugin was on so it remains usable
+		repo.Reset(oldSHA)
+		result.NewSHA = oldSHA
+		result.Err = err
+		return result
 	}
 
 	env := map[string]string{
@@ -261,11 +370,53 @@ func (p Plugin) Update(conf config.Config, ref string, out, errout io.Writer) (s
 	}
 
 	err = p.RunCallback("post-plugin-update", []string{}, env, out, errout)
+	if _, ok := err.(NoCallbackError); !ok {
+		result.Err = err
+	}
 
-	hook.Run(conf, "post_asdf_plugin_update", []string{p.Name})
-	hook.Run(conf, fmt.Sprintf("post_asdf_plugin_update_%s", p.Name), []string{})
+	hook.RunWithOutput(conf, "post_asdf_plugin_update", []string{p.Name}, out, errout)
+	hook.RunWithOutput(conf, fmt.Sprintf("post_asdf_plugin_update_%s", p.Name), []string{}, out, errout)
 
-	return newRef, err
+	return result
+}
+
+// UpdateAll updates every plugin in the slice to the latest commit on its
+// current branch, running at most concurrency updates at the same time. Output
+// from each plugin's hooks and callbacks is buffered and written to out and
+// errout once that plugin's update finishes, so output from different plugins
+// is never interleaved. Results are returned in the same order as the plugins.
+func UpdateAll(conf config.Config, plugins []Plugin, concurrency int, out, errout io.Writer) []UpdateResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]UpdateResult, len(plugins))
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	var outputLock sync.Mutex
+
+	for i, plugin := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			var pluginOut, pluginErr bytes.Buffer
+			results[i] = plugin.update(conf, "", &pluginOut, &pluginErr)
+
+			outputLock.Lock()
+			defer outputLock.Unlock()
+			out.Write(pluginOut.Bytes())
+			errout.Write(pluginErr.Bytes())
+		}()
+	}
+
+	wg.Wait()
+
+	return results
 }
 
 // List takes config and flags for what to return and builds a list of plugins
@@ -282,45 +433,51 @@ func List(config config.Config, urls, refs bool) (plugins []Plugin, err error) {
 	}
 
 	for _, file := range files {
-		if file.IsDir() {
-			if refs || urls {
-				var url string
-				var refString string
-				location := filepath.Join(pluginsDir, file.Name())
-				repo := git.NewRepo(location)
-
-				// TODO: Improve these error messages
-				if err != nil {
+		location := filepath.Join(pluginsDir, file.Name())
+
+		if !file.IsDir() {
+			// Linked plugins are symlinks to a directory elsewhere on disk
+			if file.Type()&fs.ModeSymlink == 0 {
+				continue
+			}
+
+			if exists, _ := directoryExists(location); !exists {
+				continue
+			}
+		}
+
+		plugin := New(config, file.Name())
+
+		if refs || urls {
+			target, linked := plugin.Linked()
+			repo := git.NewRepo(location)
+
+			// A linked working copy doesn't have to be a Git repository, so
+			// Git errors are only fatal for plugins asdf cloned itself
+			if refs {
+				ref, err := repo.Head()
+				if err != nil && !linked {
 					return plugins, err
 				}
 
-				if refs {
-					refString, err = repo.Head()
-					if err != nil {
-						return plugins, err
-					}
+				plugin.Ref = ref
+			}
+
+			if urls {
+				url, err := repo.RemoteURL()
+				if err != nil && !linked {
+					return plugins, err
 				}
 
-				if urls {
-					url, err = repo.RemoteURL()
-					if err != nil {
-						return plugins, err
-					}
+				if url == "" {
+					url = target
 				}
 
-				plugins = append(plugins, Plugin{
-					Name: file.Name(),
-					Dir:  location,
-					URL:  url,
-					Ref:  refString,
-				})
-			} else {
-				plugins = append(plugins, Plugin{
-					Name: file.Name(),
-					Dir:  filepath.Join(pluginsDir, file.Name()),
-				})
+				plugin.URL = url
 			}
 		}
+
+		plugins = append(plugins, plugin)
 	}
 
 	return plugins, nil
@@ -329,7 +486,7 @@ func List(config config.Config, urls, refs bool) (plugins []Plugin, err error) {
 // Add takes plugin name and Git URL and installs the plugin if it isn't
 // already installed
 func Add(config config.Config, pluginName, pluginURL, ref string) error {
-	err := validatePluginName(pluginName)
+	err := ValidatePluginName(pluginName)
 	if err != nil {
 		return err
 	}
@@ -361,7 +518,7 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 			lastCheckDuration = checkDuration.Every
 		}
 
-		index := pluginindex.Build(config.DataDir, config.PluginIndexURL, false, lastCheckDuration)
+		index := pluginindex.Build(config.CacheDirectory(), config.PluginIndexURL, false, lastCheckDuration, GitOptions(config))
 		var err error
 		pluginURL, err = index.GetPluginSourceURL(pluginName)
 		if err != nil {
@@ -371,16 +528,123 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 
 	plugin.URL = pluginURL
 
+	policy, err := LoadPolicy(config)
+	if err != nil {
+		return err
+	}
+
+	// The URL is checked after rewrites, as that is the URL actually cloned
+	gitOptions := GitOptions(config)
+	err = policy.CheckURL(plugin.Name, gitOptions.RewriteURL(plugin.URL))
+	if err != nil {
+		return err
+	}
+
+	err = policy.CheckPin(plugin.Name)
+	if err != nil {
+		return err
+	}
+
+	if _, pinned := policy.Pin(plugin.Name); pinned {
+		// A shallow clone may not include the pinned commit
+		gitOptions.Depth = 0
+	}
+
+	// Run pre hooks
+	hook.Run(config, "pre_asdf_plugin_add", []string{plugin.Name})
+	hook.Run(config, fmt.Sprintf("pre_asdf_plugin_add_%s", plugin.Name), []string{})
+
+	repo := git.NewRepoWithOptions(plugin.Dir, gitOptions)
+	err = repo.Clone(plugin.URL, ref)
+	if err != nil {
+		return err
+	}
+
+	if pin, pinned := policy.Pin(plugin.Name); pinned {
+		err = repo.Reset(pin)
+		if err != nil {
+			os.RemoveAll(plugin.Dir)
+			return PolicyViolationError{plugin: plugin.Name, reason: fmt.Sprintf("unable to check out pinned commit: %s", err)}
+		}
+	}
+
+	return finishAdd(config, plugin)
+}
+
+// AddLink adds a plugin by linking the plugins directory entry to an existing
+// working copy at path instead of cloning a Git repository. Changes made in the
+// working copy take effect immediately, which is useful when developing a
+// plugin.
+func AddLink(config config.Config, pluginName, path string) error {
+	err := ValidatePluginName(pluginName)
+	if err != nil {
+		return err
+	}
+
+	exists, err := PluginExists(config.DataDir, pluginName)
+	if err != nil {
+		return fmt.Errorf("unable to check if plugin already exists: %w", err)
+	}
+
+	if exists {
+		return NewPluginAlreadyExists(pluginName)
+	}
+
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	isDir, err := directoryExists(target)
+	if err != nil {
+		return err
+	}
+
+	if !isDir {
+		return fmt.Errorf("unable to link plugin %s: %s is not a directory", pluginName, target)
+	}
+
+	plugin := New(config, pluginName)
+	plugin.URL = target
+
+	policy, err := LoadPolicy(config)
+	if err != nil {
+		return err
+	}
+
+	err = policy.CheckLink(plugin.Name)
+	if err != nil {
+		return err
+	}
+
 	// Run pre hooks
 	hook.Run(config, "pre_asdf_plugin_add", []string{plugin.Name})
 	hook.Run(config, fmt.Sprintf("pre_asdf_plugin_add_%s", plugin.Name), []string{})
 
-	err = git.NewRepo(plugin.Dir).Clone(plugin.URL, ref)
+	err = os.MkdirAll(data.PluginsDirectory(config.DataDir), 0o777)
 	if err != nil {
 		return err
 	}
 
-	err = os.MkdirAll(data.DownloadDirectory(config.DataDir, plugin.Name), 0o777)
+	err = os.Symlink(target, plugin.Dir)
+	if err != nil {
+		return err
+	}
+
+	return finishAdd(config, plugin)
+}
+
+// finishAdd runs the steps shared by cloned and linked plugins once the plugin
+// directory is in place
+func finishAdd(config config.Config, plugin Plugin) error {
+	// A plugin previously added under the same name may have left output in
+	// the cache
+	err := plugin.ClearCache()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(data.DownloadDirectory(config.CacheDirectory(), plugin.Name), 0o777)
 	if err != nil {
 		return err
 	}
@@ -397,7 +661,7 @@ func Add(config config.Config, pluginName, pluginURL, ref string) error {
 
 // Remove uninstalls a plugin by removing it from the file system if installed
 func Remove(config config.Config, pluginName string, stdout, stderr io.Writer) error {
-	err := validatePluginName(pluginName)
+	err := ValidatePluginName(pluginName)
 	if err != nil {
 		return err
 	}
@@ -423,12 +687,15 @@ func Remove(config config.Config, pluginName string, stdout, stderr io.Writer) e
 	plugin.RunCallback("pre-plugin-remove", []string{}, env, stdout, stderr)
 
 	pluginDir := data.PluginDirectory(config.DataDir, pluginName)
-	downloadDir := data.DownloadDirectory(config.DataDir, pluginName)
+	downloadDir := data.DownloadDirectory(config.CacheDirectory(), pluginName)
 	installDir := data.InstallDirectory(config.DataDir, pluginName)
 
 	err = os.RemoveAll(downloadDir)
+	// For linked plugins this only removes the link, the working copy is left
+	// untouched
 	err2 := os.RemoveAll(pluginDir)
 	err3 := os.RemoveAll(installDir)
+	plugin.ClearCache()
 
 	if err != nil {
 		return err
@@ -444,6 +711,32 @@ func Remove(config config.Config, pluginName string, stdout, stderr io.Writer) e
 	return err3
 }
 
+// GitOptions builds the options used for plugin and plugin index Git
+// operations from the clone depth, credential and URL rewrite settings in the
+// asdfrc file.
+func GitOptions(conf config.Config) git.Options {
+	// Ignore errors here as the default values are fine
+	depth, _ := conf.GitCloneDepth()
+	tokenEnv, tokenHosts, _ := conf.GitTokenEnv()
+	netrcFile, _ := conf.GitNetrcFile()
+	urlRewrites, _ := conf.URLRewrites()
+
+	options := git.Options{
+		Depth:      depth,
+		TokenEnv:   tokenEnv,
+		TokenHosts: tokenHosts,
+		NetrcFile:  netrcFile,
+	}
+
+	for _, rewrite := range urlRewrites {
+		for _, insteadOf := range rewrite.InsteadOf {
+			options.Rewrites = append(options.Rewrites, git.Rewrite{Base: rewrite.Base, InsteadOf: insteadOf})
+		}
+	}
+
+	return options
+}
+
 // PluginExists returns a boolean indicating whether or not a plugin with the
 // provided name is currently installed
 func PluginExists(dataDir, pluginName string) (bool, error) {
@@ -464,7 +757,8 @@ func directoryExists(dir string) (bool, error) {
 	return fileInfo.IsDir(), nil
 }
 
-func validatePluginName(name string) error {
+// ValidatePluginName returns an error if the name is not a valid plugin name
+func ValidatePluginName(name string) error {
 	match, err := regexp.MatchString("^[[:lower:][:digit:]_-]+$", name)
 	if err != nil {
 		return err
@@ -35,6 +35,18 @@ func TestList(t *testing.T) {
 		assert.Zero(t, plugin.Ref)
 	})
 
+	t.Run("returns plugins with the data dir and options set like New", func(t *testing.T) {
+		conf := policyConfigWithDataDir(t, testDataDir, "[plugin \"lua\"]\nmirror = https://example.com\n")
+
+		plugins, err := List(conf, false, false)
+		assert.Nil(t, err)
+		assert.Equal(t, []Plugin{New(conf, testPluginName)}, plugins)
+
+		env := plugins[0].CallbackEnv(map[string]string{})
+		assert.Equal(t, "https://example.com", env["ASDF_PLUGIN_OPT_MIRROR"])
+		assert.Equal(t, testDataDir, env["ASDF_DATA_DIR"])
+	})
+
 	t.Run("when urls is set to true returns plugins with repo urls set", func(t *testing.T) {
 		plugins, err := List(conf, true, false)
 		assert.Nil(t, err)
@@ -170,6 +182,73 @@ func TestAdd(t *testing.T) {
 	})
 }
 
+func TestAddLink(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	// A working copy that is not a Git repository
+	workingCopy := filepath.Join(t.TempDir(), "asdf-lua")
+	err := os.CopyFS(workingCopy, os.DirFS(filepath.Join("..", "..", "test", "fixtures", "dummy_plugin")))
+	assert.Nil(t, err)
+
+	err = AddLink(conf, testPluginName, workingCopy)
+	assert.Nil(t, err)
+	plugin := New(conf, testPluginName)
+
+	t.Run("links plugin directory to working copy", func(t *testing.T) {
+		assert.Nil(t, plugin.Exists())
+
+		target, linked := plugin.Linked()
+		assert.True(t, linked)
+		assert.Equal(t, workingCopy, target)
+
+		_, err := os.Stat(data.DownloadDirectory(testDataDir, testPluginName))
+		assert.Nil(t, err)
+	})
+
+	t.Run("returns error when plugin with same name already exists", func(t *testing.T) {
+		err := AddLink(conf, testPluginName, workingCopy)
+		assert.ErrorContains(t, err, "Plugin named lua already added")
+	})
+
+	t.Run("returns error when path is not a directory", func(t *testing.T) {
+		err := AddLink(conf, "missing", filepath.Join(workingCopy, "nonexistent"))
+		assert.ErrorContains(t, err, "is not a directory")
+	})
+
+	t.Run("lists linked plugin with link target as URL", func(t *testing.T) {
+		plugins, err := List(conf, true, true)
+		assert.Nil(t, err)
+
+		assert.Len(t, plugins, 1)
+		assert.Equal(t, testPluginName, plugins[0].Name)
+		assert.Equal(t, workingCopy, plugins[0].URL)
+		assert.Zero(t, plugins[0].Ref)
+	})
+
+	t.Run("skips update of linked plugin", func(t *testing.T) {
+		var stdout strings.Builder
+		ref, err := plugin.Update(conf, "", &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Zero(t, ref)
+		assert.Contains(t, stdout.String(), "Plugin named lua is linked to")
+
+		results := UpdateAll(conf, []Plugin{plugin}, 1, &stdout, &stdout)
+		assert.True(t, results[0].Linked)
+		assert.Nil(t, results[0].Err)
+	})
+
+	t.Run("remove leaves working copy in place", func(t *testing.T) {
+		var blackhole strings.Builder
+		err := Remove(conf, testPluginName, &blackhole, &blackhole)
+		assert.Nil(t, err)
+
+		assert.NotNil(t, plugin.Exists())
+		_, err = os.Stat(filepath.Join(workingCopy, "bin", "list-all"))
+		assert.Nil(t, err)
+	})
+}
+
 func TestRemove(t *testing.T) {
 	testDataDir := t.TempDir()
 	conf := config.Config{DataDir: testDataDir}
@@ -296,6 +375,49 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateAll(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	err = Add(conf, testPluginName, repoPath, "")
+	assert.Nil(t, err)
+
+	badRepo := data.PluginDirectory(testDataDir, "badplugin")
+	err = os.MkdirAll(badRepo, 0o777)
+	assert.Nil(t, err)
+
+	t.Run("returns a result for every plugin in order", func(t *testing.T) {
+		var stdout strings.Builder
+		var stderr strings.Builder
+		plugins := []Plugin{New(conf, "badplugin"), New(conf, testPluginName)}
+
+		results := UpdateAll(conf, plugins, 2, &stdout, &stderr)
+
+		assert.Len(t, results, 2)
+		assert.Equal(t, "badplugin", results[0].Name)
+		assert.ErrorContains(t, results[0].Err, "unable to open plugin Git repository")
+
+		assert.Equal(t, testPluginName, results[1].Name)
+		assert.Nil(t, results[1].Err)
+		assert.NotZero(t, results[1].Ref)
+		assert.NotZero(t, results[1].OldSHA)
+		assert.Equal(t, results[1].OldSHA, results[1].NewSHA)
+	})
+
+	t.Run("writes buffered callback output", func(t *testing.T) {
+		var stdout strings.Builder
+		var stderr strings.Builder
+		plugins := []Plugin{New(conf, testPluginName)}
+
+		UpdateAll(conf, plugins, 0, &stdout, &stderr)
+
+		assert.Contains(t, stdout.String(), "plugin updated")
+	})
+}
+
 func TestExists(t *testing.T) {
 	testDataDir := t.TempDir()
 	conf := config.Config{DataDir: testDataDir}
@@ -367,7 +489,7 @@ func TestPluginExists(t *testing.T) {
 
 func TestValidatePluginName(t *testing.T) {
 	t.Run("returns no error when plugin name is valid", func(t *testing.T) {
-		err := validatePluginName(testPluginName)
+		err := ValidatePluginName(testPluginName)
 		assert.Nil(t, err)
 	})
 
@@ -375,7 +497,7 @@ func TestValidatePluginName(t *testing.T) {
 
 	for _, invalid := range invalids {
 		t.Run(invalid, func(t *testing.T) {
-			err := validatePluginName(invalid)
+			err := ValidatePluginName(invalid)
 
 			if err == nil {
 				t.Error("Expected an error")
@@ -429,9 +551,25 @@ func TestRunCallback(t *testing.T) {
 
 		err = plugin.RunCallback("post-plugin-update", []string{}, map[string]string{"ASDF_PLUGIN_PREV_REF": "TEST"}, &stdout, &stderr)
 		assert.Nil(t, err)
-		assert.Equal(t, "plugin updated path= old git-ref=TEST new git-ref=\n", stdout.String())
+		assert.Equal(t, fmt.Sprintf("plugin updated path=%s old git-ref=TEST new git-ref=\n", plugin.Dir), stdout.String())
 		assert.Equal(t, "", stderr.String())
 	})
+
+	t.Run("passes plugin variables and options to command", func(t *testing.T) {
+		var stdout strings.Builder
+		var stderr strings.Builder
+
+		conf := config.Config{DataDir: testDataDir, ConfigFile: filepath.Join(t.TempDir(), "asdfrc")}
+		assert.Nil(t, conf.SetPluginOption(testPluginName, "compile-flags", "-O2"))
+		plugin := New(conf, testPluginName)
+
+		script := "#!/usr/bin/env bash\necho $ASDF_PLUGIN_NAME $ASDF_PLUGIN_PATH $ASDF_DATA_DIR $ASDF_PLUGIN_OPT_COMPILE_FLAGS"
+		assert.Nil(t, repotest.WritePluginCallback(plugin.Dir, "list-all", script))
+
+		err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("%s %s %s -O2\n", testPluginName, plugin.Dir, testDataDir), stdout.String())
+	})
 }
 
 func TestCallbackPath(t *testing.T) {
@@ -553,7 +691,7 @@ func TestLegacyFilenames(t *testing.T) {
 	})
 }
 
-func TestParseLegacyVersionFile(t *testing.T) {
+func TestParseLegacyFile(t *testing.T) {
 	testDataDir := t.TempDir()
 	conf := config.Config{DataDir: testDataDir}
 	_, err := repotest.InstallPlugin("dummy_plugin", testDataDir, testPluginName)
@@ -572,19 +710,19 @@ func TestParseLegacyVersionFile(t *testing.T) {
 		assert.Nil(t, err)
 		plugin := New(conf, testPluginName)
 
-		versions, err := plugin.ParseLegacyVersionFile(path)
+		versions, err := plugin.ParseLegacyFile(path)
 		assert.Nil(t, err)
 		assert.Equal(t, versions, []string{"dummy-1.2.3"})
 	})
 
 	t.Run("returns file contents parsed by parse-legacy-file callback when it is present", func(t *testing.T) {
-		versions, err := plugin.ParseLegacyVersionFile(path)
+		versions, err := plugin.ParseLegacyFile(path)
 		assert.Nil(t, err)
 		assert.Equal(t, versions, []string{"1.2.3"})
 	})
 
 	t.Run("returns error when passed file that doesn't exist", func(t *testing.T) {
-		versions, err := plugin.ParseLegacyVersionFile("non-existent-file")
+		versions, err := plugin.ParseLegacyFile("non-existent-file")
 		assert.Error(t, err)
 		assert.Empty(t, versions)
 	})
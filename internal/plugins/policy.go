@@ -0,0 +1,228 @@
+package plugins
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+)
+
+const policyViolationMsg = "Plugin named %s violates the plugin policy: %s"
+
+// PolicyViolationError is returned when a plugin's source or commit is not
+// permitted by the plugin policy
+type PolicyViolationError struct {
+	plugin string
+	reason string
+}
+
+func (e PolicyViolationError) Error() string {
+	return fmt.Sprintf(policyViolationMsg, e.plugin, e.reason)
+}
+
+// minPinLength is the minimum number of characters of a pinned commit SHA, the
+// length Git abbreviates commits to by default
+const minPinLength = 7
+
+// policySource records where a plugin's policy is loaded from. It is stored on
+// the Plugin struct so callbacks can be checked without access to the config.
+type policySource struct {
+	systemFile       string
+	configFile       string
+	systemConfigFile string
+}
+
+func newPolicySource(conf config.Config) policySource {
+	return policySource{systemFile: conf.PluginPolicyFile, configFile: conf.ConfigFile, systemConfigFile: conf.SystemConfigFile}
+}
+
+// loadedPolicy is a policy loaded by policySource.load, along with the Git
+// options used to rewrite plugin URLs before they are checked
+type loadedPolicy struct {
+	policy     Policy
+	gitOptions git.Options
+	err        error
+}
+
+// loadedPolicies holds the policy loaded for each policySource, so the policy
+// and asdfrc files are parsed once per process rather than before every
+// callback
+var loadedPolicies sync.Map
+
+// load returns the policy loaded from the source's files. The files are only
+// read the first time.
+func (s policySource) load() loadedPolicy {
+	if loaded, ok := loadedPolicies.Load(s); ok {
+		return loaded.(loadedPolicy)
+	}
+
+	conf := config.Config{PluginPolicyFile: s.systemFile, ConfigFile: s.configFile, SystemConfigFile: s.systemConfigFile}
+	policy, err := LoadPolicy(conf)
+	loaded := loadedPolicy{policy: policy, gitOptions: GitOptions(conf), err: err}
+	loadedPolicies.Store(s, loaded)
+
+	return loaded
+}
+
+// Policy is the effective plugin policy. A plugin must satisfy both the
+// system-wide policy and the user's asdfrc policy. Pins in the system-wide
+// policy take precedence over pins in the asdfrc file.
+type Policy struct {
+	system config.PluginPolicy
+	user   config.PluginPolicy
+}
+
+// LoadPolicy loads the system-wide and asdfrc plugin policies for the config
+func LoadPolicy(conf config.Config) (Policy, error) {
+	system, err := config.LoadPluginPolicyFile(conf.PluginPolicyFile)
+	if err != nil {
+		return Policy{}, fmt.Errorf("unable to load plugin policy file %s: %w", conf.PluginPolicyFile, err)
+	}
+
+	user, err := conf.PluginPolicy()
+	if err != nil {
+		return Policy{}, fmt.Errorf("unable to load plugin policy from %s: %w", conf.ConfigFile, err)
+	}
+
+	return Policy{system: system, user: user}, nil
+}
+
+// Enabled returns true if either policy restricts plugin URLs or pins commits
+func (p Policy) Enabled() bool {
+	return len(p.system.AllowedURLs) > 0 || len(p.user.AllowedURLs) > 0 ||
+		len(p.system.Pins) > 0 || len(p.user.Pins) > 0
+}
+
+// RestrictsURLs returns true if either policy defines allowed URL patterns
+func (p Policy) RestrictsURLs() bool {
+	return len(p.system.AllowedURLs) > 0 || len(p.user.AllowedURLs) > 0
+}
+
+// CheckURL returns a PolicyViolationError if the URL does not match the allowed
+// URL patterns of every policy that defines some
+func (p Policy) CheckURL(pluginName, url string) error {
+	for _, allowed := range [][]string{p.system.AllowedURLs, p.user.AllowedURLs} {
+		if len(allowed) > 0 && !matchesAny(allowed, url) {
+			return PolicyViolationError{plugin: pluginName, reason: fmt.Sprintf("source %s is not an allowed plugin URL", url)}
+		}
+	}
+
+	return nil
+}
+
+// CheckLink returns a PolicyViolationError if the plugin may not be linked. A
+// linked working copy has no source URL or commit to check, so linking is
+// refused while the policy restricts URLs or pins the plugin.
+func (p Policy) CheckLink(pluginName string) error {
+	if p.RestrictsURLs() {
+		return PolicyViolationError{plugin: pluginName, reason: "linked plugins are not allowed while plugin URLs are restricted"}
+	}
+
+	if _, pinned := p.Pin(pluginName); pinned {
+		return PolicyViolationError{plugin: pluginName, reason: "linked plugins can't be pinned to a commit"}
+	}
+
+	return nil
+}
+
+// Pin returns the commit the plugin is pinned to, if any
+func (p Policy) Pin(pluginName string) (string, bool) {
+	if pin, ok := p.system.Pins[pluginName]; ok && pin != "" {
+		return pin, true
+	}
+
+	pin, ok := p.user.Pins[pluginName]
+	return pin, ok && pin != ""
+}
+
+// CheckPin returns a PolicyViolationError if the plugin is pinned to something
+// other than a commit SHA abbreviated to at least minPinLength characters, as
+// shorter pins match too many commits to identify one
+func (p Policy) CheckPin(pluginName string) error {
+	pin, ok := p.Pin(pluginName)
+	if !ok {
+		return nil
+	}
+
+	if len(pin) < minPinLength || strings.Trim(strings.ToLower(pin), "0123456789abcdef") != "" {
+		return PolicyViolationError{plugin: pluginName, reason: fmt.Sprintf("pinned commit %s must be a commit SHA of at least %d hexadecimal characters", pin, minPinLength)}
+	}
+
+	return nil
+}
+
+// CheckCommit returns a PolicyViolationError if the plugin is pinned and the
+// commit does not match the pin. Pins may be abbreviated commit SHAs.
+func (p Policy) CheckCommit(pluginName, sha string) error {
+	pin, ok := p.Pin(pluginName)
+	if !ok {
+		return nil
+	}
+
+	if err := p.CheckPin(pluginName); err != nil {
+		return err
+	}
+
+	if sha == "" || !strings.HasPrefix(sha, strings.ToLower(pin)) {
+		return PolicyViolationError{plugin: pluginName, reason: fmt.Sprintf("commit %s does not match pinned commit %s", sha, pin)}
+	}
+
+	return nil
+}
+
+// Verify checks the plugin's source URL and current commit against the plugin
+// policy. It is run before every callback.
+func (p Plugin) Verify() error {
+	loaded := p.policy.load()
+	policy, err := loaded.policy, loaded.err
+	if err != nil {
+		return err
+	}
+
+	if !policy.Enabled() {
+		return nil
+	}
+
+	if _, linked := p.Linked(); linked {
+		return policy.CheckLink(p.Name)
+	}
+
+	repo := git.NewRepo(p.Dir)
+
+	if policy.RestrictsURLs() {
+		url, err := repo.RemoteURL()
+		if err != nil {
+			return PolicyViolationError{plugin: p.Name, reason: fmt.Sprintf("unable to determine plugin source: %s", err)}
+		}
+
+		// Updates fetch the rewritten URL, so that is the one checked
+		url = loaded.gitOptions.RewriteURL(url)
+		if err := policy.CheckURL(p.Name, url); err != nil {
+			return err
+		}
+	}
+
+	if _, pinned := policy.Pin(p.Name); pinned {
+		head, err := repo.Head()
+		if err != nil {
+			return PolicyViolationError{plugin: p.Name, reason: fmt.Sprintf("unable to determine plugin commit: %s", err)}
+		}
+
+		return policy.CheckCommit(p.Name, head)
+	}
+
+	return nil
+}
+
+func matchesAny(patterns []string, url string) bool {
+	for _, pattern := range patterns {
+		if match, err := path.Match(pattern, url); err == nil && match {
+			return true
+		}
+	}
+
+	return false
+}
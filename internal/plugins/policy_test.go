@@ -0,0 +1,223 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyCheckURL(t *testing.T) {
+	policy := Policy{
+		system: config.PluginPolicy{AllowedURLs: []string{"https://github.com/*/*"}},
+		user:   config.PluginPolicy{AllowedURLs: []string{"https://github.com/asdf-vm/*"}},
+	}
+
+	t.Run("returns nil when URL matches every policy", func(t *testing.T) {
+		assert.Nil(t, policy.CheckURL("lua", "https://github.com/asdf-vm/asdf-lua.git"))
+	})
+
+	t.Run("returns error when URL matches only the system policy", func(t *testing.T) {
+		err := policy.CheckURL("lua", "https://github.com/someone/asdf-lua.git")
+		assert.ErrorContains(t, err, "Plugin named lua violates the plugin policy: source https://github.com/someone/asdf-lua.git is not an allowed plugin URL")
+	})
+
+	t.Run("returns nil when no policy is set", func(t *testing.T) {
+		assert.Nil(t, Policy{}.CheckURL("lua", "https://example.com/anything"))
+	})
+}
+
+func TestPolicyCheckCommit(t *testing.T) {
+	policy := Policy{
+		system: config.PluginPolicy{Pins: map[string]string{"lua": "abc1234"}},
+		user:   config.PluginPolicy{Pins: map[string]string{"lua": "def4567", "ruby": "789abcd", "nodejs": "a", "python": "ABCDEF0123"}},
+	}
+
+	t.Run("system pins take precedence", func(t *testing.T) {
+		assert.Nil(t, policy.CheckCommit("lua", "abc1234fff"))
+		assert.NotNil(t, policy.CheckCommit("lua", "def4567fff"))
+	})
+
+	t.Run("returns error when commit does not match pin", func(t *testing.T) {
+		err := policy.CheckCommit("ruby", "0000000")
+		assert.ErrorContains(t, err, "commit 0000000 does not match pinned commit 789abcd")
+	})
+
+	t.Run("returns error when pin is too short to identify a commit", func(t *testing.T) {
+		err := policy.CheckCommit("nodejs", "abc1234fff")
+		assert.ErrorContains(t, err, "pinned commit a must be a commit SHA of at least 7 hexadecimal characters")
+	})
+
+	t.Run("matches pins regardless of case", func(t *testing.T) {
+		assert.Nil(t, policy.CheckCommit("python", "abcdef0123ff"))
+	})
+
+	t.Run("returns nil for plugins without a pin", func(t *testing.T) {
+		assert.Nil(t, policy.CheckCommit("erlang", "000000"))
+	})
+}
+
+func TestPolicyEnforcement(t *testing.T) {
+	testDataDir := t.TempDir()
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", testDataDir, testPluginName)
+	assert.Nil(t, err)
+
+	head, err := git.NewRepo(repoPath).Head()
+	assert.Nil(t, err)
+
+	t.Run("Add refuses URLs not on the allow list", func(t *testing.T) {
+		conf := policyConfig(t, "plugin_allowed_urls = https://github.com/asdf-vm/*\n")
+
+		err := Add(conf, testPluginName, repoPath, "")
+		assert.IsType(t, PolicyViolationError{}, err)
+
+		exists, err := PluginExists(conf.DataDir, testPluginName)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Add checks URL after rewrites", func(t *testing.T) {
+		conf := policyConfig(t, fmt.Sprintf("plugin_allowed_urls = https://github.com/asdf-vm/*\n[url \"%s\"]\ninsteadOf = https://github.com/asdf-vm/asdf-dummy\n", repoPath))
+
+		err := Add(conf, testPluginName, "https://github.com/asdf-vm/asdf-dummy", "")
+		assert.IsType(t, PolicyViolationError{}, err)
+		assert.ErrorContains(t, err, repoPath)
+	})
+
+	t.Run("Update fails when plugin source can't be determined", func(t *testing.T) {
+		conf := policyConfig(t, "")
+		err := Add(conf, testPluginName, repoPath, "")
+		assert.Nil(t, err)
+
+		plugin := New(conf, testPluginName)
+		err = exec.Command("git", "-C", plugin.Dir, "remote", "remove", "origin").Run()
+		assert.Nil(t, err)
+
+		conf = policyConfigWithDataDir(t, conf.DataDir, fmt.Sprintf("plugin_allowed_urls = %s\n", repoPath))
+		var blackhole strings.Builder
+		_, err = New(conf, testPluginName).Update(conf, "", &blackhole, &blackhole)
+		assert.IsType(t, PolicyViolationError{}, err)
+		assert.ErrorContains(t, err, "unable to determine plugin source")
+	})
+
+	t.Run("AddLink refuses to link plugins while URLs are restricted", func(t *testing.T) {
+		conf := policyConfig(t, fmt.Sprintf("plugin_allowed_urls = %s\n", repoPath))
+
+		err := AddLink(conf, testPluginName, repoPath)
+		assert.IsType(t, PolicyViolationError{}, err)
+
+		exists, err := PluginExists(conf.DataDir, testPluginName)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Verify refuses linked plugins once URLs are restricted", func(t *testing.T) {
+		conf := policyConfig(t, "")
+		err := AddLink(conf, testPluginName, repoPath)
+		assert.Nil(t, err)
+
+		conf = policyConfigWithDataDir(t, conf.DataDir, fmt.Sprintf("plugin_allowed_urls = %s\n", repoPath))
+		err = New(conf, testPluginName).Verify()
+		assert.IsType(t, PolicyViolationError{}, err)
+		assert.ErrorContains(t, err, "linked plugins are not allowed")
+	})
+
+	t.Run("Add checks out pinned commit", func(t *testing.T) {
+		previous, err := resolveCommit(repoPath, "HEAD~")
+		assert.Nil(t, err)
+		conf := policyConfig(t, fmt.Sprintf("[plugin_pins]\n%s = %s\n", testPluginName, previous))
+
+		err = Add(conf, testPluginName, repoPath, "")
+		assert.Nil(t, err)
+
+		pluginHead, err := git.NewRepo(New(conf, testPluginName).Dir).Head()
+		assert.Nil(t, err)
+		assert.Equal(t, previous, pluginHead)
+	})
+
+	t.Run("Add checks out pinned commit outside of the clone depth", func(t *testing.T) {
+		previous, err := resolveCommit(repoPath, "HEAD~")
+		assert.Nil(t, err)
+		conf := policyConfig(t, fmt.Sprintf("git_clone_depth = 1\n\n[plugin_pins]\n%s = %s\n", testPluginName, previous))
+
+		err = Add(conf, testPluginName, repoPath, "")
+		assert.Nil(t, err)
+
+		pluginHead, err := git.NewRepo(New(conf, testPluginName).Dir).Head()
+		assert.Nil(t, err)
+		assert.Equal(t, previous, pluginHead)
+	})
+
+	t.Run("Add refuses pins too short to identify a commit", func(t *testing.T) {
+		conf := policyConfig(t, fmt.Sprintf("[plugin_pins]\n%s = abc\n", testPluginName))
+
+		err := Add(conf, testPluginName, repoPath, "")
+		assert.IsType(t, PolicyViolationError{}, err)
+		assert.ErrorContains(t, err, "pinned commit abc must be a commit SHA of at least 7 hexadecimal characters")
+
+		exists, err := PluginExists(conf.DataDir, testPluginName)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("RunCallback refuses to run callbacks of plugins with wrong commit", func(t *testing.T) {
+		conf := policyConfig(t, "")
+		err := Add(conf, testPluginName, repoPath, "")
+		assert.Nil(t, err)
+
+		conf = policyConfigWithDataDir(t, conf.DataDir, fmt.Sprintf("[plugin_pins]\n%s = 0000000\n", testPluginName))
+		plugin := New(conf, testPluginName)
+
+		var stdout strings.Builder
+		err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.IsType(t, PolicyViolationError{}, err)
+		assert.Empty(t, stdout.String())
+	})
+
+	t.Run("Update rolls back when new commit does not match pin", func(t *testing.T) {
+		previous, err := resolveCommit(repoPath, "HEAD~")
+		assert.Nil(t, err)
+		conf := policyConfig(t, fmt.Sprintf("[plugin_pins]\n%s = %s\n", testPluginName, previous))
+		err = Add(conf, testPluginName, repoPath, "")
+		assert.Nil(t, err)
+
+		var blackhole strings.Builder
+		plugin := New(conf, testPluginName)
+		_, err = plugin.Update(conf, "", &blackhole, &blackhole)
+		assert.IsType(t, PolicyViolationError{}, err)
+		assert.ErrorContains(t, err, fmt.Sprintf("commit %s does not match", head))
+
+		pluginHead, err := git.NewRepo(plugin.Dir).Head()
+		assert.Nil(t, err)
+		assert.Equal(t, previous, pluginHead)
+	})
+}
+
+func policyConfig(t *testing.T, asdfrc string) config.Config {
+	t.Helper()
+	return policyConfigWithDataDir(t, t.TempDir(), asdfrc)
+}
+
+func policyConfigWithDataDir(t *testing.T, dataDir, asdfrc string) config.Config {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "asdfrc")
+	err := os.WriteFile(configFile, []byte(asdfrc), 0o666)
+	assert.Nil(t, err)
+
+	return config.Config{DataDir: dataDir, ConfigFile: configFile}
+}
+
+func resolveCommit(repoPath, revision string) (string, error) {
+	var stdout strings.Builder
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", revision)
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	return strings.TrimSpace(stdout.String()), err
+}
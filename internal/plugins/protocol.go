@@ -0,0 +1,240 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/execute"
+)
+
+// ProtocolV2 is the version of the JSON plugin protocol. Plugins using it
+// provide a single executable, bin/asdf-plugin, instead of a script for every
+// callback. asdf runs the executable once per request, writes a JSON
+// ProtocolRequest to its stdin and reads a JSON ProtocolResponse from its
+// stdout. Anything the plugin writes to stderr is shown to the user.
+const ProtocolV2 = 2
+
+// ProtocolExecutable is the name of the executable in bin/ that marks a plugin
+// as using the JSON protocol
+const ProtocolExecutable = "asdf-plugin"
+
+// Protocol error codes with a meaning to asdf. Plugins may return other codes,
+// which are reported to the user along with the message.
+const (
+	// ErrCodeUnsupportedMethod means the plugin doesn't implement the method.
+	// asdf treats it the same as a missing callback script.
+	ErrCodeUnsupportedMethod = "unsupported_method"
+)
+
+// protocolMethods maps callbacks to the protocol methods that implement them
+var protocolMethods = map[string]string{
+	"list-all":              "list-all",
+	"latest-stable":         "latest",
+	"download":              "download",
+	"install":               "install",
+	"list-bin-paths":        "bin-paths",
+	"exec-env":              "exec-env",
+	"list-legacy-filenames": "legacy-filenames",
+	"parse-legacy-file":     "parse-legacy-file",
+}
+
+// ProtocolRequest is sent to the plugin on stdin
+type ProtocolRequest struct {
+	Protocol int            `json:"protocol"`
+	Method   string         `json:"method"`
+	Params   ProtocolParams `json:"params"`
+}
+
+// ProtocolParams are the parameters of a request. Only the parameters relevant
+// to the method are set.
+type ProtocolParams struct {
+	// Version and VersionType identify the tool version for download, install,
+	// bin-paths and exec-env. VersionType is either "version" or "ref".
+	Version     string `json:"version,omitempty"`
+	VersionType string `json:"version_type,omitempty"`
+	// Query is the version prefix to filter by for latest
+	Query string `json:"query,omitempty"`
+	// DownloadPath and InstallPath are the directories the tool version is
+	// downloaded and installed to
+	DownloadPath string `json:"download_path,omitempty"`
+	InstallPath  string `json:"install_path,omitempty"`
+	// Concurrency is the number of jobs the plugin may use to build the tool
+	Concurrency string `json:"concurrency,omitempty"`
+	// Path is the legacy version file to parse for parse-legacy-file
+	Path string `json:"path,omitempty"`
+}
+
+// ProtocolResponse is read from the plugin's stdout. Exactly one of Result and
+// Error is expected to be set. Metadata is free form information about the
+// result, such as release dates or download URLs. asdf returns it to callers
+// of Call but doesn't act on it.
+type ProtocolResponse struct {
+	Protocol int            `json:"protocol"`
+	Result   ProtocolResult `json:"result"`
+	Error    *ProtocolError `json:"error,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// ProtocolResult holds the result of any method. Only the fields relevant to
+// the method are set: Versions for list-all and parse-legacy-file, Version for
+// latest, Paths for bin-paths, Env for exec-env and Filenames for
+// legacy-filenames. download and install have no result.
+type ProtocolResult struct {
+	Versions  []string          `json:"versions,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Paths     []string          `json:"paths,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Filenames []string          `json:"filenames,omitempty"`
+}
+
+// ProtocolError is a structured error returned by a plugin
+type ProtocolError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+	plugin  string
+	method  string
+}
+
+func (e ProtocolError) Error() string {
+	return fmt.Sprintf("Plugin named %s failed to %s: %s (%s)", e.plugin, e.method, e.Message, e.Code)
+}
+
+// ProtocolVersion returns ProtocolV2 if the plugin uses the JSON protocol and
+// 1 if it uses a script for each callback
+func (p Plugin) ProtocolVersion() int {
+	fileInfo, err := os.Stat(p.protocolExecutablePath())
+	if err == nil && !fileInfo.IsDir() && fileInfo.Mode()&0o111 != 0 {
+		return ProtocolV2
+	}
+
+	return 1
+}
+
+// ProtocolCallback returns true if the callback is implemented by the plugin's
+// JSON protocol executable
+func (p Plugin) ProtocolCallback(name string) bool {
+	_, ok := protocolMethods[name]
+	return ok && p.ProtocolVersion() == ProtocolV2
+}
+
+// Call sends a request for the method to the plugin's protocol executable and
+// returns the decoded response. env is the environment the executable is run
+// with. A ProtocolError is returned if the plugin reports an error, or
+// NoCallbackError if the plugin doesn't support the method.
+func (p Plugin) Call(method string, params ProtocolParams, env map[string]string, stdErr io.Writer) (ProtocolResponse, error) {
+	err := p.Verify()
+	if err != nil {
+		return ProtocolResponse{}, err
+	}
+
+	request, err := json.Marshal(ProtocolRequest{Protocol: ProtocolV2, Method: method, Params: params})
+	if err != nil {
+		return ProtocolResponse{}, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := execute.New(fmt.Sprintf("'%s'", p.protocolExecutablePath()), []string{})
+	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stdErr
+	runErr := cmd.Run()
+
+	var response ProtocolResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		if runErr != nil {
+			return response, fmt.Errorf("Plugin named %s failed to %s: %w", p.Name, method, runErr)
+		}
+
+		return response, fmt.Errorf("Plugin named %s returned an invalid response to %s: %w", p.Name, method, err)
+	}
+
+	if response.Protocol != ProtocolV2 {
+		return response, fmt.Errorf("Plugin named %s responded with unsupported protocol version %d", p.Name, response.Protocol)
+	}
+
+	if response.Error != nil {
+		if response.Error.Code == ErrCodeUnsupportedMethod {
+			return response, NoCallbackError{callback: method, plugin: p.Name}
+		}
+
+		response.Error.plugin, response.Error.method = p.Name, method
+		return response, *response.Error
+	}
+
+	if runErr != nil {
+		return response, fmt.Errorf("Plugin named %s failed to %s: %w", p.Name, method, runErr)
+	}
+
+	return response, nil
+}
+
+// protocolCallback runs a callback through the JSON protocol and returns the
+// response. handled is false if the plugin doesn't use the protocol or doesn't
+// implement the method, in which case the callback script should be run
+// instead.
+func (p Plugin) protocolCallback(name string, arguments []string, env map[string]string, stdErr io.Writer) (response ProtocolResponse, handled bool, err error) {
+	if !p.ProtocolCallback(name) {
+		return response, false, nil
+	}
+
+	response, err = p.Call(protocolMethods[name], protocolParams(name, arguments, env), p.CallbackEnv(env), stdErr)
+	if _, ok := err.(NoCallbackError); ok {
+		return response, false, nil
+	}
+
+	return response, true, err
+}
+
+// writeProtocolResult writes the result of a callback to stdOut in the same
+// format as the v1 callback script would, for callers that run callbacks by
+// name rather than through the Backend methods
+func writeProtocolResult(name string, result ProtocolResult, stdOut io.Writer) (err error) {
+	switch name {
+	case "list-all", "parse-legacy-file":
+		_, err = fmt.Fprintln(stdOut, strings.Join(result.Versions, " "))
+	case "latest-stable":
+		_, err = fmt.Fprintln(stdOut, result.Version)
+	case "list-bin-paths":
+		_, err = fmt.Fprintln(stdOut, strings.Join(result.Paths, " "))
+	case "list-legacy-filenames":
+		_, err = fmt.Fprintln(stdOut, strings.Join(result.Filenames, " "))
+	case "exec-env":
+		for key, value := range result.Env {
+			fmt.Fprintf(stdOut, "%s=%s\n", key, value)
+		}
+	}
+
+	return err
+}
+
+func protocolParams(callback string, arguments []string, env map[string]string) ProtocolParams {
+	params := ProtocolParams{
+		Version:      env["ASDF_INSTALL_VERSION"],
+		VersionType:  env["ASDF_INSTALL_TYPE"],
+		DownloadPath: env["ASDF_DOWNLOAD_PATH"],
+		InstallPath:  env["ASDF_INSTALL_PATH"],
+		Concurrency:  env["ASDF_CONCURRENCY"],
+	}
+
+	if len(arguments) > 0 {
+		switch callback {
+		case "latest-stable":
+			params.Query = arguments[0]
+		case "parse-legacy-file":
+			params.Path = arguments[0]
+		}
+	}
+
+	return params
+}
+
+func (p Plugin) protocolExecutablePath() string {
+	return filepath.Join(p.Dir, "bin", ProtocolExecutable)
+}
@@ -0,0 +1,201 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+// protocolScript is a minimal plugin implementing the JSON protocol. It picks
+// the response by matching the method in the request.
+const protocolScript = `#!/usr/bin/env bash
+request="$(cat)"
+method="$(echo "$request" | sed -E 's/.*"method":"([^"]*)".*/\1/')"
+
+case "$method" in
+list-all)
+  echo '{"protocol":2,"result":{"versions":["1.0.0","1.1.0","2.0.0"]}}'
+  ;;
+latest)
+  echo '{"protocol":2,"result":{"version":"2.0.0"}}'
+  ;;
+download)
+  mkdir -p "$ASDF_DOWNLOAD_PATH"
+  echo '{"protocol":2,"result":{}}'
+  ;;
+install)
+  mkdir -p "$ASDF_INSTALL_PATH/bin"
+  echo "$request" >"$ASDF_INSTALL_PATH/request.json"
+  echo '{"protocol":2,"result":{}}'
+  ;;
+bin-paths)
+  echo '{"protocol":2,"result":{"paths":["bin","libexec"]}}'
+  ;;
+exec-env)
+  echo '{"protocol":2,"result":{"env":{"TOOL_HOME":"'"$ASDF_INSTALL_PATH"'"}}}'
+  ;;
+legacy-filenames)
+  echo '{"protocol":2,"result":{"filenames":[".tool-version"]}}'
+  ;;
+parse-legacy-file)
+  echo "parsing" >&2
+  echo '{"protocol":2,"error":{"code":"invalid_file","message":"unable to parse file"}}'
+  ;;
+*)
+  echo '{"protocol":2,"error":{"code":"unsupported_method","message":"not supported"}}'
+  ;;
+esac
+`
+
+func TestRunCallbackWithProtocol(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	pluginDir := data.PluginDirectory(testDataDir, "tool")
+	err := os.MkdirAll(filepath.Join(pluginDir, "bin"), 0o777)
+	assert.Nil(t, err)
+
+	plugin := New(conf, "tool")
+
+	t.Run("returns 1 as protocol version without protocol executable", func(t *testing.T) {
+		assert.Equal(t, 1, plugin.ProtocolVersion())
+		assert.False(t, plugin.ProtocolCallback("list-all"))
+	})
+
+	err = os.WriteFile(filepath.Join(pluginDir, "bin", ProtocolExecutable), []byte(protocolScript), 0o777)
+	assert.Nil(t, err)
+
+	t.Run("returns 2 as protocol version with protocol executable", func(t *testing.T) {
+		assert.Equal(t, ProtocolV2, plugin.ProtocolVersion())
+		assert.True(t, plugin.ProtocolCallback("list-all"))
+		assert.False(t, plugin.ProtocolCallback("help.overview"))
+	})
+
+	t.Run("formats results like callback scripts", func(t *testing.T) {
+		tests := []struct {
+			callback string
+			output   string
+		}{
+			{callback: "list-all", output: "1.0.0 1.1.0 2.0.0\n"},
+			{callback: "latest-stable", output: "2.0.0\n"},
+			{callback: "list-bin-paths", output: "bin libexec\n"},
+			{callback: "list-legacy-filenames", output: ".tool-version\n"},
+		}
+
+		for _, tt := range tests {
+			var stdout strings.Builder
+			err := plugin.RunCallback(tt.callback, []string{}, map[string]string{}, &stdout, &stdout)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.output, stdout.String())
+		}
+	})
+
+	t.Run("sends parameters from callback environment", func(t *testing.T) {
+		var stdout strings.Builder
+		env := map[string]string{
+			"ASDF_INSTALL_TYPE":    "version",
+			"ASDF_INSTALL_VERSION": "1.0.0",
+			"ASDF_DOWNLOAD_PATH":   filepath.Join(t.TempDir(), "download"),
+			"ASDF_INSTALL_PATH":    filepath.Join(t.TempDir(), "install"),
+			"ASDF_CONCURRENCY":     "4",
+		}
+
+		err := plugin.RunCallback("download", []string{}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+		err = plugin.RunCallback("install", []string{}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		request, err := os.ReadFile(filepath.Join(env["ASDF_INSTALL_PATH"], "request.json"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(request), `"method":"install"`)
+		assert.Contains(t, string(request), `"version":"1.0.0"`)
+		assert.Contains(t, string(request), `"version_type":"version"`)
+		assert.Contains(t, string(request), `"concurrency":"4"`)
+	})
+
+	t.Run("returns environment from exec-env", func(t *testing.T) {
+		env, err := plugin.ExecEnv(map[string]string{"ASDF_INSTALL_PATH": "/install"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"ASDF_INSTALL_PATH": "/install", "TOOL_HOME": "/install"}, env)
+	})
+
+	t.Run("returns ProtocolError when plugin reports an error", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		err := plugin.RunCallback("parse-legacy-file", []string{"/tmp/.tool-version"}, map[string]string{}, &stdout, &stderr)
+
+		protocolErr, ok := err.(ProtocolError)
+		assert.True(t, ok)
+		assert.Equal(t, "invalid_file", protocolErr.Code)
+		assert.ErrorContains(t, err, "Plugin named tool failed to parse-legacy-file: unable to parse file (invalid_file)")
+		assert.Equal(t, "parsing\n", stderr.String())
+		assert.Empty(t, stdout.String())
+	})
+
+	t.Run("returns NoCallbackError for unsupported methods", func(t *testing.T) {
+		_, err := plugin.Call("uninstall", ProtocolParams{}, map[string]string{}, os.Stderr)
+		assert.IsType(t, NoCallbackError{}, err)
+	})
+
+	t.Run("falls back to callback scripts for unsupported methods", func(t *testing.T) {
+		err := os.WriteFile(filepath.Join(pluginDir, "bin", "help.overview"), []byte("#!/usr/bin/env bash\necho overview\n"), 0o777)
+		assert.Nil(t, err)
+
+		var stdout strings.Builder
+		err = plugin.RunCallback("help.overview", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, "overview\n", stdout.String())
+	})
+
+	t.Run("returns results to backend methods without splitting them", func(t *testing.T) {
+		script := `#!/usr/bin/env bash
+case "$(cat)" in
+*'"method":"bin-paths"'*)
+  echo '{"protocol":2,"result":{"paths":["bin","share/my tool/bin"]}}'
+  ;;
+*)
+  echo '{"protocol":2,"result":{"versions":["1.0.0","2.0.0"]},"metadata":{"released":"2024-01-01"}}'
+  ;;
+esac
+`
+		err := os.WriteFile(filepath.Join(pluginDir, "bin", ProtocolExecutable), []byte(script), 0o777)
+		assert.Nil(t, err)
+
+		paths, err := plugin.BinPaths(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"bin", "share/my tool/bin"}, paths)
+
+		versions, err := plugin.ListAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0", "2.0.0"}, versions)
+
+		response, handled, err := plugin.protocolCallback("list-all", []string{}, map[string]string{}, os.Stderr)
+		assert.Nil(t, err)
+		assert.True(t, handled)
+		assert.Equal(t, map[string]any{"released": "2024-01-01"}, response.Metadata)
+	})
+
+	t.Run("returns error for invalid response", func(t *testing.T) {
+		script := "#!/usr/bin/env bash\necho 'not json'\n"
+		err := os.WriteFile(filepath.Join(pluginDir, "bin", ProtocolExecutable), []byte(script), 0o777)
+		assert.Nil(t, err)
+
+		var stdout strings.Builder
+		err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.ErrorContains(t, err, "Plugin named tool returned an invalid response to list-all")
+	})
+
+	t.Run("returns error for unsupported protocol version", func(t *testing.T) {
+		script := "#!/usr/bin/env bash\necho '{\"protocol\":3,\"result\":{}}'\n"
+		err := os.WriteFile(filepath.Join(pluginDir, "bin", ProtocolExecutable), []byte(script), 0o777)
+		assert.Nil(t, err)
+
+		var stdout strings.Builder
+		err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stdout)
+		assert.ErrorContains(t, err, "unsupported protocol version 3")
+	})
+}
@@ -0,0 +1,58 @@
+package plugins
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/asdf-vm/asdf/internal/wasm"
+)
+
+// WasmCallback returns true if the callback is implemented by a WebAssembly
+// module run in a sandbox rather than by a script
+func (p Plugin) WasmCallback(name string) bool {
+	path, err := p.CallbackPath(name)
+	return err == nil && filepath.Ext(path) == wasm.Extension
+}
+
+// runWasmCallback runs a WebAssembly callback in a sandbox. The callback can
+// read the plugin directory, write to the download and install paths if they
+// are set in env, and only sees the ASDF_ variables from env and the variables
+// the plugin declares. parse-legacy-file callbacks receive the contents of the
+// legacy file on stdin, as the file itself is outside the sandbox.
+func (p Plugin) runWasmCallback(name, callbackPath string, arguments []string, env map[string]string, stdOut, stdErr io.Writer) error {
+	permissions, err := wasm.LoadPermissions(p.Dir)
+	if err != nil {
+		return err
+	}
+
+	options := wasm.Options{
+		Args:         arguments,
+		Env:          permissions.FilterEnv(env),
+		ReadOnlyDirs: []string{p.Dir},
+		Network:      permissions.Network,
+		Hosts:        permissions.Hosts,
+		Stdout:       stdOut,
+		Stderr:       stdErr,
+	}
+
+	for _, key := range []string{"ASDF_DOWNLOAD_PATH", "ASDF_INSTALL_PATH"} {
+		if dir := env[key]; dir != "" {
+			if fileInfo, err := os.Stat(dir); err == nil && fileInfo.IsDir() {
+				options.WritableDirs = append(options.WritableDirs, dir)
+			}
+		}
+	}
+
+	if name == "parse-legacy-file" && len(arguments) > 0 {
+		file, err := os.Open(arguments[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		options.Stdin = file
+	}
+
+	return wasm.Run(callbackPath, options)
+}
@@ -0,0 +1,103 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/data"
+	"github.com/asdf-vm/asdf/internal/wasm"
+	"github.com/asdf-vm/asdf/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCallbackWithWasm(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+
+	pluginDir := data.PluginDirectory(testDataDir, "tool")
+	err := os.MkdirAll(filepath.Join(pluginDir, "bin"), 0o777)
+	assert.Nil(t, err)
+
+	modulePath := filepath.Join(pluginDir, "bin", "list-all.wasm")
+	err = repotest.BuildWasmCallback(modulePath)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	plugin := New(conf, "tool")
+
+	t.Run("returns path of WebAssembly callback", func(t *testing.T) {
+		path, err := plugin.CallbackPath("list-all")
+		assert.Nil(t, err)
+		assert.Equal(t, modulePath, path)
+		assert.True(t, plugin.WasmCallback("list-all"))
+		assert.False(t, plugin.WasmCallback("download"))
+	})
+
+	t.Run("runs WebAssembly callback in sandbox", func(t *testing.T) {
+		versions, err := plugin.ListAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0", "2.0.0"}, versions)
+	})
+
+	t.Run("passes only ASDF_ variables and variables declared by plugin", func(t *testing.T) {
+		env := map[string]string{"ASDF_INSTALL_VERSION": "1.0.0", "HOME": "/home/user", "SECRET": "secret"}
+
+		pluginEnv := fmt.Sprintf("ASDF_DATA_DIR=%s\nASDF_INSTALL_VERSION=1.0.0\nASDF_PLUGIN_NAME=tool\nASDF_PLUGIN_PATH=%s\n", testDataDir, pluginDir)
+
+		var stdout strings.Builder
+		err := plugin.RunCallback("list-all", []string{"env"}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, pluginEnv, stdout.String())
+
+		err = os.WriteFile(filepath.Join(pluginDir, wasm.PermissionsFilename), []byte("env = HOME\n"), 0o666)
+		assert.Nil(t, err)
+		defer os.Remove(filepath.Join(pluginDir, wasm.PermissionsFilename))
+
+		stdout.Reset()
+		err = plugin.RunCallback("list-all", []string{"env"}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, pluginEnv+"HOME=/home/user\n", stdout.String())
+	})
+
+	t.Run("allows writing to install path only", func(t *testing.T) {
+		installPath := t.TempDir()
+		env := map[string]string{"ASDF_INSTALL_PATH": installPath}
+
+		var stdout strings.Builder
+		err := plugin.RunCallback("list-all", []string{"write", filepath.Join(installPath, "tool"), "tool"}, env, &stdout, &stdout)
+		assert.Nil(t, err)
+		assert.FileExists(t, filepath.Join(installPath, "tool"))
+
+		err = plugin.RunCallback("list-all", []string{"write", filepath.Join(t.TempDir(), "tool"), "tool"}, env, &stdout, &stdout)
+		assert.Equal(t, wasm.ExitError{Code: 1}, err)
+	})
+
+	t.Run("passes legacy file on stdin to parse-legacy-file callback", func(t *testing.T) {
+		err := os.Link(modulePath, filepath.Join(pluginDir, "bin", "parse-legacy-file.wasm"))
+		assert.Nil(t, err)
+
+		legacyFile := filepath.Join(t.TempDir(), ".tool-version")
+		err = os.WriteFile(legacyFile, []byte("1.0.0"), 0o666)
+		assert.Nil(t, err)
+
+		versions, err := plugin.ParseLegacyFile(legacyFile)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0"}, versions)
+	})
+
+	t.Run("prefers scripts over WebAssembly callbacks", func(t *testing.T) {
+		err := os.WriteFile(filepath.Join(pluginDir, "bin", "list-all"), []byte("#!/usr/bin/env bash\necho 3.0.0\n"), 0o777)
+		assert.Nil(t, err)
+		defer os.Remove(filepath.Join(pluginDir, "bin", "list-all"))
+
+		versions, err := plugin.ListAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"3.0.0"}, versions)
+		assert.False(t, plugin.WasmCallback("list-all"))
+	})
+}
@@ -0,0 +1,118 @@
+// Package plugintemplate generates new asdf plugins from a template embedded
+// in the asdf binary.
+package plugintemplate
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/git"
+)
+
+//go:embed template
+var templateFS embed.FS
+
+const templateDir = "template"
+
+// executableDirs contain the callbacks and extension commands, which need to
+// be executable for asdf to run them
+var executableDirs = []string{"bin", "lib/commands"}
+
+// data is available to every template file
+type data struct {
+	// Name is the plugin name
+	Name string
+	// EnvPrefix is the plugin name in a form usable in environment variable
+	// names
+	EnvPrefix string
+	// Year is the current year, used in the LICENSE
+	Year int
+}
+
+// Generate writes a new plugin named pluginName to dir, which must not exist
+// yet or be empty. When initGit is true a Git repository is created in dir with
+// the generated files committed, so the plugin can be added or tested right
+// away.
+func Generate(dir, pluginName string, initGit bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if len(entries) > 0 {
+		return fmt.Errorf("directory %s already exists and is not empty", dir)
+	}
+
+	templateData := data{
+		Name:      pluginName,
+		EnvPrefix: strings.ToUpper(strings.ReplaceAll(pluginName, "-", "_")),
+		Year:      time.Now().Year(),
+	}
+
+	err = fs.WalkDir(templateFS, templateDir, func(templatePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(templatePath, templateDir), "/")
+		destination := filepath.Join(dir, filepath.FromSlash(relativePath))
+
+		if entry.IsDir() {
+			return os.MkdirAll(destination, 0o777)
+		}
+
+		contents, err := render(templatePath, templateData)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(destination, contents, fileMode(relativePath))
+	})
+	if err != nil {
+		return fmt.Errorf("unable to generate plugin: %w", err)
+	}
+
+	if !initGit {
+		return nil
+	}
+
+	_, err = git.NewRepo(dir).Init(fmt.Sprintf("Generate asdf-%s plugin", pluginName))
+	return err
+}
+
+func render(templatePath string, templateData data) ([]byte, error) {
+	contents, err := templateFS.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(path.Base(templatePath)).Parse(string(contents))
+	if err != nil {
+		return nil, err
+	}
+
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, templateData)
+	return rendered.Bytes(), err
+}
+
+// fileMode returns the mode for a generated file. The embedded file system
+// doesn't record file modes so they are derived from the file's location.
+func fileMode(relativePath string) fs.FileMode {
+	for _, dir := range executableDirs {
+		if path.Dir(relativePath) == dir {
+			return 0o777
+		}
+	}
+
+	return 0o666
+}
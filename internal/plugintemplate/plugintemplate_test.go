@@ -0,0 +1,82 @@
+package plugintemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/plugintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("writes callbacks with executable bit set", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "asdf-my-tool")
+		err := Generate(dir, "my-tool", false)
+		assert.Nil(t, err)
+
+		for _, callback := range plugins.AllCallbacks {
+			fileInfo, err := os.Stat(filepath.Join(dir, "bin", callback))
+			assert.Nil(t, err, callback)
+			assert.NotZero(t, fileInfo.Mode()&0o111, callback)
+		}
+
+		fileInfo, err := os.Stat(filepath.Join(dir, "lib", "commands", "command"))
+		assert.Nil(t, err)
+		assert.NotZero(t, fileInfo.Mode()&0o111)
+
+		fileInfo, err = os.Stat(filepath.Join(dir, "LICENSE"))
+		assert.Nil(t, err)
+		assert.Zero(t, fileInfo.Mode()&0o111)
+
+		_, err = os.Stat(filepath.Join(dir, ".git"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("renders plugin name into files", func(t *testing.T) {
+		dir := t.TempDir()
+		err := Generate(dir, "my-tool", false)
+		assert.Nil(t, err)
+
+		readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(readme), "# asdf-my-tool\n"))
+
+		execEnv, err := os.ReadFile(filepath.Join(dir, "bin", "exec-env"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(execEnv), "export MY_TOOL_HOME=")
+	})
+
+	t.Run("returns error when directory is not empty", func(t *testing.T) {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "file"), []byte{}, 0o666)
+		assert.Nil(t, err)
+
+		err = Generate(dir, "my-tool", false)
+		assert.ErrorContains(t, err, "is not empty")
+	})
+
+	t.Run("generates plugin that passes plugin test", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "asdf-my-tool")
+		err := Generate(dir, "my-tool", true)
+		assert.Nil(t, err)
+
+		head, err := git.NewRepo(dir).Head()
+		assert.Nil(t, err)
+		assert.NotZero(t, head)
+
+		var stdout strings.Builder
+		options := plugintest.Options{Name: "my-tool", URL: "file://" + dir, Command: []string{`test -x "$ASDF_DATA_DIR/shims/my-tool"`}}
+		report, err := plugintest.Run(config.Config{}, options, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		var text strings.Builder
+		report.WriteText(&text)
+		assert.Zero(t, report.Failed(), text.String())
+		assert.Zero(t, report.Skipped(), text.String())
+	})
+}
@@ -0,0 +1,375 @@
+// Package plugintest runs a conformance suite against a plugin. The plugin is
+// added to a temporary data directory, every callback it provides is checked
+// against its contract, a version of the tool is installed and exercised
+// through the shims, and everything is uninstalled again.
+package plugintest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/execenv"
+	"github.com/asdf-vm/asdf/internal/execute"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/shims"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/versions"
+	"github.com/asdf-vm/asdf/internal/wasm"
+)
+
+var helpCallbacks = []string{"help.overview", "help.deps", "help.config", "help.links"}
+
+// Options describes the plugin to test and how to test it
+type Options struct {
+	// Name is the name the plugin is added under
+	Name string
+	// URL is the Git URL or path of the plugin repository
+	URL string
+	// Ref is the Git ref of the plugin to test. When empty the default branch
+	// is used.
+	Ref string
+	// ToolVersion is the version of the tool to install. When empty the latest
+	// stable version is used, or the first version from list-all if the plugin
+	// has no latest-stable callback.
+	ToolVersion string
+	// Command is run through the shims once the tool version is installed. A
+	// single element is run as a shell command line, several elements as a
+	// command and its arguments. It is skipped when empty.
+	Command []string
+	// AsdfDir is a directory containing the asdf executable. It is added to
+	// the PATH of Command so the shims are able to invoke asdf.
+	AsdfDir string
+}
+
+// suite holds the state shared between checks while a suite is running
+type suite struct {
+	conf    config.Config
+	options Options
+	plugin  plugins.Plugin
+	report  *Report
+	stdout  io.Writer
+	stderr  io.Writer
+
+	allVersions []string
+	version     toolversions.Version
+	installed   bool
+}
+
+// Run adds the plugin to a temporary data directory and runs every check
+// against it. The temporary directory is also used as the cache directory, and
+// callbacks and the test command get ASDF_DATA_DIR and ASDF_CACHE_DIR pointed
+// at it, so nothing is written to or removed from the user's directories.
+// Output from callbacks and the test command is written to stdout and stderr. A failing
+// check does not stop the suite, checks that depend on it are skipped instead.
+func Run(conf config.Config, options Options, stdout, stderr io.Writer) (Report, error) {
+	report := Report{Plugin: options.Name}
+
+	dataDir, err := os.MkdirTemp("", fmt.Sprintf("asdf-test-%s-", options.Name))
+	if err != nil {
+		return report, fmt.Errorf("unable to create temporary data directory: %w", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	conf.DataDir, conf.CacheDir = dataDir, dataDir
+	s := suite{
+		conf:    conf,
+		options: options,
+		plugin:  plugins.New(conf, options.Name),
+		report:  &report,
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+
+	if !s.run("plugin is added", s.add) {
+		report.skipRemaining("plugin could not be added")
+		return report, nil
+	}
+
+	s.run("LICENSE file is present", s.checkLicense)
+	s.checkCallbacks()
+
+	if s.run("list-all returns versions", s.listAll) {
+		s.runOptional("latest-stable", "latest-stable returns a version from list-all", s.latestStable)
+	} else {
+		s.report.skip("latest-stable returns a version from list-all", "list-all failed")
+	}
+
+	for _, callback := range helpCallbacks {
+		s.runOptional(callback, fmt.Sprintf("%s exits successfully", callback), func() error {
+			return s.plugin.RunCallback(callback, []string{}, s.callbackEnv(), s.stdout, s.stderr)
+		})
+	}
+
+	if s.version.Value == "" {
+		s.report.skip("tool version is installed", "no tool version to install")
+	} else {
+		s.installed = s.run(fmt.Sprintf("tool version %s is installed", s.version.Value), s.install)
+	}
+
+	s.runInstalled("list-bin-paths directories exist", s.checkBinPaths)
+	if !s.hasCallback("exec-env") {
+		s.report.skip("exec-env is sourceable", "plugin has no exec-env callback")
+	} else {
+		s.runInstalled("exec-env is sourceable", s.checkExecEnv)
+	}
+
+	if len(options.Command) == 0 {
+		s.report.skip("test command succeeds", "no test command given")
+	} else {
+		s.runInstalled("test command succeeds", s.runCommand)
+	}
+
+	s.runInstalled("tool version is uninstalled", s.uninstall)
+	s.run("plugin is removed", s.remove)
+
+	return report, nil
+}
+
+// run records the outcome of a single check and returns true if it passed
+func (s *suite) run(name string, check func() error) bool {
+	start := time.Now()
+	err := check()
+	s.report.add(Check{Name: name, Err: err, Duration: time.Since(start)})
+	return err == nil
+}
+
+// runOptional runs a check of an optional callback, skipping it when the
+// plugin doesn't provide the callback
+func (s *suite) runOptional(callback, name string, check func() error) {
+	if !s.hasCallback(callback) {
+		s.report.skip(name, fmt.Sprintf("plugin has no %s callback", callback))
+		return
+	}
+
+	s.run(name, check)
+}
+
+// hasCallback returns true if the plugin provides the callback as a script,
+// through the JSON protocol or with its manifest
+func (s *suite) hasCallback(callback string) bool {
+	if _, err := s.plugin.CallbackPath(callback); err == nil {
+		return true
+	}
+
+	return s.plugin.ProtocolCallback(callback) || s.plugin.ManifestCallback(callback)
+}
+
+// runInstalled runs a check that needs the tool version to be installed
+func (s *suite) runInstalled(name string, check func() error) {
+	if !s.installed {
+		s.report.skip(name, "tool version was not installed")
+		return
+	}
+
+	s.run(name, check)
+}
+
+func (s *suite) add() error {
+	return plugins.Add(s.conf, s.options.Name, s.options.URL, s.options.Ref)
+}
+
+func (s *suite) checkLicense() error {
+	contents, err := os.ReadFile(filepath.Join(s.plugin.Dir, "LICENSE"))
+	if err != nil {
+		return errors.New("LICENSE file must be present in the plugin repository")
+	}
+
+	if len(contents) == 0 {
+		return errors.New("LICENSE file in the plugin repository must not be empty")
+	}
+
+	return nil
+}
+
+// checkCallbacks checks required callbacks are present, either as a script,
+// through the JSON protocol or in the plugin's manifest, every callback script
+// is executable and every WebAssembly callback can be loaded
+func (s *suite) checkCallbacks() {
+	for _, callback := range plugins.AllCallbacks {
+		path, err := s.plugin.CallbackPath(callback)
+		if err != nil {
+			if s.plugin.ProtocolCallback(callback) {
+				s.report.add(Check{Name: fmt.Sprintf("%s callback is provided by the protocol executable", callback)})
+			} else if s.plugin.ManifestCallback(callback) {
+				s.report.add(Check{Name: fmt.Sprintf("%s callback is provided by the manifest", callback)})
+			} else if slices.Contains(plugins.RequiredCallbacks, callback) {
+				s.report.add(Check{Name: fmt.Sprintf("%s callback is present", callback), Err: fmt.Errorf("missing required callback %s", callback)})
+			}
+			continue
+		}
+
+		if s.plugin.WasmCallback(callback) {
+			s.run(fmt.Sprintf("%s callback is a valid WebAssembly module", callback), func() error {
+				return wasm.Validate(path)
+			})
+			continue
+		}
+
+		s.run(fmt.Sprintf("%s callback is executable", callback), func() error {
+			fileInfo, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+
+			if fileInfo.Mode()&0o111 == 0 {
+				return fmt.Errorf("callback lacks executable permission: %s", callback)
+			}
+
+			return nil
+		})
+	}
+}
+
+func (s *suite) listAll() error {
+	var err error
+	s.allVersions, err = versions.AllVersions(s.plugin)
+	if err != nil {
+		return fmt.Errorf("unable to list available versions: %w", err)
+	}
+
+	if len(s.allVersions) < 1 {
+		return errors.New("list-all did not return any version")
+	}
+
+	if s.options.ToolVersion == "" {
+		s.version = toolversions.Parse(s.allVersions[0])
+	} else {
+		s.version = toolversions.Parse(s.options.ToolVersion)
+	}
+
+	return nil
+}
+
+func (s *suite) latestStable() error {
+	latest, err := versions.Latest(s.plugin, "")
+	if err != nil {
+		return fmt.Errorf("unable to get latest stable version: %w", err)
+	}
+
+	if !slices.Contains(s.allVersions, latest) {
+		return fmt.Errorf("latest stable version %s is not returned by list-all", latest)
+	}
+
+	if s.options.ToolVersion == "" {
+		s.version = toolversions.Parse(latest)
+	}
+
+	return nil
+}
+
+func (s *suite) install() error {
+	return versions.InstallOneVersion(s.conf, s.plugin, toolversions.Format(s.version), false, s.stdout, s.stderr)
+}
+
+func (s *suite) checkBinPaths() error {
+	paths, err := shims.ExecutablePaths(s.conf, s.plugin, s.version)
+	if err != nil {
+		return fmt.Errorf("unable to get bin paths: %w", err)
+	}
+
+	var missing []string
+	for _, path := range paths {
+		fileInfo, err := os.Stat(path)
+		if err != nil || !fileInfo.IsDir() {
+			missing = append(missing, path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("bin paths do not exist: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func (s *suite) checkExecEnv() error {
+	env := execenv.MergeEnv(s.callbackEnv(), map[string]string{
+		"ASDF_INSTALL_TYPE":    s.version.Type,
+		"ASDF_INSTALL_VERSION": s.version.Value,
+		"ASDF_INSTALL_PATH":    installs.InstallPath(s.conf, s.plugin, s.version),
+	})
+
+	_, err := execenv.Generate(s.plugin, env)
+	if err != nil {
+		return fmt.Errorf("unable to source exec-env: %w", err)
+	}
+
+	return nil
+}
+
+func (s *suite) runCommand() error {
+	path := shims.Directory(s.conf)
+	if s.options.AsdfDir != "" {
+		path += string(os.PathListSeparator) + s.options.AsdfDir
+	}
+	path += string(os.PathListSeparator) + os.Getenv("PATH")
+
+	env := execenv.MergeEnv(s.callbackEnv(), map[string]string{
+		"PATH": path,
+		fmt.Sprintf("ASDF_%s_VERSION", strings.ToUpper(s.plugin.Name)): toolversions.Format(s.version),
+	})
+
+	// Bash looks the command up in the PATH with the shims directory, and
+	// "$@" passes the arguments on exactly as they were given
+	args := []string{"-c", `"$@"`, "bash"}
+	if len(s.options.Command) == 1 {
+		args = []string{"-c", s.options.Command[0]}
+	} else {
+		args = append(args, s.options.Command...)
+	}
+
+	cmd := exec.Command("bash", args...)
+	cmd.Env = execute.MapToSlice(env)
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("test command %q failed: %w", strings.Join(s.options.Command, " "), err)
+	}
+
+	return nil
+}
+
+func (s *suite) uninstall() error {
+	err := versions.Uninstall(s.conf, s.plugin, toolversions.Format(s.version), s.stdout, s.stderr)
+	if err != nil {
+		return err
+	}
+
+	if installs.IsInstalled(s.conf, s.plugin, s.version) {
+		return fmt.Errorf("install directory %s still exists", installs.InstallPath(s.conf, s.plugin, s.version))
+	}
+
+	return nil
+}
+
+func (s *suite) remove() error {
+	err := plugins.Remove(s.conf, s.plugin.Name, s.stdout, s.stderr)
+	if err != nil {
+		return err
+	}
+
+	if s.plugin.Exists() == nil {
+		return fmt.Errorf("plugin directory %s still exists", s.plugin.Dir)
+	}
+
+	return nil
+}
+
+// callbackEnv returns the current environment with ASDF_DATA_DIR pointed at
+// the suite's data directory, so asdf invoked by the shims uses it too
+func (s *suite) callbackEnv() map[string]string {
+	return execenv.MergeEnv(execenv.CurrentEnv(), map[string]string{
+		"ASDF_DATA_DIR":  s.conf.DataDir,
+		"ASDF_CACHE_DIR": s.conf.CacheDir,
+	})
+}
@@ -0,0 +1,125 @@
+package plugintest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginName = "lua"
+
+func TestRun(t *testing.T) {
+	testDataDir := t.TempDir()
+	conf := config.Config{DataDir: testDataDir}
+	repoPath, err := repotest.GeneratePlugin("dummy_plugin", t.TempDir(), testPluginName)
+	assert.Nil(t, err)
+
+	t.Run("passes for a conforming plugin", func(t *testing.T) {
+		var stdout strings.Builder
+		options := Options{Name: testPluginName, URL: repoPath, Command: []string{`test -x "$ASDF_DATA_DIR/shims/dummy"`}}
+		report, err := Run(conf, options, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.Zero(t, report.Failed(), stdout.String())
+		assert.Contains(t, checkNames(report), "latest-stable returns a version from list-all")
+		assert.Contains(t, checkNames(report), "tool version 2.0.0 is installed")
+		assert.Contains(t, checkNames(report), "test command succeeds")
+		assert.Contains(t, checkNames(report), "plugin is removed")
+	})
+
+	t.Run("installs the given tool version", func(t *testing.T) {
+		var stdout strings.Builder
+		report, err := Run(conf, Options{Name: testPluginName, URL: repoPath, ToolVersion: "1.0.0"}, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.Zero(t, report.Failed())
+		assert.Contains(t, checkNames(report), "tool version 1.0.0 is installed")
+	})
+
+	t.Run("does not write to the data directory", func(t *testing.T) {
+		var stdout strings.Builder
+		_, err := Run(conf, Options{Name: testPluginName, URL: repoPath}, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		entries, err := os.ReadDir(testDataDir)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("does not touch the cache directory", func(t *testing.T) {
+		conf := config.Config{DataDir: testDataDir, CacheDir: t.TempDir()}
+		download := filepath.Join(conf.CacheDir, "downloads", testPluginName, "1.0.0")
+		assert.Nil(t, os.MkdirAll(download, 0o777))
+
+		var stdout strings.Builder
+		options := Options{Name: testPluginName, URL: repoPath, Command: []string{`test "$ASDF_CACHE_DIR" = "$ASDF_DATA_DIR"`}}
+		report, err := Run(conf, options, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.True(t, findCheck(report, "test command succeeds").Passed(), stdout.String())
+		assert.DirExists(t, download)
+	})
+
+	t.Run("passes test command arguments unchanged", func(t *testing.T) {
+		var stdout strings.Builder
+		options := Options{Name: testPluginName, URL: repoPath, Command: []string{"test", "a b", "=", "a b"}}
+		report, err := Run(conf, options, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.True(t, findCheck(report, "test command succeeds").Passed(), stdout.String())
+	})
+
+	t.Run("reports failing test command and keeps going", func(t *testing.T) {
+		var stdout strings.Builder
+		report, err := Run(conf, Options{Name: testPluginName, URL: repoPath, Command: []string{"false"}}, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, report.Failed())
+		assert.Contains(t, checkNames(report), "tool version is uninstalled")
+		assert.True(t, findCheck(report, "plugin is removed").Passed())
+	})
+
+	t.Run("reports install failure and skips checks that depend on it", func(t *testing.T) {
+		var stdout strings.Builder
+		report, err := Run(conf, Options{Name: testPluginName, URL: repoPath, ToolVersion: "other-dummy"}, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, report.Failed())
+		assert.NotNil(t, findCheck(report, "tool version other-dummy is installed").Err)
+		assert.True(t, findCheck(report, "list-bin-paths directories exist").Skipped)
+		assert.True(t, findCheck(report, "tool version is uninstalled").Skipped)
+	})
+
+	t.Run("reports plugin that cannot be added", func(t *testing.T) {
+		var stdout strings.Builder
+		report, err := Run(conf, Options{Name: testPluginName, URL: "/nonexistent"}, &stdout, &stdout)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, report.Failed())
+		assert.NotNil(t, findCheck(report, "plugin is added").Err)
+		assert.True(t, findCheck(report, "plugin is removed").Skipped)
+	})
+}
+
+func checkNames(report Report) (names []string) {
+	for _, check := range report.Checks {
+		names = append(names, check.Name)
+	}
+
+	return names
+}
+
+func findCheck(report Report, name string) Check {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+
+	return Check{}
+}
@@ -0,0 +1,159 @@
+package plugintest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Check is the outcome of a single check run against the plugin
+type Check struct {
+	Name       string
+	Err        error
+	Skipped    bool
+	SkipReason string
+	Duration   time.Duration
+}
+
+// Passed returns true if the check ran and succeeded
+func (c Check) Passed() bool {
+	return !c.Skipped && c.Err == nil
+}
+
+// Report holds the outcome of every check in the order they were run
+type Report struct {
+	Plugin string
+	Checks []Check
+}
+
+// Failed returns the number of checks that failed
+func (r Report) Failed() (count int) {
+	for _, check := range r.Checks {
+		if !check.Skipped && check.Err != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Skipped returns the number of checks that were skipped
+func (r Report) Skipped() (count int) {
+	for _, check := range r.Checks {
+		if check.Skipped {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r *Report) add(check Check) {
+	r.Checks = append(r.Checks, check)
+}
+
+func (r *Report) skip(name, reason string) {
+	r.add(Check{Name: name, Skipped: true, SkipReason: reason})
+}
+
+// skipRemaining records the checks that are always run as skipped, so reports
+// for plugins that couldn't be added have the same shape as any other report
+func (r *Report) skipRemaining(reason string) {
+	for _, name := range []string{"LICENSE file is present", "list-all returns versions", "tool version is installed", "test command succeeds", "plugin is removed"} {
+		r.skip(name, reason)
+	}
+}
+
+// WriteText writes a human readable pass/fail line for every check followed by
+// a summary
+func (r Report) WriteText(writer io.Writer) error {
+	for _, check := range r.Checks {
+		var err error
+		switch {
+		case check.Skipped:
+			_, err = fmt.Fprintf(writer, "SKIPPED: %s (%s)\n", check.Name, check.SkipReason)
+		case check.Err != nil:
+			_, err = fmt.Fprintf(writer, "FAILED:  %s: %s\n", check.Name, check.Err)
+		default:
+			_, err = fmt.Fprintf(writer, "PASSED:  %s\n", check.Name)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(writer, "\n%d checks, %d failed, %d skipped\n", len(r.Checks), r.Failed(), r.Skipped())
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML so it can be consumed by CI
+// systems. The plugin is a test suite and every check is a test case.
+func (r Report) WriteJUnit(writer io.Writer) error {
+	suite := junitTestSuite{
+		Name:     r.Plugin,
+		Tests:    len(r.Checks),
+		Failures: r.Failed(),
+		Skipped:  r.Skipped(),
+	}
+
+	var total time.Duration
+	for _, check := range r.Checks {
+		total += check.Duration
+
+		testCase := junitTestCase{Name: check.Name, ClassName: r.Plugin, Time: formatSeconds(check.Duration)}
+		switch {
+		case check.Skipped:
+			testCase.Skipped = &junitMessage{Message: check.SkipReason}
+		case check.Err != nil:
+			testCase.Failure = &junitMessage{Message: check.Err.Error()}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = formatSeconds(total)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+func formatSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
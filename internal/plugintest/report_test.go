@@ -0,0 +1,56 @@
+package plugintest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() Report {
+	return Report{
+		Plugin: "lua",
+		Checks: []Check{
+			{Name: "plugin is added", Duration: 1500 * time.Millisecond},
+			{Name: "list-all returns versions", Err: errors.New("list-all did not return any version")},
+			{Name: "help.deps exits successfully", Skipped: true, SkipReason: "plugin has no help.deps callback"},
+		},
+	}
+}
+
+func TestReportWriteText(t *testing.T) {
+	var stdout strings.Builder
+	err := testReport().WriteText(&stdout)
+	assert.Nil(t, err)
+
+	expected := `PASSED:  plugin is added
+FAILED:  list-all returns versions: list-all did not return any version
+SKIPPED: help.deps exits successfully (plugin has no help.deps callback)
+
+3 checks, 1 failed, 1 skipped
+`
+	assert.Equal(t, expected, stdout.String())
+}
+
+func TestReportWriteJUnit(t *testing.T) {
+	var stdout strings.Builder
+	err := testReport().WriteJUnit(&stdout)
+	assert.Nil(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="lua" tests="3" failures="1" skipped="1" time="1.500">
+    <testcase name="plugin is added" classname="lua" time="1.500"></testcase>
+    <testcase name="list-all returns versions" classname="lua" time="0.000">
+      <failure message="list-all did not return any version"></failure>
+    </testcase>
+    <testcase name="help.deps exits successfully" classname="lua" time="0.000">
+      <skipped message="plugin has no help.deps callback"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, stdout.String())
+}
@@ -0,0 +1,175 @@
+package resolve
+
+import (
+	"os"
+	"path"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+)
+
+// Result is the resolution of a single tool by AllVersions
+type Result struct {
+	Plugin   plugins.Plugin
+	Versions ToolVersions
+	Found    bool
+	Err      error
+}
+
+// AllVersions resolves every given tool the same way Version does, but walks
+// up from the directory only once. Each tool versions file is read once for
+// all tools and each plugin's legacy file names are listed once. The results
+// are in the same order as the plugins.
+func AllVersions(conf config.Config, allPlugins []plugins.Plugin, directory string) ([]Result, error) {
+	results := make([]Result, len(allPlugins))
+	pending := []int{}
+	for i, plugin := range allPlugins {
+		results[i].Plugin = plugin
+
+		versions, envVariableName, found := findVersionsInEnv(plugin.Name)
+		if found {
+			results[i].Versions = ToolVersions{Versions: versions, Source: envVariableName}
+			results[i].Found = true
+			continue
+		}
+
+		pending = append(pending, i)
+	}
+
+	if conf.ToolVersionsFile != "" {
+		if _, err := os.Stat(conf.ToolVersionsFile); err != nil && len(pending) > 0 {
+			return results, forcedFileError(conf, err)
+		}
+
+		err := findInFiles(allPlugins, results, pending, []string{conf.ToolVersionsFile})
+		return resolveAllInstalled(conf, results), err
+	}
+
+	legacyEnabled, err := conf.LegacyVersionFile()
+	if err != nil {
+		return results, err
+	}
+
+	legacy := make([]legacyFiles, len(allPlugins))
+	if legacyEnabled {
+		for _, i := range pending {
+			legacy[i], results[i].Err = findLegacyFiles(conf, allPlugins[i], nil)
+		}
+	}
+
+	filenames := conf.ToolVersionsFilenames()
+	start := directory
+	for len(pending) > 0 {
+		// Read every tool versions file in the directory once for all tools
+		fileVersions := make([][]toolversions.ToolVersions, len(filenames))
+		for j, filename := range filenames {
+			fileVersions[j], err = readToolVersions(path.Join(directory, filename))
+			if err != nil {
+				return results, err
+			}
+		}
+
+		stillPending := []int{}
+		for _, i := range pending {
+			if results[i].Err == nil {
+				results[i].Versions, results[i].Found, results[i].Err = findVersionsInLegacyFile(allPlugins[i], legacy[i], directory, nil)
+			}
+
+			for j, filename := range filenames {
+				if results[i].Found || results[i].Err != nil {
+					break
+				}
+
+				if versions, ok := findTool(fileVersions[j], allPlugins[i].Name); ok {
+					results[i].Versions = ToolVersions{Versions: versions, Source: filename, Directory: directory}
+					results[i].Found = true
+				}
+			}
+
+			if !results[i].Found && results[i].Err == nil {
+				stillPending = append(stillPending, i)
+			}
+		}
+		pending = stillPending
+
+		nextDir := path.Dir(directory)
+		if nextDir == directory {
+			break
+		}
+		directory = nextDir
+	}
+
+	fallbackFiles := []string{}
+	for _, filepath := range conf.FallbackToolVersionsFiles() {
+		if !walked(conf, start, filepath) {
+			fallbackFiles = append(fallbackFiles, filepath)
+		}
+	}
+
+	err = findInFiles(allPlugins, results, pending, fallbackFiles)
+	return resolveAllInstalled(conf, results), err
+}
+
+// findInFiles looks up the pending tools in each of the tool versions files in
+// turn, reading each file once. Files that don't exist are skipped.
+func findInFiles(allPlugins []plugins.Plugin, results []Result, pending []int, files []string) error {
+	for _, filepath := range files {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		fileVersions, err := readToolVersions(filepath)
+		if err != nil {
+			return err
+		}
+
+		stillPending := []int{}
+		for _, i := range pending {
+			if versions, ok := findTool(fileVersions, allPlugins[i].Name); ok {
+				results[i].Versions = ToolVersions{Versions: versions, Source: path.Base(filepath), Directory: path.Dir(filepath)}
+				results[i].Found = true
+				continue
+			}
+
+			stillPending = append(stillPending, i)
+		}
+		pending = stillPending
+	}
+
+	return nil
+}
+
+// resolveAllInstalled resolves the constraints and prefixes of every tool that
+// was found
+func resolveAllInstalled(conf config.Config, results []Result) []Result {
+	for i, result := range results {
+		if result.Found && result.Err == nil {
+			results[i].Versions = resolveInstalled(conf, result.Plugin, result.Versions, nil)
+		}
+	}
+
+	return results
+}
+
+// readToolVersions returns every tool in the tool versions file, or no tools if
+// it doesn't exist
+func readToolVersions(filepath string) ([]toolversions.ToolVersions, error) {
+	if _, err := os.Stat(filepath); err != nil {
+		return nil, nil
+	}
+
+	return toolversions.GetAllToolsAndVersions(filepath)
+}
+
+// findTool returns the versions of the first line for the tool, like
+// toolversions.FindToolVersions does
+func findTool(fileVersions []toolversions.ToolVersions, toolName string) ([]string, bool) {
+	for _, tool := range fileVersions {
+		if tool.Name == toolName {
+			return tool.Versions, true
+		}
+	}
+
+	return []string{}, false
+}
@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/legacyfile"
 	"github.com/asdf-vm/asdf/internal/plugins"
 	"github.com/asdf-vm/asdf/internal/toolversions"
 )
@@ -19,18 +22,65 @@ type ToolVersions struct {
 	Versions  []string
 	Directory string
 	Source    string
+	// Requested are the versions as they were specified, before version
+	// constraints and prefixes were resolved to the installed versions in
+	// Versions. They are in the same order as Versions.
+	Requested []string
 }
 
 // Version takes a plugin and a directory and resolves the tool to one or more
-// versions.
+// versions. Version constraints and prefixes are resolved to the highest
+// installed version matching them.
 func Version(conf config.Config, plugin plugins.Plugin, directory string) (versions ToolVersions, found bool, err error) {
+	versions, found, err = findVersions(conf, plugin, directory, nil)
+	if found && err == nil {
+		versions = resolveInstalled(conf, plugin, versions, nil)
+	}
+
+	return versions, found, err
+}
+
+// legacyFiles are the legacy version files a tool's versions may be read from
+type legacyFiles struct {
+	// plugin are the files listed by the plugin's list-legacy-filenames
+	// callback, which are parsed by the plugin
+	plugin []string
+	// native are the files asdf parses itself
+	native []string
+}
+
+func findVersions(conf config.Config, plugin plugins.Plugin, directory string, trace *Trace) (versions ToolVersions, found bool, err error) {
 	version, envVariableName, found := findVersionsInEnv(plugin.Name)
 	if found {
+		trace.add(envVariableName, "set to %s", strings.Join(version, " "))
 		return ToolVersions{Versions: version, Source: envVariableName}, true, nil
 	}
+	trace.add(envVariableName, "not set")
+
+	if conf.ToolVersionsFile != "" {
+		return findVersionsInForcedFile(conf, plugin, trace)
+	}
 
+	legacyEnabled, err := conf.LegacyVersionFile()
+	if err != nil {
+		return versions, false, err
+	}
+
+	// Legacy files are looked up once rather than in every directory, as it
+	// may invoke a plugin callback
+	var legacy legacyFiles
+	if legacyEnabled {
+		legacy, err = findLegacyFiles(conf, plugin, trace)
+		if err != nil {
+			return versions, false, err
+		}
+	} else {
+		trace.add("legacy_version_file", "disabled, only %s files are read", strings.Join(conf.ToolVersionsFilenames(), " and "))
+	}
+
+	start := directory
 	for !found {
-		versions, found, err = findVersionsInDir(conf, plugin, directory)
+		versions, found, err = findVersionsInDir(conf, plugin, legacy, directory, trace)
 		if err != nil {
 			return versions, false, err
 		}
@@ -42,33 +92,94 @@ func Version(conf config.Config, plugin plugins.Plugin, directory string) (versi
 		directory = nextDir
 	}
 
+	if !found {
+		return findVersionsInFallbackFiles(conf, plugin, start, trace)
+	}
+
 	return versions, found, err
 }
 
-func findVersionsInDir(conf config.Config, plugin plugins.Plugin, directory string) (versions ToolVersions, found bool, err error) {
-	legacyFiles, err := conf.LegacyVersionFile()
-	if err != nil {
+func findVersionsInDir(conf config.Config, plugin plugins.Plugin, legacy legacyFiles, directory string, trace *Trace) (versions ToolVersions, found bool, err error) {
+	versions, found, err = findVersionsInLegacyFile(plugin, legacy, directory, trace)
+	if found || err != nil {
 		return versions, found, err
 	}
 
-	if legacyFiles {
-		versions, found, err := findVersionsInLegacyFile(plugin, directory)
-
+	// The Source of the versions is the name of the file that matched, as
+	// there may be several tool versions files in the directory
+	for _, filename := range conf.ToolVersionsFilenames() {
+		versions, found, err = findVersionsInFile(plugin, path.Join(directory, filename), trace)
 		if found || err != nil {
 			return versions, found, err
 		}
 	}
 
-	filepath := path.Join(directory, conf.DefaultToolVersionsFilename)
+	return versions, false, nil
+}
+
+// findVersionsInFile looks up the tool in a tool versions file, returning found
+// false if the file doesn't exist
+func findVersionsInFile(plugin plugins.Plugin, filepath string, trace *Trace) (versions ToolVersions, found bool, err error) {
+	if _, err := os.Stat(filepath); err != nil {
+		trace.add(filepath, "not found")
+		return versions, false, nil
+	}
+
+	versionsSlice, found, err := toolversions.FindToolVersions(filepath, plugin.Name)
+	if found || err != nil {
+		trace.add(filepath, "%s", foundResult(versionsSlice, err))
+		return ToolVersions{Versions: versionsSlice, Source: path.Base(filepath), Directory: path.Dir(filepath)}, found, err
+	}
 
-	if _, err = os.Stat(filepath); err == nil {
-		versions, found, err := toolversions.FindToolVersions(filepath, plugin.Name)
+	trace.add(filepath, "no entry for %s", plugin.Name)
+	return versions, false, nil
+}
+
+// findVersionsInForcedFile looks up the tool in the file set by
+// ASDF_TOOL_VERSIONS_FILE, which replaces every version file that is otherwise
+// read
+func findVersionsInForcedFile(conf config.Config, plugin plugins.Plugin, trace *Trace) (versions ToolVersions, found bool, err error) {
+	if _, err := os.Stat(conf.ToolVersionsFile); err != nil {
+		trace.add(conf.ToolVersionsFile, "not found")
+		return versions, false, forcedFileError(conf, err)
+	}
+
+	return findVersionsInFile(plugin, conf.ToolVersionsFile, trace)
+}
+
+// forcedFileError describes why the file set by ASDF_TOOL_VERSIONS_FILE can't
+// be read
+func forcedFileError(conf config.Config, err error) error {
+	return fmt.Errorf("unable to read ASDF_TOOL_VERSIONS_FILE %s: %w", conf.ToolVersionsFile, err)
+}
+
+// findVersionsInFallbackFiles looks up the tool in the global and system-wide
+// .tool-versions files, so tools have a version wherever asdf is run from.
+// Files already read while searching up from the start directory are skipped.
+func findVersionsInFallbackFiles(conf config.Config, plugin plugins.Plugin, start string, trace *Trace) (versions ToolVersions, found bool, err error) {
+	for _, filepath := range conf.FallbackToolVersionsFiles() {
+		if walked(conf, start, filepath) {
+			continue
+		}
+
+		versions, found, err = findVersionsInFile(plugin, filepath, trace)
 		if found || err != nil {
-			return ToolVersions{Versions: versions, Source: conf.DefaultToolVersionsFilename, Directory: directory}, found, err
+			return versions, found, err
 		}
 	}
 
-	return versions, found, nil
+	return versions, false, nil
+}
+
+// walked returns true if the .tool-versions file is read when searching up
+// from the start directory to the root directory
+func walked(conf config.Config, start, filepath string) bool {
+	if !slices.Contains(conf.ToolVersionsFilenames(), path.Base(filepath)) {
+		return false
+	}
+
+	dir := path.Dir(filepath)
+	return dir == start || dir == "/" || strings.HasPrefix(start, dir+"/")
 }
 
 // findVersionsInEnv returns the version from the environment if present
@@ -81,45 +192,139 @@ func findVersionsInEnv(pluginName string) ([]string, string, bool) {
 	return parseVersion(versionString), envVariableName, true
 }
 
-// findVersionsInLegacyFile looks up a legacy version in the given directory if
-// the specified plugin has a list-legacy-filenames callback script. If the
-// callback script exists asdf will look for files with the given name in the
-// current and extract the version from them.
-func findVersionsInLegacyFile(plugin plugins.Plugin, directory string) (versions ToolVersions, found bool, err error) {
-	var legacyFileNames []string
+// findLegacyFiles returns the legacy version files of the plugin. These are the
+// files listed by the plugin's list-legacy-filenames callback, if it has one,
+// and the files asdf can parse itself that are mapped to the plugin in the
+// `[legacy_version_files]` section of the asdfrc file. Files listed by the
+// plugin are parsed by the plugin, so its callbacks override asdf's parsers.
+func findLegacyFiles(conf config.Config, plugin plugins.Plugin, trace *Trace) (files legacyFiles, err error) {
+	files.plugin, err = plugin.LegacyFilenames()
+	if err != nil {
+		trace.add("list-legacy-filenames callback", "failed: %s", err)
+		return files, err
+	}
+	trace.add("list-legacy-filenames callback", "%s", listResult(files.plugin))
 
-	legacyFileNames, err = plugin.LegacyFilenames()
+	mapped, err := conf.LegacyVersionFiles()
 	if err != nil {
-		return versions, false, err
+		return files, err
+	}
+
+	for _, filename := range legacyfile.Filenames() {
+		if mapped[filename] == plugin.Name && !slices.Contains(files.plugin, filename) {
+			files.native = append(files.native, filename)
+		}
 	}
+	trace.add("built-in legacy file parsers", "%s", listResult(files.native))
 
-	for _, filename := range legacyFileNames {
+	return files, nil
+}
+
+// findVersionsInLegacyFile looks up a legacy version in the given directory.
+// Files listed by the plugin are parsed with its parse-legacy-file callback,
+// the others with asdf's own parsers.
+func findVersionsInLegacyFile(backend plugins.Backend, files legacyFiles, directory string, trace *Trace) (versions ToolVersions, found bool, err error) {
+	for _, filename := range files.plugin {
 		filepath := path.Join(directory, filename)
 		if _, err := os.Stat(filepath); err == nil {
-			versionsSlice, err := plugin.ParseLegacyVersionFile(filepath)
+			versionsSlice, err := backend.ParseLegacyFile(filepath)
 
 			if len(versionsSlice) == 0 || (len(versionsSlice) == 1 && versionsSlice[0] == "") {
+				trace.add(filepath, "parsed by plugin, no version")
 				return versions, false, nil
 			}
+			trace.add(filepath, "parsed by plugin, %s", foundResult(versionsSlice, err))
 			return ToolVersions{Versions: versionsSlice, Source: filename, Directory: directory}, err == nil, err
 		}
+		trace.add(filepath, "not found")
 	}
 
-	return versions, found, err
+	for _, filename := range files.native {
+		filepath := path.Join(directory, filename)
+		if _, err := os.Stat(filepath); err != nil {
+			trace.add(filepath, "not found")
+			continue
+		}
+
+		versionsSlice, err := legacyfile.Parse(filepath)
+		if err != nil {
+			trace.add(filepath, "%s", foundResult(versionsSlice, err))
+			return versions, false, err
+		}
+
+		if len(versionsSlice) > 0 {
+			trace.add(filepath, "%s", foundResult(versionsSlice, nil))
+			return ToolVersions{Versions: versionsSlice, Source: filename, Directory: directory}, true, nil
+		}
+		trace.add(filepath, "no version")
+	}
+
+	return versions, false, nil
 }
 
-// parseVersion parses the raw version
-func parseVersion(rawVersions string) []string {
-	var versions []string
-	for _, version := range strings.Split(rawVersions, " ") {
-		version = strings.TrimSpace(version)
-		if len(version) > 0 {
-			versions = append(versions, version)
+// resolveInstalled replaces version constraints and prefixes with the highest
+// installed version matching them. Prefixes like `20` are only resolved when no
+// version with exactly that name is installed. Versions that don't match any
+// installed version are left as they are. Path versions are expanded and made
+// absolute, relative to the directory of the file that set them.
+func resolveInstalled(conf config.Config, plugin plugins.Plugin, versions ToolVersions, trace *Trace) ToolVersions {
+	versions.Requested = versions.Versions
+	versions.Versions = []string{}
+
+	var installed []string
+	for _, requested := range versions.Requested {
+		version := requested
+		if parsed := toolversions.Parse(requested); parsed.Type == "path" {
+			version = toolversions.Format(toolversions.ExpandPath(parsed, versions.Directory))
+			if version != requested {
+				trace.add(requested, "expanded to %s", version)
+			}
+		} else if toolversions.IsConstraint(requested) || toolversions.IsPrefix(requested) {
+			if installed == nil {
+				installed, _ = installs.Installed(conf, plugin)
+			}
+
+			if slices.Contains(installed, requested) {
+				trace.add(requested, "installed")
+			} else if constraint, err := toolversions.ParseConstraint(requested); err == nil {
+				if highest, ok := constraint.Highest(installed); ok {
+					version = highest
+					trace.add(requested, "resolved to installed version %s", highest)
+				} else {
+					trace.add(requested, "no installed version matches")
+				}
+			}
 		}
+
+		versions.Versions = append(versions.Versions, version)
 	}
+
 	return versions
 }
 
+// foundResult describes the versions read from a file for a trace
+func foundResult(versions []string, err error) string {
+	if err != nil {
+		return fmt.Sprintf("failed: %s", err)
+	}
+
+	return fmt.Sprintf("found %s", strings.Join(versions, " "))
+}
+
+// listResult describes a list of file names for a trace
+func listResult(filenames []string) string {
+	if len(filenames) == 0 {
+		return "none"
+	}
+
+	return strings.Join(filenames, " ")
+}
+
+// parseVersion parses the raw version
+func parseVersion(rawVersions string) []string {
+	return toolversions.SplitVersions(rawVersions)
+}
+
 func variableVersionName(toolName string) string {
 	return fmt.Sprintf("ASDF_%s_VERSION", strings.ToUpper(toolName))
 }
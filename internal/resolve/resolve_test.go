@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/asdf-vm/asdf/internal/config"
@@ -67,6 +68,233 @@ func TestVersion(t *testing.T) {
 		assert.True(t, found)
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3"})
 	})
+
+	t.Run("resolves constraints and prefixes to highest installed version", func(t *testing.T) {
+		for _, version := range []string{"1.2.3", "1.10.0", "2.0.0", "2.1.0-rc1"} {
+			err := os.MkdirAll(filepath.Join(conf.DataDir, "installs", "lua", version), 0o777)
+			assert.Nil(t, err)
+		}
+
+		data := []byte("lua >=1.2 <2 2 ~3.0 system")
+		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), data, 0o666)
+		assert.Nil(t, err)
+
+		toolVersion, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"1.10.0", "2.0.0", "~3.0", "system"}, toolVersion.Versions)
+		assert.Equal(t, []string{">=1.2 <2", "2", "~3.0", "system"}, toolVersion.Requested)
+	})
+
+	t.Run("resolves relative path versions against directory of file", func(t *testing.T) {
+		subDir := filepath.Join(currentDir, "subdir")
+		assert.Nil(t, os.MkdirAll(subDir, 0o777))
+		t.Setenv("ASDF_TEST_BUILD", "build")
+
+		data := []byte("lua path:../lua/$ASDF_TEST_BUILD path:/opt/lua")
+		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), data, 0o666)
+		assert.Nil(t, err)
+
+		toolVersion, found, err := Version(conf, plugin, subDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"path:" + filepath.Join(filepath.Dir(currentDir), "lua", "build"), "path:/opt/lua"}, toolVersion.Versions)
+		assert.Equal(t, []string{"path:../lua/$ASDF_TEST_BUILD", "path:/opt/lua"}, toolVersion.Requested)
+	})
+
+	t.Run("prefers installed version with exactly the name of a prefix", func(t *testing.T) {
+		err := os.MkdirAll(filepath.Join(conf.DataDir, "installs", "lua", "2"), 0o777)
+		assert.Nil(t, err)
+
+		data := []byte("lua 2")
+		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), data, 0o666)
+		assert.Nil(t, err)
+
+		toolVersion, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"2"}, toolVersion.Versions)
+	})
+}
+
+func TestVersionFallbackFiles(t *testing.T) {
+	fallbackDir := t.TempDir()
+	conf := config.Config{
+		DataDir:                     t.TempDir(),
+		DefaultToolVersionsFilename: ".tool-versions",
+		ConfigFile:                  "testdata/asdfrc",
+		GlobalToolVersionsFile:      filepath.Join(fallbackDir, ".tool-versions"),
+		SystemToolVersionsFile:      filepath.Join(fallbackDir, "tool-versions"),
+	}
+	_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, "lua")
+	assert.Nil(t, err)
+	plugin := plugins.New(conf, "lua")
+	currentDir := t.TempDir()
+
+	t.Run("returns found false when fallback files don't exist", func(t *testing.T) {
+		_, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("returns version from system-wide file when global file lacks tool", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(conf.GlobalToolVersionsFile, []byte("ruby 1.0.0\n"), 0o666))
+		assert.Nil(t, os.WriteFile(conf.SystemToolVersionsFile, []byte("lua 1.0.0\n"), 0o666))
+
+		toolVersion, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"1.0.0"}, toolVersion.Versions)
+		assert.Equal(t, fallbackDir, toolVersion.Directory)
+		assert.Equal(t, "tool-versions", toolVersion.Source)
+	})
+
+	t.Run("returns version from global file before system-wide file", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(conf.GlobalToolVersionsFile, []byte("lua 2.0.0\n"), 0o666))
+
+		toolVersion, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"2.0.0"}, toolVersion.Versions)
+		assert.Equal(t, ".tool-versions", toolVersion.Source)
+	})
+
+	t.Run("returns version from directory before fallback files", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte("lua 3.0.0\n"), 0o666))
+		defer os.Remove(filepath.Join(currentDir, ".tool-versions"))
+
+		toolVersion, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"3.0.0"}, toolVersion.Versions)
+	})
+
+	t.Run("explains that a fallback file was used", func(t *testing.T) {
+		_, found, trace, err := Explain(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Step{Source: conf.GlobalToolVersionsFile, Result: "found 2.0.0"}, trace.Steps[len(trace.Steps)-1])
+		assert.Contains(t, trace.Reason, conf.GlobalToolVersionsFile+" is a fallback file")
+	})
+
+	t.Run("doesn't read global file twice when searching from below its directory", func(t *testing.T) {
+		subDir := filepath.Join(fallbackDir, "subdir")
+		assert.Nil(t, os.MkdirAll(subDir, 0o777))
+
+		_, found, trace, err := Explain(conf, plugin, subDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+
+		consulted := 0
+		for _, step := range trace.Steps {
+			if step.Source == conf.GlobalToolVersionsFile {
+				consulted++
+			}
+		}
+		assert.Equal(t, 1, consulted)
+		assert.Contains(t, trace.Reason, " is the first file setting lua")
+	})
+}
+
+func TestVersionForcedFile(t *testing.T) {
+	forcedFile := filepath.Join(t.TempDir(), "tool-versions")
+	conf := config.Config{
+		DataDir:                     t.TempDir(),
+		DefaultToolVersionsFilename: ".tool-versions",
+		ConfigFile:                  "testdata/asdfrc",
+		GlobalToolVersionsFile:      filepath.Join(t.TempDir(), ".tool-versions"),
+		ToolVersionsFile:            forcedFile,
+	}
+	_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, "lua")
+	assert.Nil(t, err)
+	plugin := plugins.New(conf, "lua")
+
+	currentDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte("lua 1.0.0\n"), 0o666))
+	assert.Nil(t, os.WriteFile(conf.GlobalToolVersionsFile, []byte("lua 1.0.0\n"), 0o666))
+
+	t.Run("returns error when file doesn't exist", func(t *testing.T) {
+		_, found, err := Version(conf, plugin, currentDir)
+		assert.ErrorContains(t, err, "unable to read ASDF_TOOL_VERSIONS_FILE "+forcedFile)
+		assert.False(t, found)
+
+		_, err = AllVersions(conf, []plugins.Plugin{plugin}, currentDir)
+		assert.ErrorContains(t, err, "unable to read ASDF_TOOL_VERSIONS_FILE "+forcedFile)
+	})
+
+	t.Run("returns found false when file lacks tool instead of reading other files", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(forcedFile, []byte("ruby 2.0.0\n"), 0o666))
+
+		_, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		results, err := AllVersions(conf, []plugins.Plugin{plugin}, currentDir)
+		assert.Nil(t, err)
+		assert.False(t, results[0].Found)
+	})
+
+	t.Run("returns version from file regardless of directory", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(forcedFile, []byte("lua 2.0.0\n"), 0o666))
+
+		toolVersion, found, trace, err := Explain(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"2.0.0"}, toolVersion.Versions)
+		assert.Equal(t, filepath.Dir(forcedFile), toolVersion.Directory)
+		assert.Equal(t, "ASDF_TOOL_VERSIONS_FILE is set to "+forcedFile+", so no other version files are read", trace.Reason)
+
+		results, err := AllVersions(conf, []plugins.Plugin{plugin}, currentDir)
+		assert.Nil(t, err)
+		assert.Equal(t, toolVersion, results[0].Versions)
+	})
+
+	t.Run("returns version from env before file", func(t *testing.T) {
+		t.Setenv("ASDF_LUA_VERSION", "3.0.0")
+
+		toolVersion, found, err := Version(conf, plugin, currentDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"3.0.0"}, toolVersion.Versions)
+	})
+}
+
+func TestExplain(t *testing.T) {
+	conf := config.Config{DataDir: t.TempDir(), DefaultToolVersionsFilename: ".tool-versions", ConfigFile: "testdata/asdfrc"}
+	_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, "lua")
+	assert.Nil(t, err)
+	plugin := plugins.New(conf, "lua")
+
+	currentDir := t.TempDir()
+	subDir := filepath.Join(currentDir, "subdir")
+	err = os.MkdirAll(subDir, 0o777)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte("lua 1.2.3"), 0o666)
+	assert.Nil(t, err)
+
+	t.Run("records every place consulted and the winner", func(t *testing.T) {
+		toolVersion, found, trace, err := Explain(conf, plugin, subDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"1.2.3"}, toolVersion.Versions)
+
+		assert.Contains(t, trace.Steps, Step{Source: "ASDF_LUA_VERSION", Result: "not set"})
+		assert.Contains(t, trace.Steps, Step{Source: "list-legacy-filenames callback", Result: ".dummy-version .dummyrc"})
+		assert.Contains(t, trace.Steps, Step{Source: filepath.Join(subDir, ".dummy-version"), Result: "not found"})
+		assert.Contains(t, trace.Steps, Step{Source: filepath.Join(subDir, ".tool-versions"), Result: "not found"})
+		assert.Equal(t, Step{Source: filepath.Join(currentDir, ".tool-versions"), Result: "found 1.2.3"}, trace.Steps[len(trace.Steps)-1])
+		assert.Contains(t, trace.Reason, filepath.Join(currentDir, ".tool-versions")+" is the first file setting lua")
+	})
+
+	t.Run("explains that environment variable takes precedence", func(t *testing.T) {
+		t.Setenv("ASDF_LUA_VERSION", "2.3.4")
+
+		_, found, trace, err := Explain(conf, plugin, subDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []Step{{Source: "ASDF_LUA_VERSION", Result: "set to 2.3.4"}}, trace.Steps)
+		assert.Equal(t, "ASDF_LUA_VERSION is set, which takes precedence over version files", trace.Reason)
+	})
 }
 
 func TestFindVersionsInDir(t *testing.T) {
@@ -79,7 +307,7 @@ func TestFindVersionsInDir(t *testing.T) {
 	t.Run("when no versions set returns found false", func(t *testing.T) {
 		currentDir := t.TempDir()
 
-		versions, found, err := findVersionsInDir(conf, plugin, currentDir)
+		versions, found, err := findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
 
 		assert.Empty(t, versions)
 		assert.False(t, found)
@@ -92,7 +320,7 @@ func TestFindVersionsInDir(t *testing.T) {
 		data := []byte("lua 1.2.3")
 		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), data, 0o666)
 
-		toolVersion, found, err := findVersionsInDir(conf, plugin, currentDir)
+		toolVersion, found, err := findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
 
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3"})
 		assert.True(t, found)
@@ -105,7 +333,7 @@ func TestFindVersionsInDir(t *testing.T) {
 		data := []byte("lua 1.2.3 2.3.4")
 		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), data, 0o666)
 
-		toolVersion, found, err := findVersionsInDir(conf, plugin, currentDir)
+		toolVersion, found, err := findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
 
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3", "2.3.4"})
 		assert.True(t, found)
@@ -119,20 +347,53 @@ func TestFindVersionsInDir(t *testing.T) {
 		data := []byte("lua 1.2.3 2.3.4")
 		err = os.WriteFile(filepath.Join(currentDir, "custom-file"), data, 0o666)
 
-		toolVersion, found, err := findVersionsInDir(conf, plugin, currentDir)
+		toolVersion, found, err := findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
 
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3", "2.3.4"})
 		assert.True(t, found)
 		assert.Nil(t, err)
 	})
 
+	t.Run("when several filenames are set reports the first file with an entry for the tool", func(t *testing.T) {
+		conf := config.Config{DataDir: testDataDir, DefaultToolVersionsFilename: ".tool-versions.local:.tool-versions"}
+		currentDir := t.TempDir()
+
+		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte("lua 1.2.3\n"), 0o666)
+		assert.Nil(t, err)
+
+		toolVersion, found, err := findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"1.2.3"}, toolVersion.Versions)
+		assert.Equal(t, ".tool-versions", toolVersion.Source)
+
+		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions.local"), []byte("ruby 2.0.0\n"), 0o666)
+		assert.Nil(t, err)
+
+		toolVersion, found, err = findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, ".tool-versions", toolVersion.Source)
+
+		err = os.WriteFile(filepath.Join(currentDir, ".tool-versions.local"), []byte("lua 2.0.0\n"), 0o666)
+		assert.Nil(t, err)
+
+		toolVersion, found, err = findVersionsInDir(conf, plugin, legacyFiles{}, currentDir, nil)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"2.0.0"}, toolVersion.Versions)
+		assert.Equal(t, ".tool-versions.local", toolVersion.Source)
+	})
+
 	t.Run("when legacy file support is on looks up version in legacy file", func(t *testing.T) {
 		currentDir := t.TempDir()
 
 		data := []byte("1.2.3 2.3.4")
 		err = os.WriteFile(filepath.Join(currentDir, ".dummy-version"), data, 0o666)
 
-		toolVersion, found, err := findVersionsInDir(conf, plugin, currentDir)
+		legacy, err := findLegacyFiles(conf, plugin, nil)
+		assert.Nil(t, err)
+		toolVersion, found, err := findVersionsInDir(conf, plugin, legacy, currentDir, nil)
 
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3", "2.3.4"})
 		assert.True(t, found)
@@ -152,14 +413,18 @@ func TestFindVersionsLegacyFiles(t *testing.T) {
 		_, err := repotest.InstallPlugin("dummy_plugin_no_download", conf.DataDir, pluginName)
 		assert.Nil(t, err)
 		plugin := plugins.New(conf, pluginName)
-		toolVersion, found, err := findVersionsInLegacyFile(plugin, t.TempDir())
+		legacy, err := findLegacyFiles(conf, plugin, nil)
+		assert.Nil(t, err)
+		toolVersion, found, err := findVersionsInLegacyFile(plugin, legacy, t.TempDir(), nil)
 		assert.Empty(t, toolVersion.Versions)
 		assert.False(t, found)
 		assert.Nil(t, err)
 	})
 
 	t.Run("when given tool that has a list-legacy-filenames callback but file not found returns empty versions list", func(t *testing.T) {
-		toolVersion, found, err := findVersionsInLegacyFile(plugin, t.TempDir())
+		legacy, err := findLegacyFiles(conf, plugin, nil)
+		assert.Nil(t, err)
+		toolVersion, found, err := findVersionsInLegacyFile(plugin, legacy, t.TempDir(), nil)
 		assert.Empty(t, toolVersion.Versions)
 		assert.False(t, found)
 		assert.Nil(t, err)
@@ -172,11 +437,45 @@ func TestFindVersionsLegacyFiles(t *testing.T) {
 		err = os.WriteFile(filepath.Join(currentDir, ".dummy-version"), data, 0o666)
 		assert.Nil(t, err)
 
-		toolVersion, found, err := findVersionsInLegacyFile(plugin, currentDir)
+		legacy, err := findLegacyFiles(conf, plugin, nil)
+		assert.Nil(t, err)
+		toolVersion, found, err := findVersionsInLegacyFile(plugin, legacy, currentDir, nil)
 		assert.Equal(t, toolVersion.Versions, []string{"1.2.3"})
 		assert.True(t, found)
 		assert.Nil(t, err)
 	})
+
+	t.Run("parses files mapped to the tool without plugin callbacks", func(t *testing.T) {
+		pluginName := "nodejs"
+		_, err := repotest.InstallPlugin("dummy_plugin_no_download", conf.DataDir, pluginName)
+		assert.Nil(t, err)
+		plugin := plugins.New(conf, pluginName)
+
+		currentDir := t.TempDir()
+		err = os.WriteFile(filepath.Join(currentDir, ".nvmrc"), []byte("v20.11.1\n"), 0o666)
+		assert.Nil(t, err)
+
+		legacy, err := findLegacyFiles(conf, plugin, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{".node-version", ".nvmrc"}, legacy.native)
+
+		toolVersion, found, err := findVersionsInLegacyFile(plugin, legacy, currentDir, nil)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []string{"20.11.1"}, toolVersion.Versions)
+		assert.Equal(t, ".nvmrc", toolVersion.Source)
+	})
+
+	t.Run("leaves files listed by the plugin to the plugin", func(t *testing.T) {
+		conf := config.Config{DataDir: testDataDir, ConfigFile: filepath.Join(t.TempDir(), "asdfrc")}
+		err := os.WriteFile(conf.ConfigFile, []byte("[legacy_version_files]\n.dummy-version = lua\n.nvmrc = lua\n"), 0o666)
+		assert.Nil(t, err)
+
+		legacy, err := findLegacyFiles(conf, plugin, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{".dummy-version", ".dummyrc"}, legacy.plugin)
+		assert.Equal(t, []string{".nvmrc"}, legacy.native)
+	})
 }
 
 func TestFindVersionsInEnv(t *testing.T) {
@@ -232,3 +531,96 @@ func TestVariableVersionName(t *testing.T) {
 		})
 	}
 }
+
+func TestAllVersions(t *testing.T) {
+	conf := config.Config{
+		DataDir:                     t.TempDir(),
+		DefaultToolVersionsFilename: ".tool-versions.local:.tool-versions",
+		ConfigFile:                  "testdata/asdfrc",
+		GlobalToolVersionsFile:      filepath.Join(t.TempDir(), ".tool-versions"),
+	}
+	allPlugins := []plugins.Plugin{}
+	for _, name := range []string{"lua", "ruby", "elixir", "erlang", "python"} {
+		_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, name)
+		assert.Nil(t, err)
+		allPlugins = append(allPlugins, plugins.New(conf, name))
+	}
+
+	rootDir := t.TempDir()
+	subDir := filepath.Join(rootDir, "a", "b")
+	assert.Nil(t, os.MkdirAll(subDir, 0o777))
+	assert.Nil(t, os.WriteFile(filepath.Join(rootDir, ".tool-versions"), []byte("lua 1.0.0\nruby 2.0.0\n"), 0o666))
+	assert.Nil(t, os.WriteFile(filepath.Join(rootDir, "a", ".tool-versions"), []byte("lua 1.1.0\n"), 0o666))
+	assert.Nil(t, os.WriteFile(filepath.Join(rootDir, "a", ".tool-versions.local"), []byte("ruby 2.1.0\n"), 0o666))
+	assert.Nil(t, os.WriteFile(conf.GlobalToolVersionsFile, []byte("lua 5.0.0\npython 3.0.0\n"), 0o666))
+	t.Setenv("ASDF_ELIXIR_VERSION", "4.0.0")
+
+	results, err := AllVersions(conf, allPlugins, subDir)
+	assert.Nil(t, err)
+	assert.Len(t, results, len(allPlugins))
+
+	for i, plugin := range allPlugins {
+		versions, found, err := Version(conf, plugin, subDir)
+		assert.Equal(t, plugin, results[i].Plugin)
+		assert.Equal(t, versions, results[i].Versions, plugin.Name)
+		assert.Equal(t, found, results[i].Found, plugin.Name)
+		assert.Equal(t, err, results[i].Err, plugin.Name)
+	}
+
+	assert.Equal(t, []string{"1.1.0"}, results[0].Versions.Versions)
+	assert.Equal(t, filepath.Join(rootDir, "a"), results[0].Versions.Directory)
+	assert.Equal(t, []string{"2.1.0"}, results[1].Versions.Versions)
+	assert.Equal(t, ".tool-versions.local", results[1].Versions.Source)
+	assert.Equal(t, "ASDF_ELIXIR_VERSION", results[2].Versions.Source)
+	assert.False(t, results[3].Found)
+	assert.Equal(t, []string{"3.0.0"}, results[4].Versions.Versions)
+	assert.Equal(t, filepath.Dir(conf.GlobalToolVersionsFile), results[4].Versions.Directory)
+}
+
+// benchmarkTree installs pluginCount plugins and returns a directory depth
+// levels below a .tool-versions file listing all of them
+func benchmarkTree(b *testing.B, pluginCount, depth int) (config.Config, []plugins.Plugin, string) {
+	conf := config.Config{DataDir: b.TempDir(), DefaultToolVersionsFilename: ".tool-versions", ConfigFile: filepath.Join(b.TempDir(), "asdfrc")}
+	rootDir := b.TempDir()
+
+	allPlugins := []plugins.Plugin{}
+	var toolVersions strings.Builder
+	for i := 0; i < pluginCount; i++ {
+		name := fmt.Sprintf("tool%d", i)
+		_, err := repotest.InstallPlugin("dummy_plugin", conf.DataDir, name)
+		assert.Nil(b, err)
+		allPlugins = append(allPlugins, plugins.New(conf, name))
+		fmt.Fprintf(&toolVersions, "%s 1.0.0\n", name)
+	}
+	assert.Nil(b, os.WriteFile(filepath.Join(rootDir, ".tool-versions"), []byte(toolVersions.String()), 0o666))
+
+	directory := rootDir
+	for i := 0; i < depth; i++ {
+		directory = filepath.Join(directory, fmt.Sprintf("level%d", i))
+	}
+	assert.Nil(b, os.MkdirAll(directory, 0o777))
+
+	return conf, allPlugins, directory
+}
+
+func BenchmarkVersionPerPlugin(b *testing.B) {
+	conf, allPlugins, directory := benchmarkTree(b, 20, 10)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for _, plugin := range allPlugins {
+			_, _, err := Version(conf, plugin, directory)
+			assert.Nil(b, err)
+		}
+	}
+}
+
+func BenchmarkAllVersions(b *testing.B) {
+	conf, allPlugins, directory := benchmarkTree(b, 20, 10)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_, err := AllVersions(conf, allPlugins, directory)
+		assert.Nil(b, err)
+	}
+}
@@ -0,0 +1,73 @@
+package resolve
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/plugins"
+)
+
+// Trace records how a tool's version was resolved
+type Trace struct {
+	// Steps are every environment variable, file and callback consulted, in
+	// the order they were consulted
+	Steps []Step
+	// Reason explains why the winning versions were picked
+	Reason string
+}
+
+// Step is a single place a version was looked up in
+type Step struct {
+	// Source is what was consulted, like an environment variable, a file or a
+	// callback
+	Source string
+	// Result describes what was found there
+	Result string
+}
+
+// Explain resolves the tool like Version does and also returns a trace of every
+// place consulted along the way
+func Explain(conf config.Config, plugin plugins.Plugin, directory string) (versions ToolVersions, found bool, trace Trace, err error) {
+	versions, found, err = findVersions(conf, plugin, directory, &trace)
+	if found && err == nil {
+		versions = resolveInstalled(conf, plugin, versions, &trace)
+	}
+
+	switch {
+	case err != nil:
+		trace.Reason = fmt.Sprintf("resolution failed: %s", err)
+	case !found && conf.ToolVersionsFile != "":
+		trace.Reason = fmt.Sprintf("no version is set for %s in the environment or in ASDF_TOOL_VERSIONS_FILE %s", plugin.Name, conf.ToolVersionsFile)
+	case !found:
+		trace.Reason = fmt.Sprintf("no version is set for %s in the environment or in any version file from %s up to the root directory", plugin.Name, directory)
+	case versions.Directory == "":
+		trace.Reason = fmt.Sprintf("%s is set, which takes precedence over version files", versions.Source)
+	case conf.ToolVersionsFile != "":
+		trace.Reason = fmt.Sprintf("ASDF_TOOL_VERSIONS_FILE is set to %s, so no other version files are read", conf.ToolVersionsFile)
+	case isFallback(conf, versions, directory):
+		trace.Reason = fmt.Sprintf("%s is a fallback file, used as no version file from %s up to the root directory sets %s", path.Join(versions.Directory, versions.Source), directory, plugin.Name)
+	default:
+		trace.Reason = fmt.Sprintf("%s is the first file setting %s found searching from %s up to the root directory", path.Join(versions.Directory, versions.Source), plugin.Name, directory)
+	}
+
+	return versions, found, trace, err
+}
+
+// add records a step, doing nothing for a nil trace so resolution without
+// tracing doesn't need to check
+func (t *Trace) add(source, format string, args ...any) {
+	if t == nil {
+		return
+	}
+
+	t.Steps = append(t.Steps, Step{Source: source, Result: fmt.Sprintf(format, args...)})
+}
+
+// isFallback returns true if the versions were read from the global or
+// system-wide .tool-versions file after searching up from the directory
+func isFallback(conf config.Config, versions ToolVersions, directory string) bool {
+	filepath := path.Join(versions.Directory, versions.Source)
+	return slices.Contains(conf.FallbackToolVersionsFiles(), filepath) && !walked(conf, directory, filepath)
+}
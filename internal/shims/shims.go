@@ -53,9 +53,43 @@ func (e NoExecutableForPluginError) Error() string {
 	return fmt.Sprintf("No %s executable found for %s %s", e.shim, strings.Join(e.tools, ", "), strings.Join(e.versions, ", "))
 }
 
+// Explanation records how FindExecutable picked the executable for a shim
+type Explanation struct {
+	// Tools are the tools the shim was generated for, in the order listed in
+	// the shim
+	Tools []ToolExplanation
+}
+
+// ToolExplanation records how the versions of one of the tools a shim was
+// generated for were narrowed down
+type ToolExplanation struct {
+	Name string
+	// ShimVersions are the versions of the tool the shim has an executable for
+	ShimVersions []string
+	// Versions are the versions the tool resolved to in the directory
+	Versions resolve.ToolVersions
+	Found    bool
+	Trace    resolve.Trace
+	// Candidates are the resolved versions that remain after intersecting them
+	// with ShimVersions. These are tried in order.
+	Candidates []string
+}
+
 // FindExecutable takes a shim name and a current directory and returns the path
 // to the executable that the shim resolves to.
 func FindExecutable(conf config.Config, shimName, currentDirectory string) (string, plugins.Plugin, string, bool, error) {
+	return findExecutable(conf, shimName, currentDirectory, nil)
+}
+
+// ExplainExecutable finds the executable like FindExecutable does and also
+// returns how the version of each tool the shim belongs to was resolved
+func ExplainExecutable(conf config.Config, shimName, currentDirectory string) (string, Explanation, error) {
+	explanation := Explanation{}
+	executable, _, _, _, err := findExecutable(conf, shimName, currentDirectory, &explanation)
+	return executable, explanation, err
+}
+
+func findExecutable(conf config.Config, shimName, currentDirectory string, explanation *Explanation) (string, plugins.Plugin, string, bool, error) {
 	shimPath := Path(conf, shimName)
 
 	if _, err := os.Stat(shimPath); err != nil {
@@ -70,31 +104,47 @@ func FindExecutable(conf config.Config, shimName, currentDirectory string) (stri
 	existingPluginToolVersions := make(map[plugins.Plugin]resolve.ToolVersions)
 
 	// loop over tools and check if the plugin for them still exists
+	shimPlugins := []plugins.Plugin{}
+	shimVersions := [][]string{}
 	for _, shimToolVersion := range toolVersions {
 		plugin := plugins.New(conf, shimToolVersion.Name)
 		if plugin.Exists() == nil {
+			shimPlugins = append(shimPlugins, plugin)
+			shimVersions = append(shimVersions, shimToolVersion.Versions)
+		}
+	}
 
-			versions, found, err := resolve.Version(conf, plugin, currentDirectory)
-			if err != nil {
-				return "", plugins.Plugin{}, "", false, nil
+	results, err := resolveVersions(conf, shimPlugins, currentDirectory, explanation)
+	if err != nil {
+		return "", plugins.Plugin{}, "", false, nil
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			return "", plugins.Plugin{}, "", false, nil
+		}
+
+		plugin, versions := result.Plugin, result.Versions
+		if result.Found {
+			tempVersions := toolversions.Intersect(shimVersions[i], versions.Versions)
+			if slices.Contains(versions.Versions, "system") {
+				tempVersions = append(tempVersions, "system")
 			}
 
-			if found {
-				tempVersions := toolversions.Intersect(shimToolVersion.Versions, versions.Versions)
-				if slices.Contains(versions.Versions, "system") {
-					tempVersions = append(tempVersions, "system")
+			parsedVersions := toolversions.ParseSlice(versions.Versions)
+			for _, parsedVersion := range parsedVersions {
+				if parsedVersion.Type == "path" {
+					tempVersions = append(tempVersions, toolversions.Format(parsedVersion))
 				}
+			}
 
-				parsedVersions := toolversions.ParseSlice(versions.Versions)
-				for _, parsedVersion := range parsedVersions {
-					if parsedVersion.Type == "path" {
-						tempVersions = append(tempVersions, toolversions.Format(parsedVersion))
-					}
-				}
+			versions.Versions = tempVersions
+			existingPluginToolVersions[plugin] = versions
+		}
 
-				versions.Versions = tempVersions
-				existingPluginToolVersions[plugin] = versions
-			}
+		if explanation != nil {
+			explanation.Tools[i].ShimVersions = shimVersions[i]
+			explanation.Tools[i].Candidates = versions.Versions
 		}
 	}
 
@@ -139,6 +189,23 @@ func FindExecutable(conf config.Config, shimName, currentDirectory string) (stri
 	return "", plugins.Plugin{}, "", false, NoExecutableForPluginError{shim: shimName, tools: tools, versions: versions}
 }
 
+// resolveVersions resolves the versions of all the tools at once, or one by one
+// with tracing if the executable is being explained
+func resolveVersions(conf config.Config, allPlugins []plugins.Plugin, directory string, explanation *Explanation) ([]resolve.Result, error) {
+	if explanation == nil {
+		return resolve.AllVersions(conf, allPlugins, directory)
+	}
+
+	results := []resolve.Result{}
+	for _, plugin := range allPlugins {
+		versions, found, trace, err := resolve.Explain(conf, plugin, directory)
+		results = append(results, resolve.Result{Plugin: plugin, Versions: versions, Found: found, Err: err})
+		explanation.Tools = append(explanation.Tools, ToolExplanation{Name: plugin.Name, Versions: versions, Found: found, Trace: trace})
+	}
+
+	return results, nil
+}
+
 // SystemExecutableOnPath returns the path to the system executable if found,
 // removes asdf shim directory from search
 func SystemExecutableOnPath(conf config.Config, executableName string) (string, bool) {
@@ -200,9 +267,6 @@ func GetToolsAndVersionsFromShimFile(shimPath string) (versions []toolversions.T
 }
 
 func getCustomExecutablePath(conf config.Config, plugin plugins.Plugin, shimName string, version toolversions.Version, executablePath string) (string, error) {
-	var stdOut strings.Builder
-	var stdErr strings.Builder
-
 	installPath := installs.InstallPath(conf, plugin, version)
 	env := map[string]string{"ASDF_INSTALL_TYPE": "version"}
 
@@ -211,12 +275,12 @@ func getCustomExecutablePath(conf config.Config, plugin plugins.Plugin, shimName
 		return "", err
 	}
 
-	err = plugin.RunCallback("exec-path", []string{installPath, shimName, relativePath}, env, &stdOut, &stdErr)
+	execPath, err := plugin.ExecPath(env, installPath, shimName, relativePath)
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(installPath, strings.TrimSpace(stdOut.String())), err
+	return filepath.Join(installPath, execPath), err
 }
 
 // RemoveAll removes all shim scripts
@@ -370,29 +434,8 @@ func ExecutablePaths(conf config.Config, plugin plugins.Plugin, version toolvers
 
 // ExecutableDirs returns a slice of directory names that tool executables are
 // contained in
-func ExecutableDirs(plugin plugins.Plugin) ([]string, error) {
-	var stdOut strings.Builder
-	var stdErr strings.Builder
-
-	err := plugin.RunCallback("list-bin-paths", []string{}, map[string]string{}, &stdOut, &stdErr)
-	if err != nil {
-		if _, ok := err.(plugins.NoCallbackError); ok {
-			// assume all executables are located in /bin directory
-			return []string{"bin"}, nil
-		}
-
-		return []string{}, err
-	}
-
-	// use output from list-bin-paths to determine locations for executables
-	rawDirs := strings.Split(stdOut.String(), " ")
-	var dirs []string
-
-	for _, dir := range rawDirs {
-		dirs = append(dirs, strings.TrimSpace(dir))
-	}
-
-	return dirs, nil
+func ExecutableDirs(backend plugins.Backend) ([]string, error) {
+	return backend.BinPaths(map[string]string{})
 }
 
 func parse(contents string) (versions []toolversions.ToolVersions) {
@@ -98,6 +98,45 @@ func TestFindExecutable(t *testing.T) {
 		assert.Equal(t, filepath.Base(executable), "dummy")
 		assert.True(t, strings.HasPrefix(executable, dir))
 	})
+
+	t.Run("returns path to executable when relative path version set in parent directory", func(t *testing.T) {
+		dir := installs.InstallPath(conf, plugin, toolversions.Version{Type: "version", Value: "1.1.0"})
+		relative, err := filepath.Rel(currentDir, dir)
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte(fmt.Sprintf("lua path:%s\n", relative)), 0o666))
+
+		subDir := filepath.Join(currentDir, "subdir")
+		assert.Nil(t, os.MkdirAll(subDir, 0o777))
+
+		executable, _, version, found, err := FindExecutable(conf, "dummy", subDir)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "path:"+dir, version)
+		assert.Equal(t, filepath.Join(dir, "bin", "dummy"), executable)
+	})
+}
+
+func TestExplainExecutable(t *testing.T) {
+	conf, plugin := generateConfig(t)
+	installVersion(t, conf, plugin, "1.0.0")
+	installVersion(t, conf, plugin, "1.1.0")
+	stdout, stderr := buildOutputs()
+	assert.Nil(t, GenerateAll(conf, &stdout, &stderr))
+	currentDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(currentDir, ".tool-versions"), []byte("lua 2.0.0 1.1.0"), 0o666))
+
+	executable, explanation, err := ExplainExecutable(conf, "dummy", currentDir)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.1.0", filepath.Base(filepath.Dir(filepath.Dir(executable))))
+
+	assert.Len(t, explanation.Tools, 1)
+	tool := explanation.Tools[0]
+	assert.Equal(t, testPluginName, tool.Name)
+	assert.ElementsMatch(t, []string{"1.0.0", "1.1.0"}, tool.ShimVersions)
+	assert.Equal(t, []string{"2.0.0", "1.1.0"}, tool.Versions.Versions)
+	assert.Equal(t, []string{"1.1.0"}, tool.Candidates)
+	assert.True(t, tool.Found)
+	assert.NotEmpty(t, tool.Trace.Steps)
 }
 
 func TestGetExecutablePath(t *testing.T) {
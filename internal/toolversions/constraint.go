@@ -0,0 +1,242 @@
+package toolversions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/versioncmp"
+)
+
+// constraintType is the version type of version constraints like `~3.12` or
+// `>=1.6 <1.8`
+const constraintType = "constraint"
+
+// prefixPattern matches versions that are treated as a prefix when no version
+// with exactly that name exists, like `20` or `3.12`
+var prefixPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// operators supported in constraints, longest first so `>=` isn't parsed as
+// `>`
+var operators = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+// rangeOperators are the operators whose clauses are combined into a single
+// constraint when written next to each other, like `>=1.6 <1.8`
+var rangeOperators = []string{">", "<", "!="}
+
+// Constraint matches versions against a version constraint or prefix. A
+// constraint is made of one or more clauses that must all match.
+type Constraint struct {
+	raw     string
+	clauses []clause
+}
+
+type clause struct {
+	operator string
+	segments []int
+}
+
+// IsConstraint returns true if the version is a constraint rather than an
+// exact version, because it starts with an operator
+func IsConstraint(version string) bool {
+	return operator(version) != ""
+}
+
+// IsPrefix returns true if the version may be a prefix of other versions, like
+// `20` or `3.12`. Prefixes are only used when no version with exactly that name
+// exists.
+func IsPrefix(version string) bool {
+	return prefixPattern.MatchString(version)
+}
+
+// ParseConstraint parses a constraint or a prefix. Clauses are separated by
+// spaces or commas and an operator may be separated from its version by a
+// space.
+func ParseConstraint(value string) (Constraint, error) {
+	constraint := Constraint{raw: value}
+
+	fields := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if field == operator(field) && i+1 < len(fields) {
+			field += fields[i+1]
+			i++
+		}
+
+		op := operator(field)
+		segments, ok := numericSegments(strings.TrimPrefix(field, op))
+		if !ok {
+			return constraint, fmt.Errorf("invalid version constraint %s", value)
+		}
+
+		constraint.clauses = append(constraint.clauses, clause{operator: op, segments: segments})
+	}
+
+	if len(constraint.clauses) == 0 {
+		return constraint, fmt.Errorf("invalid version constraint %s", value)
+	}
+
+	return constraint, nil
+}
+
+// String returns the constraint as it was written
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Match returns true if the version satisfies every clause of the constraint.
+// Only versions made of numeric segments can match, so pre-releases like
+// `3.13.0rc1` and other kinds of versions never do.
+func (c Constraint) Match(version string) bool {
+	segments, ok := numericSegments(version)
+	if !ok {
+		return false
+	}
+
+	for _, clause := range c.clauses {
+		if !clause.match(segments) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Highest returns the highest of the versions matching the constraint
+func (c Constraint) Highest(versions []string) (string, bool) {
+	highest := ""
+	for _, version := range versions {
+		if c.Match(version) && (highest == "" || versioncmp.Compare(version, highest) > 0) {
+			highest = version
+		}
+	}
+
+	return highest, highest != ""
+}
+
+func (c clause) match(segments []int) bool {
+	comparison := compareSegments(segments, c.segments)
+
+	switch c.operator {
+	case "":
+		return len(segments) >= len(c.segments) && compareSegments(segments[:len(c.segments)], c.segments) == 0
+	case "=":
+		return comparison == 0
+	case "!=":
+		return comparison != 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case "~":
+		// ~1.2.3 and ~1.2 allow changes to the last segment, ~1 to everything
+		// but the major version
+		index := len(c.segments) - 1
+		if len(c.segments) > 2 {
+			index = len(c.segments) - 2
+		}
+		return comparison >= 0 && compareSegments(segments, bump(c.segments, index)) < 0
+	case "^":
+		// ^1.2.3 allows changes that don't modify the first non-zero segment
+		index := 0
+		for index < len(c.segments)-1 && c.segments[index] == 0 {
+			index++
+		}
+		return comparison >= 0 && compareSegments(segments, bump(c.segments, index)) < 0
+	}
+
+	return false
+}
+
+// bump increments the segment at index and drops the segments after it
+func bump(segments []int, index int) []int {
+	bumped := append([]int{}, segments[:index+1]...)
+	bumped[index]++
+	return bumped
+}
+
+// compareSegments compares two versions segment by segment, treating missing
+// segments as zero
+func compareSegments(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func numericSegments(version string) ([]int, bool) {
+	if version == "" {
+		return nil, false
+	}
+
+	segments := []int{}
+	for _, segment := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(segment)
+		if err != nil || number < 0 {
+			return nil, false
+		}
+
+		segments = append(segments, number)
+	}
+
+	return segments, true
+}
+
+func operator(version string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(version, op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+// groupConstraints joins the clauses of range constraints written next to
+// each other, like `>=1.6 <1.8`, into a single version. An operator written
+// apart from its version is joined with it too.
+func groupConstraints(tokens []string) (versions []string) {
+	for _, token := range tokens {
+		if len(versions) > 0 {
+			last := versions[len(versions)-1]
+			lastField := last[strings.LastIndex(last, " ")+1:]
+			if lastField == operator(lastField) || strings.HasSuffix(last, ",") || (isRange(last) && isRange(token)) {
+				versions[len(versions)-1] = last + " " + token
+				continue
+			}
+		}
+
+		versions = append(versions, token)
+	}
+
+	return versions
+}
+
+func isRange(version string) bool {
+	for _, op := range rangeOperators {
+		if strings.HasPrefix(version, op) {
+			return true
+		}
+	}
+
+	return false
+}
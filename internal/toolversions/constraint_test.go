@@ -0,0 +1,84 @@
+package toolversions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		match      bool
+	}{
+		{constraint: "20", version: "20.11.1", match: true},
+		{constraint: "20", version: "20", match: true},
+		{constraint: "20", version: "200.1.0", match: false},
+		{constraint: "3.12", version: "3.12.4", match: true},
+		{constraint: "3.12", version: "3.13.0", match: false},
+		{constraint: "~3.12", version: "3.12.9", match: true},
+		{constraint: "~3.12", version: "3.13.0", match: false},
+		{constraint: "~1.2.3", version: "1.2.9", match: true},
+		{constraint: "~1.2.3", version: "1.3.0", match: false},
+		{constraint: "~1", version: "1.9.0", match: true},
+		{constraint: "^1.2", version: "1.9.0", match: true},
+		{constraint: "^1.2", version: "2.0.0", match: false},
+		{constraint: "^0.2.3", version: "0.2.9", match: true},
+		{constraint: "^0.2.3", version: "0.3.0", match: false},
+		{constraint: ">=1.6 <1.8", version: "1.7.5", match: true},
+		{constraint: ">=1.6 <1.8", version: "1.8.0", match: false},
+		{constraint: ">=1.6 <1.8", version: "1.5.9", match: false},
+		{constraint: ">= 1.6, != 1.7.0", version: "1.7.0", match: false},
+		{constraint: "=1.6", version: "1.6.0", match: true},
+		{constraint: "<=2", version: "2.0.0", match: true},
+		{constraint: ">2", version: "2.0.0", match: false},
+		{constraint: ">=3.12", version: "3.13.0rc1", match: false},
+		{constraint: ">=1", version: "ref-abc123", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			constraint, err := ParseConstraint(tt.constraint)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.match, constraint.Match(tt.version))
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	t.Run("returns error for invalid constraint", func(t *testing.T) {
+		_, err := ParseConstraint(">=abc")
+		assert.EqualError(t, err, "invalid version constraint >=abc")
+	})
+
+	t.Run("returns error for empty constraint", func(t *testing.T) {
+		_, err := ParseConstraint("")
+		assert.EqualError(t, err, "invalid version constraint ")
+	})
+}
+
+func TestConstraintHighest(t *testing.T) {
+	versions := []string{"1.10.0", "1.6.2", "1.9.1", "2.0.0", "1.7.0-beta"}
+
+	constraint, err := ParseConstraint(">=1.6 <2")
+	assert.Nil(t, err)
+
+	highest, ok := constraint.Highest(versions)
+	assert.True(t, ok)
+	assert.Equal(t, "1.10.0", highest)
+
+	constraint, err = ParseConstraint("~3")
+	assert.Nil(t, err)
+
+	_, ok = constraint.Highest(versions)
+	assert.False(t, ok)
+}
+
+func TestIsPrefix(t *testing.T) {
+	assert.True(t, IsPrefix("20"))
+	assert.True(t, IsPrefix("3.12"))
+	assert.False(t, IsPrefix("1.2.3"))
+	assert.False(t, IsPrefix("~3.12"))
+	assert.False(t, IsPrefix("system"))
+}
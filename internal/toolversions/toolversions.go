@@ -6,13 +6,16 @@ package toolversions
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+
+	"github.com/mitchellh/go-homedir"
 )
 
 // Version struct represents a single version in asdf.
 type Version struct {
-	Type  string // Must be one of: version, ref, path, system, latest
+	Type  string // Must be one of: version, ref, path, system, latest, constraint
 	Value string // Any string
 }
 
@@ -119,6 +122,10 @@ func Parse(version string) Version {
 		return Version{Type: "system"}
 	}
 
+	if IsConstraint(version) {
+		return Version{Type: constraintType, Value: version}
+	}
+
 	return Version{Type: "version", Value: version}
 }
 
@@ -142,6 +149,37 @@ func Format(version Version) string {
 	}
 }
 
+// ExpandPath expands `~` and environment variables like `$HOME` in the path of
+// a path version, and resolves relative paths against the directory, which is
+// usually the directory of the file the version was read from. Relative paths
+// are resolved against the current directory if the directory is empty. Other
+// versions are returned unchanged.
+func ExpandPath(version Version, directory string) Version {
+	if version.Type != "path" || version.Value == "" {
+		return version
+	}
+
+	expanded, err := homedir.Expand(os.ExpandEnv(version.Value))
+	if err != nil {
+		// Paths like ~user/foo can't be expanded, leave them to fail later
+		expanded = os.ExpandEnv(version.Value)
+	}
+
+	if filepath.IsAbs(expanded) {
+		return Version{Type: "path", Value: expanded}
+	}
+
+	if directory == "" {
+		if absolute, err := filepath.Abs(expanded); err == nil {
+			expanded = absolute
+		}
+
+		return Version{Type: "path", Value: expanded}
+	}
+
+	return Version{Type: "path", Value: filepath.Join(directory, expanded)}
+}
+
 // FormatForFS takes a versionType and version strings and generate a version
 // string suitable for the file system
 func FormatForFS(version Version) string {
@@ -177,10 +215,16 @@ func findToolVersionsInContent(content, toolName string) (versions []string, fou
 	return versions, found
 }
 
+// SplitVersions splits a space separated list of versions, keeping the clauses
+// of range constraints like `>=1.6 <1.8` together
+func SplitVersions(versions string) []string {
+	return groupConstraints(parseLine(versions))
+}
+
 func getAllToolsAndVersionsInContent(content string) (toolVersions []ToolVersions) {
 	for _, line := range readLines(content) {
 		tokens := parseLine(line)
-		newTool := ToolVersions{Name: tokens[0], Versions: tokens[1:]}
+		newTool := ToolVersions{Name: tokens[0], Versions: groupConstraints(tokens[1:])}
 		toolVersions = append(toolVersions, newTool)
 	}
 
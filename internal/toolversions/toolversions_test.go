@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/mitchellh/go-homedir"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -148,6 +149,15 @@ func TestGetAllToolsAndVersionsInContent(t *testing.T) {
 				{Name: "ruby", Versions: []string{"2.0.0"}},
 			},
 		},
+		{
+			desc:  "keeps clauses of range constraints together",
+			input: "terraform >=1.6 <1.8 system\npython ~3.12 ~3.11\nnodejs >= 20, < 22",
+			want: []ToolVersions{
+				{Name: "terraform", Versions: []string{">=1.6 <1.8", "system"}},
+				{Name: "python", Versions: []string{"~3.12", "~3.11"}},
+				{Name: "nodejs", Versions: []string{">= 20, < 22"}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -186,6 +196,12 @@ func TestParse(t *testing.T) {
 		assert.Equal(t, version.Type, "system")
 		assert.Equal(t, version.Value, "")
 	})
+
+	t.Run("when passed constraint returns struct with type of 'constraint' and constraint as value", func(t *testing.T) {
+		version := Parse(">=1.6 <1.8")
+		assert.Equal(t, version.Type, "constraint")
+		assert.Equal(t, version.Value, ">=1.6 <1.8")
+	})
 }
 
 func TestParseFromCliArg(t *testing.T) {
@@ -279,6 +295,35 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestExpandPath(t *testing.T) {
+	home, err := homedir.Dir()
+	assert.Nil(t, err)
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	t.Setenv("ASDF_TEST_SRC", "/opt/src")
+
+	tests := []struct {
+		desc      string
+		input     Version
+		directory string
+		output    Version
+	}{
+		{desc: "leaves other versions unchanged", input: Version{Type: "version", Value: "../1.2.3"}, directory: "/project", output: Version{Type: "version", Value: "../1.2.3"}},
+		{desc: "leaves absolute path unchanged", input: Version{Type: "path", Value: "/opt/erlang"}, directory: "/project", output: Version{Type: "path", Value: "/opt/erlang"}},
+		{desc: "resolves relative path against directory", input: Version{Type: "path", Value: "../ruby-build/out"}, directory: "/project/app", output: Version{Type: "path", Value: "/project/ruby-build/out"}},
+		{desc: "resolves relative path against current directory when directory empty", input: Version{Type: "path", Value: "out"}, directory: "", output: Version{Type: "path", Value: filepath.Join(wd, "out")}},
+		{desc: "expands home directory", input: Version{Type: "path", Value: "~/src/erlang"}, directory: "/project", output: Version{Type: "path", Value: filepath.Join(home, "src", "erlang")}},
+		{desc: "expands environment variables", input: Version{Type: "path", Value: "$ASDF_TEST_SRC/erlang"}, directory: "/project", output: Version{Type: "path", Value: "/opt/src/erlang"}},
+		{desc: "expands braced environment variables in relative path", input: Version{Type: "path", Value: "build/${ASDF_TEST_UNSET}out"}, directory: "/project", output: Version{Type: "path", Value: "/project/build/out"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.output, ExpandPath(tt.input, tt.directory))
+		})
+	}
+}
+
 func TestFormatForFS(t *testing.T) {
 	t.Run("returns version when version type is not ref", func(t *testing.T) {
 		assert.Equal(t, FormatForFS(Version{Type: "version", Value: "foobar"}), "foobar")
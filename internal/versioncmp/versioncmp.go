@@ -0,0 +1,281 @@
+// Package versioncmp compares and sorts tool versions. Plugins return versions
+// in whatever format the tool uses, so comparison isn't limited to semantic
+// versions: calendar versions, any number of numeric segments, pre-release
+// tags like `-rc.1` or `a2` and prefixes like `v` or `jdk-` are supported.
+package versioncmp
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// prereleasePattern matches the suffix of pre-release versions, like `-rc.1`,
+// `-beta`, `a2` or `-SNAPSHOT`. Other suffixes, like the `-p551` of Ruby patch
+// releases, denote versions newer than the release without a suffix.
+var prereleasePattern = regexp.MustCompile(`(?i)^[-._]?(alpha|beta|rc|pre|preview|dev|snapshot|nightly|canary|next|milestone|ea|a|b|c|m)([-._]|\d|$)`)
+
+// prereleaseRanks orders the kinds of pre-releases, so a beta comes after an
+// alpha and a release candidate after a beta regardless of spelling
+var prereleaseRanks = map[string]int{
+	"dev": 0, "snapshot": 0, "nightly": 0, "canary": 0, "next": 0,
+	"alpha": 1, "a": 1, "ea": 1, "milestone": 1, "m": 1,
+	"beta": 2, "b": 2,
+	"rc": 3, "c": 3, "pre": 3, "preview": 3,
+}
+
+type version struct {
+	// prefix is everything before the first digit, like `jdk-`. A `v` prefix
+	// is dropped so `v1.2.0` and `1.2.0` compare equal.
+	prefix string
+	// segments are the numeric segments, as digits without leading zeros
+	segments []string
+	// suffix is everything after the numeric segments except build metadata
+	suffix     string
+	prerelease bool
+	// build is the build metadata after a `+`, like the `12` in `17.0.1+12`
+	build string
+}
+
+// Compare returns -1 if a is older than b, 1 if a is newer than b and 0 if they
+// are the same version. Versions are compared by prefix first, so versions of
+// different distributions of a tool, like `temurin-21.0.1` and `zulu-21.0.1`,
+// are grouped together. Versions without any digits, like `nightly`, are older
+// than every other version.
+func Compare(a, b string) int {
+	x, y := parse(a), parse(b)
+
+	if comparison := compareBool(len(x.segments) > 0, len(y.segments) > 0); comparison != 0 {
+		return comparison
+	}
+
+	if comparison := strings.Compare(x.prefix, y.prefix); comparison != 0 {
+		return comparison
+	}
+
+	if comparison := compareSegments(x.segments, y.segments); comparison != 0 {
+		return comparison
+	}
+
+	if comparison := compareSuffixes(x, y); comparison != 0 {
+		return comparison
+	}
+
+	if comparison := compareBool(x.build != "", y.build != ""); comparison != 0 {
+		return comparison
+	}
+
+	if comparison := compareNatural(x.build, y.build); comparison != 0 {
+		return comparison
+	}
+
+	// Different spellings of the same version, like `1.0` and `1.0.0`, are
+	// still ordered so sorting is deterministic
+	return strings.Compare(a, b)
+}
+
+// Sort sorts the versions from oldest to newest
+func Sort(versions []string) {
+	slices.SortStableFunc(versions, Compare)
+}
+
+// IsPrerelease returns true if the version is a pre-release, like `2.0.0-rc1`,
+// `3.13.0a2` or `1.1.0-SNAPSHOT`
+func IsPrerelease(v string) bool {
+	return parse(v).prerelease
+}
+
+// Prefix returns the prefix of the version, everything before the first digit
+// like the `jdk-` of `jdk-21.0.1`. A `v` prefix isn't considered a prefix, and
+// the prefix of a version without any digits is the whole version.
+func Prefix(v string) string {
+	return parse(v).prefix
+}
+
+func parse(raw string) version {
+	v := version{}
+
+	rest := raw
+	if index := strings.Index(rest, "+"); index != -1 {
+		rest, v.build = rest[:index], rest[index+1:]
+	}
+
+	start := strings.IndexAny(rest, "0123456789")
+	if start == -1 {
+		v.prefix = rest
+		v.prerelease = prereleasePattern.MatchString(rest)
+		return v
+	}
+
+	v.prefix, rest = rest[:start], rest[start:]
+	if v.prefix == "v" || v.prefix == "V" {
+		v.prefix = ""
+	}
+
+	dotted := false
+	for {
+		digits := leadingDigits(rest)
+		v.segments = append(v.segments, trimZeros(digits))
+		rest = rest[len(digits):]
+
+		if len(rest) < 2 || !isDigit(rest[1]) {
+			break
+		}
+
+		// Segments are usually separated by dots. Calendar versions may
+		// separate them with dashes instead, like `2024-01-15`, but a dash
+		// after dotted segments starts a suffix, like the `-1` of `1.0.0-1`.
+		separator := rest[0]
+		if !strings.ContainsRune("._-", rune(separator)) || (separator == '-' && dotted) {
+			break
+		}
+
+		dotted = dotted || separator == '.'
+		rest = rest[1:]
+	}
+
+	v.suffix = rest
+	v.prerelease = prereleasePattern.MatchString(rest)
+	return v
+}
+
+// compareSuffixes orders pre-releases before the release, and the release
+// before versions with other suffixes
+func compareSuffixes(x, y version) int {
+	if comparison := sign(suffixRank(x) - suffixRank(y)); comparison != 0 {
+		return comparison
+	}
+
+	if x.prerelease {
+		kind := prereleaseRanks[prereleaseKind(x.suffix)] - prereleaseRanks[prereleaseKind(y.suffix)]
+		if comparison := sign(kind); comparison != 0 {
+			return comparison
+		}
+	}
+
+	return compareNatural(strings.TrimLeft(x.suffix, "-._"), strings.TrimLeft(y.suffix, "-._"))
+}
+
+func suffixRank(v version) int {
+	switch {
+	case v.prerelease:
+		return 0
+	case v.suffix == "":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func prereleaseKind(suffix string) string {
+	match := prereleasePattern.FindStringSubmatch(suffix)
+	if match == nil {
+		return ""
+	}
+
+	return strings.ToLower(match[1])
+}
+
+// compareSegments compares numeric segments one by one, treating missing
+// segments as zero
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := "", ""
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if comparison := compareNumbers(x, y); comparison != 0 {
+			return comparison
+		}
+	}
+
+	return 0
+}
+
+// compareNatural compares strings so runs of digits are compared as numbers,
+// making `rc2` older than `rc10`
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		var x, y string
+		if isDigit(a[0]) && isDigit(b[0]) {
+			x, y = leadingDigits(a), leadingDigits(b)
+			if comparison := compareNumbers(trimZeros(x), trimZeros(y)); comparison != 0 {
+				return comparison
+			}
+		} else {
+			x, y = leadingNonDigits(a), leadingNonDigits(b)
+			if comparison := strings.Compare(strings.ToLower(x), strings.ToLower(y)); comparison != 0 {
+				return comparison
+			}
+		}
+
+		a, b = a[len(x):], b[len(y):]
+	}
+
+	return compareBool(a != "", b != "")
+}
+
+// compareNumbers compares numbers written as digits without leading zeros, so
+// numbers of any length can be compared. An empty string is zero.
+func compareNumbers(a, b string) int {
+	if len(a) != len(b) {
+		return sign(len(a) - len(b))
+	}
+
+	return strings.Compare(a, b)
+}
+
+// compareBool orders false before true
+func compareBool(a, b bool) int {
+	if a == b {
+		return 0
+	}
+
+	if a {
+		return 1
+	}
+
+	return -1
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+
+	return s[:end]
+}
+
+func leadingNonDigits(s string) string {
+	end := 0
+	for end < len(s) && !isDigit(s[end]) {
+		end++
+	}
+
+	return s[:end]
+}
+
+func trimZeros(digits string) string {
+	return strings.TrimLeft(digits, "0")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+
+	if n > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,78 @@
+package versioncmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		older string
+		newer string
+	}{
+		{older: "1.2.0", newer: "1.10.0"},
+		{older: "1.2", newer: "1.2.1"},
+		{older: "1.0", newer: "1.0.0"},
+		{older: "9", newer: "10"},
+		{older: "v1.9.0", newer: "1.10.0"},
+		{older: "2.0.0-rc1", newer: "2.0.0"},
+		{older: "2.0.0-rc2", newer: "2.0.0-rc10"},
+		{older: "1.0.0-alpha", newer: "1.0.0-alpha.1"},
+		{older: "1.0.0-alpha.2", newer: "1.0.0-beta"},
+		{older: "1.0.0-beta.11", newer: "1.0.0-rc.1"},
+		{older: "3.13.0a2", newer: "3.13.0b1"},
+		{older: "3.13.0rc1", newer: "3.13.0"},
+		{older: "1.9.3", newer: "1.9.3-p551"},
+		{older: "1.1.0-SNAPSHOT", newer: "1.1.0"},
+		{older: "2023.12.31", newer: "2024.01.15"},
+		{older: "2024-01-15", newer: "2024-02-01"},
+		{older: "1.0.0-1", newer: "1.0.0-2"},
+		{older: "jdk-17.0.9+9", newer: "jdk-21.0.1+12"},
+		{older: "17.0.1+9", newer: "17.0.1+12"},
+		{older: "temurin-21.0.1", newer: "zulu-17.0.9"},
+		{older: "nightly", newer: "0.1.0"},
+		{older: "1.2.99999999999999999999", newer: "1.3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.older+" < "+tt.newer, func(t *testing.T) {
+			assert.Equal(t, -1, Compare(tt.older, tt.newer))
+			assert.Equal(t, 1, Compare(tt.newer, tt.older))
+		})
+	}
+
+	t.Run("returns zero for the same version", func(t *testing.T) {
+		assert.Equal(t, 0, Compare("1.2.3", "1.2.3"))
+	})
+}
+
+func TestSort(t *testing.T) {
+	versions := []string{"2.0.0", "1.10.0", "2.0.0-rc1", "1.2.0", "v1.9.0", "1.2.0-beta"}
+	Sort(versions)
+	assert.Equal(t, []string{"1.2.0-beta", "1.2.0", "v1.9.0", "1.10.0", "2.0.0-rc1", "2.0.0"}, versions)
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for _, version := range []string{"2.0.0-rc1", "2.0.0-rc.1", "3.13.0a2", "1.0.0-beta", "1.1.0-SNAPSHOT", "21-ea", "1.0.0-preview1", "nightly"} {
+		assert.True(t, IsPrerelease(version), version)
+	}
+
+	for _, version := range []string{"2.0.0", "v1.2.0", "1.9.3-p551", "jdk-17.0.9+9", "2024.01.15", "master"} {
+		assert.False(t, IsPrerelease(version), version)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	tests := map[string]string{
+		"3.13.0":          "",
+		"v1.2.0":          "",
+		"jdk-21.0.1":      "jdk-",
+		"stackless-3.7.5": "stackless-",
+		"nightly":         "nightly",
+	}
+
+	for version, prefix := range tests {
+		assert.Equal(t, prefix, Prefix(version), version)
+	}
+}
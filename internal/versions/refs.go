@@ -0,0 +1,249 @@
+package versions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/git"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/resolve"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+)
+
+// refSourceOption is the plugin option holding the URL of the Git repository
+// ref versions are built from. It is queried for plugins without a resolve-ref
+// callback.
+const refSourceOption = "ref_source_url"
+
+// RefStatus compares the commit an installed ref version was built from with
+// the commit its ref currently points to upstream
+type RefStatus struct {
+	Version toolversions.Version
+	// Installed is the commit recorded when the version was installed, empty
+	// if it is unknown
+	Installed string
+	// Upstream is the commit the ref currently points to
+	Upstream string
+	// Err is set if the upstream commit couldn't be looked up
+	Err error
+}
+
+// Outdated returns true if the ref moved since the version was installed.
+// Versions installed without a recorded commit are considered outdated.
+func (s RefStatus) Outdated() bool {
+	return s.Err == nil && s.Installed != s.Upstream
+}
+
+// UpstreamCommit returns the commit the branch or tag currently points to
+// upstream. The plugin's resolve-ref callback is asked first. Plugins without
+// one may have the URL of the tool's Git repository set in the ref_source_url
+// option of their `[plugin "<name>"]` asdfrc section, which is queried instead.
+func UpstreamCommit(conf config.Config, plugin plugins.Plugin, ref string) (string, error) {
+	commit, err := plugin.ResolveRef(ref)
+	if _, ok := err.(plugins.NoCallbackError); !ok {
+		if err == nil && commit == "" {
+			return "", fmt.Errorf("resolve-ref callback of %s printed no commit for %s", plugin.Name, ref)
+		}
+
+		return commit, err
+	}
+
+	options, err := conf.PluginOptions(plugin.Name)
+	if err != nil {
+		return "", err
+	}
+
+	url := options[refSourceOption]
+	if url == "" {
+		return "", fmt.Errorf("unable to resolve ref %s: %s has no resolve-ref callback and no %s option is set", ref, plugin.Name, refSourceOption)
+	}
+
+	return git.RemoteRef(url, ref, plugins.GitOptions(conf))
+}
+
+// RefStatuses checks every installed ref version of the tool against upstream
+func RefStatuses(conf config.Config, plugin plugins.Plugin) ([]RefStatus, error) {
+	installed, err := installs.Installed(conf, plugin)
+	if err != nil {
+		return []RefStatus{}, err
+	}
+
+	statuses := []RefStatus{}
+	for _, name := range installed {
+		ref, ok := strings.CutPrefix(name, "ref-")
+		if !ok {
+			continue
+		}
+
+		status := RefStatus{Version: toolversions.Version{Type: "ref", Value: ref}}
+		metadata, err := installs.ReadMetadata(conf, plugin, status.Version)
+		if err != nil {
+			return statuses, err
+		}
+
+		status.Installed = metadata.Commit
+		status.Upstream, status.Err = UpstreamCommit(conf, plugin, ref)
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// RefreshRef installs a ref version, or reinstalls it if its ref moved since it
+// was installed. The previous build is moved aside while the new one is built
+// in its place, and restored if the new build fails. Returns true if the
+// version was installed.
+func RefreshRef(conf config.Config, plugin plugins.Plugin, version toolversions.Version, stdOut, stdErr io.Writer) (bool, error) {
+	commit, err := UpstreamCommit(conf, plugin, version.Value)
+	if err != nil {
+		return false, err
+	}
+
+	err = recoverInstall(installs.InstallPath(conf, plugin, version))
+	if err != nil {
+		return false, err
+	}
+
+	versionStr := "ref:" + version.Value
+	if !installs.IsInstalled(conf, plugin, version) {
+		return true, installOneVersion(conf, plugin, versionStr, commit, false, false, stdOut, stdErr)
+	}
+
+	metadata, err := installs.ReadMetadata(conf, plugin, version)
+	if err != nil {
+		return false, err
+	}
+
+	if metadata.Commit == commit {
+		fmt.Fprintf(stdOut, "%s %s is up to date\n", plugin.Name, versionStr)
+		return false, nil
+	}
+
+	err = installOneVersion(conf, plugin, versionStr, commit, true, false, stdOut, stdErr)
+	if err != nil {
+		return false, fmt.Errorf("unable to refresh %s %s, kept previous build: %w", plugin.Name, versionStr, err)
+	}
+
+	return true, nil
+}
+
+// previousPath returns the directory the installed build is moved to while it
+// is rebuilt. Hidden directories aren't listed as installed versions.
+func previousPath(installDir string) string {
+	return filepath.Join(filepath.Dir(installDir), "."+filepath.Base(installDir)+".previous")
+}
+
+// moveAside moves the build installed at installDir out of the way, so the new
+// build is installed at the same path. The path is often recorded in the build,
+// for example as the prefix of tools built from source.
+func moveAside(installDir string) error {
+	err := os.Rename(installDir, previousPath(installDir))
+	if err != nil {
+		return fmt.Errorf("unable to move previous build aside: %w", err)
+	}
+
+	return nil
+}
+
+// finishReinstall removes the previous build moved aside by moveAside if the
+// new build installed successfully, and restores it in place of the new build
+// if installErr is set. Returns installErr, or the error restoring the
+// previous build.
+func finishReinstall(installDir string, installErr error) error {
+	previousDir := previousPath(installDir)
+	if installErr == nil {
+		return os.RemoveAll(previousDir)
+	}
+
+	err := os.RemoveAll(installDir)
+	if err == nil {
+		err = os.Rename(previousDir, installDir)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w, and unable to restore previous build from %s: %w", installErr, previousDir, err)
+	}
+
+	return installErr
+}
+
+// recoverInstall restores the previous build if asdf was interrupted while
+// rebuilding it. The previous build is only left in place when the rebuild
+// didn't finish, so whatever is installed at installDir is incomplete.
+func recoverInstall(installDir string) error {
+	previousDir := previousPath(installDir)
+	if _, err := os.Stat(previousDir); err != nil {
+		return nil
+	}
+
+	err := os.RemoveAll(installDir)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(previousDir, installDir)
+}
+
+// RefreshAll is like InstallAll, but refreshes ref versions with RefreshRef and
+// skips versions that are already installed
+func RefreshAll(conf config.Config, dir string, stdOut io.Writer, stdErr io.Writer) (failures []error) {
+	plugins, err := plugins.List(conf, false, false)
+	if err != nil {
+		return []error{fmt.Errorf("unable to list plugins: %w", err)}
+	}
+
+	for _, plugin := range plugins {
+		err := Refresh(conf, plugin, dir, stdOut, stdErr)
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	return failures
+}
+
+// Refresh is like Install, but refreshes ref versions with RefreshRef and skips
+// versions that are already installed
+func Refresh(conf config.Config, plugin plugins.Plugin, dir string, stdOut io.Writer, stdErr io.Writer) error {
+	err := plugin.Exists()
+	if err != nil {
+		return err
+	}
+
+	versions, found, err := resolve.Version(conf, plugin, dir)
+	if err != nil {
+		return err
+	}
+
+	if !found || len(versions.Versions) == 0 {
+		return NoVersionSetError{toolName: plugin.Name}
+	}
+
+	for _, version := range versions.Requested {
+		version, err := ResolveAvailable(plugin, version)
+		if err != nil {
+			return err
+		}
+
+		parsedVersion := toolversions.Parse(version)
+		switch {
+		case parsedVersion.Type == "ref":
+			_, err = RefreshRef(conf, plugin, parsedVersion, stdOut, stdErr)
+		case installs.IsInstalled(conf, plugin, parsedVersion):
+			continue
+		default:
+			err = InstallOneVersion(conf, plugin, version, false, stdOut, stdErr)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package versions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdf-vm/asdf/internal/config"
+	"github.com/asdf-vm/asdf/internal/installs"
+	"github.com/asdf-vm/asdf/internal/plugins"
+	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamCommit(t *testing.T) {
+	t.Setenv("ASDF_CONFIG_FILE", "testdata/asdfrc")
+
+	t.Run("returns error when plugin can't resolve refs", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		_, err := UpstreamCommit(conf, plugin, "main")
+		assert.ErrorContains(t, err, "has no resolve-ref callback and no ref_source_url option is set")
+	})
+
+	t.Run("returns commit printed by resolve-ref callback", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		setUpstreamCommit(t, plugin.Dir, "abc123")
+
+		commit, err := UpstreamCommit(conf, plugin, "main")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc123", commit)
+	})
+}
+
+func TestRefreshRef(t *testing.T) {
+	t.Setenv("ASDF_CONFIG_FILE", "testdata/asdfrc")
+	version := toolversions.Version{Type: "ref", Value: "main"}
+
+	t.Run("installs ref version and records its commit", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		setUpstreamCommit(t, plugin.Dir, "abc123")
+		stdout, stderr := buildOutputs()
+
+		installed, err := RefreshRef(conf, plugin, version, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.True(t, installed)
+		assert.DirExists(t, installs.InstallPath(conf, plugin, version))
+
+		metadata, err := installs.ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Equal(t, "abc123", metadata.Commit)
+	})
+
+	t.Run("installs ref version without looking up its commit", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		script := []byte("#!/usr/bin/env bash\nexit 1\n")
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, "bin", "resolve-ref"), script, 0o777))
+		stdout, stderr := buildOutputs()
+
+		assert.Nil(t, InstallOneVersion(conf, plugin, "ref:main", false, &stdout, &stderr))
+		metadata, err := installs.ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Empty(t, metadata.Commit)
+	})
+
+	t.Run("does nothing when ref hasn't moved", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		setUpstreamCommit(t, plugin.Dir, "abc123")
+		stdout, stderr := buildOutputs()
+		installRef(t, conf, plugin)
+
+		stdout.Reset()
+		installed, err := RefreshRef(conf, plugin, version, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.False(t, installed)
+		assert.Equal(t, "lua ref:main is up to date\n", stdout.String())
+	})
+
+	t.Run("reinstalls ref version when ref has moved", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		setUpstreamCommit(t, plugin.Dir, "abc123")
+		stdout, stderr := buildOutputs()
+		installRef(t, conf, plugin)
+		stale := filepath.Join(installs.InstallPath(conf, plugin, version), "stale")
+		assert.Nil(t, os.WriteFile(stale, []byte{}, 0o666))
+
+		setUpstreamCommit(t, plugin.Dir, "def456")
+		installed, err := RefreshRef(conf, plugin, version, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.True(t, installed)
+		assert.NoFileExists(t, stale)
+
+		metadata, err := installs.ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Equal(t, "def456", metadata.Commit)
+
+		versions, err := installs.Installed(conf, plugin)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ref-main"}, versions)
+		assert.NoDirExists(t, previousPath(installs.InstallPath(conf, plugin, version)))
+	})
+
+	t.Run("keeps previous build when reinstalling fails", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		setUpstreamCommit(t, plugin.Dir, "abc123")
+		stdout, stderr := buildOutputs()
+		installRef(t, conf, plugin)
+		previous := filepath.Join(installs.InstallPath(conf, plugin, version), "previous")
+		assert.Nil(t, os.WriteFile(previous, []byte{}, 0o666))
+
+		setUpstreamCommit(t, plugin.Dir, "def456")
+		// Fails unless the new build is installed at the path of the previous
+		// one, which has been moved aside
+		install := fmt.Sprintf("#!/usr/bin/env bash\ntest \"$ASDF_INSTALL_PATH\" = %q -a ! -e %q || exit 2\ntouch \"$ASDF_INSTALL_PATH/partial\"\nexit 1\n", installs.InstallPath(conf, plugin, version), previous)
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, "bin", "install"), []byte(install), 0o777))
+
+		installed, err := RefreshRef(conf, plugin, version, &stdout, &stderr)
+		assert.ErrorContains(t, err, "kept previous build")
+		assert.ErrorContains(t, err, "exit status 1")
+		assert.False(t, installed)
+		assert.FileExists(t, previous)
+		assert.NoFileExists(t, filepath.Join(installs.InstallPath(conf, plugin, version), "partial"))
+		assert.NoDirExists(t, previousPath(installs.InstallPath(conf, plugin, version)))
+
+		metadata, err := installs.ReadMetadata(conf, plugin, version)
+		assert.Nil(t, err)
+		assert.Equal(t, "abc123", metadata.Commit)
+	})
+}
+
+func TestRecoverInstall(t *testing.T) {
+	t.Run("restores previous build when install dir is missing", func(t *testing.T) {
+		installDir := filepath.Join(t.TempDir(), "ref-main")
+		assert.Nil(t, os.MkdirAll(previousPath(installDir), 0o777))
+
+		assert.Nil(t, recoverInstall(installDir))
+		assert.DirExists(t, installDir)
+		assert.NoDirExists(t, previousPath(installDir))
+	})
+
+	t.Run("replaces unfinished build with previous build", func(t *testing.T) {
+		installDir := filepath.Join(t.TempDir(), "ref-main")
+		assert.Nil(t, os.MkdirAll(previousPath(installDir), 0o777))
+		assert.Nil(t, os.WriteFile(filepath.Join(previousPath(installDir), "previous"), []byte{}, 0o666))
+		assert.Nil(t, os.MkdirAll(installDir, 0o777))
+		assert.Nil(t, os.WriteFile(filepath.Join(installDir, "partial"), []byte{}, 0o666))
+
+		assert.Nil(t, recoverInstall(installDir))
+		assert.FileExists(t, filepath.Join(installDir, "previous"))
+		assert.NoFileExists(t, filepath.Join(installDir, "partial"))
+		assert.NoDirExists(t, previousPath(installDir))
+	})
+
+	t.Run("does nothing without previous build", func(t *testing.T) {
+		installDir := filepath.Join(t.TempDir(), "ref-main")
+		assert.Nil(t, os.MkdirAll(installDir, 0o777))
+
+		assert.Nil(t, recoverInstall(installDir))
+		assert.DirExists(t, installDir)
+	})
+}
+
+func TestRefStatuses(t *testing.T) {
+	t.Setenv("ASDF_CONFIG_FILE", "testdata/asdfrc")
+	conf, plugin := generateConfig(t)
+	setUpstreamCommit(t, plugin.Dir, "abc123")
+	stdout, stderr := buildOutputs()
+	assert.Nil(t, InstallOneVersion(conf, plugin, "1.0.0", false, &stdout, &stderr))
+	installRef(t, conf, plugin)
+
+	statuses, err := RefStatuses(conf, plugin)
+	assert.Nil(t, err)
+	assert.Len(t, statuses, 1)
+	assert.False(t, statuses[0].Outdated())
+
+	setUpstreamCommit(t, plugin.Dir, "def456")
+	statuses, err = RefStatuses(conf, plugin)
+	assert.Nil(t, err)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, toolversions.Version{Type: "ref", Value: "main"}, statuses[0].Version)
+	assert.Equal(t, "abc123", statuses[0].Installed)
+	assert.Equal(t, "def456", statuses[0].Upstream)
+	assert.True(t, statuses[0].Outdated())
+}
+
+// installRef installs ref:main recording the commit it was built from
+func installRef(t *testing.T, conf config.Config, plugin plugins.Plugin) {
+	t.Helper()
+	stdout, stderr := buildOutputs()
+	installed, err := RefreshRef(conf, plugin, toolversions.Version{Type: "ref", Value: "main"}, &stdout, &stderr)
+	assert.Nil(t, err)
+	assert.True(t, installed)
+}
+
+// setUpstreamCommit gives the plugin a resolve-ref callback printing commit
+func setUpstreamCommit(t *testing.T, pluginDir, commit string) {
+	t.Helper()
+	script := []byte("#!/usr/bin/env bash\necho " + commit + "\n")
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDir, "bin", "resolve-ref"), script, 0o777))
+}
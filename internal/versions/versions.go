@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/asdf-vm/asdf/internal/config"
@@ -18,12 +19,14 @@ import (
 	"github.com/asdf-vm/asdf/internal/resolve"
 	"github.com/asdf-vm/asdf/internal/shims"
 	"github.com/asdf-vm/asdf/internal/toolversions"
+	"github.com/asdf-vm/asdf/internal/versioncmp"
 )
 
 const (
 	systemVersion           = "system"
 	latestVersion           = "latest"
 	uninstallableVersionMsg = "uninstallable version: %s"
+	unreleasedFilterRegex   = "(?i)(^Available versions:|-src|-latest|-stm|master)"
 	latestFilterRegex       = "(?i)(^Available versions:|-src|-dev|-latest|-stm|[-\\.]rc|-milestone|-alpha|-beta|[-\\.]pre|-next|(a|b|c)[0-9]+|snapshot|master)"
 	noLatestVersionErrMsg   = "no latest version found"
 )
@@ -93,8 +96,13 @@ func Install(conf config.Config, plugin plugins.Plugin, dir string, stdOut io.Wr
 		return NoVersionSetError{toolName: plugin.Name}
 	}
 
-	for _, version := range versions.Versions {
-		err := InstallOneVersion(conf, plugin, version, false, stdOut, stdErr)
+	for _, version := range versions.Requested {
+		version, err := ResolveAvailable(plugin, version)
+		if err != nil {
+			return err
+		}
+
+		err = InstallOneVersion(conf, plugin, version, false, stdOut, stdErr)
 		if err != nil {
 			return err
 		}
@@ -103,6 +111,47 @@ func Install(conf config.Config, plugin plugins.Plugin, dir string, stdOut io.Wr
 	return nil
 }
 
+// ResolveAvailable resolves a version constraint or prefix to the highest
+// version the backend can install that matches it. Prefixes like `20` are only
+// resolved when no version with exactly that name is available. Other versions
+// are returned as they are.
+func ResolveAvailable(backend plugins.Backend, version string) (string, error) {
+	isConstraint := toolversions.IsConstraint(version)
+	if !isConstraint && !toolversions.IsPrefix(version) {
+		return version, nil
+	}
+
+	constraint, err := toolversions.ParseConstraint(version)
+	if err != nil {
+		return version, err
+	}
+
+	allVersions, err := AllVersions(backend)
+	if err != nil {
+		if !isConstraint {
+			// Could be an exact version, let the backend decide
+			return version, nil
+		}
+
+		return version, err
+	}
+
+	if !isConstraint && slices.Contains(allVersions, version) {
+		return version, nil
+	}
+
+	highest, ok := constraint.Highest(allVersions)
+	if !ok {
+		if isConstraint {
+			return version, fmt.Errorf("no available version matches %s", version)
+		}
+
+		return version, nil
+	}
+
+	return highest, nil
+}
+
 // InstallVersion installs a version of a specific tool, the version may be an
 // exact version, or it may be `latest` or `latest` a regex query in order to
 // select the latest version matching the provided pattern.
@@ -125,6 +174,14 @@ func InstallVersion(conf config.Config, plugin plugins.Plugin, version toolversi
 
 // InstallOneVersion installs a specific version of a specific tool
 func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr string, keepDownload bool, stdOut io.Writer, stdErr io.Writer) error {
+	return installOneVersion(conf, plugin, versionStr, "", false, keepDownload, stdOut, stdErr)
+}
+
+// installOneVersion installs a specific version of a specific tool. The commit
+// is recorded for ref versions unless it is empty. When reinstalling, the
+// installed version is moved aside while the new one is installed at the same
+// path, and restored if the install callback fails.
+func installOneVersion(conf config.Config, plugin plugins.Plugin, versionStr, commit string, reinstall, keepDownload bool, stdOut io.Writer, stdErr io.Writer) error {
 	err := plugin.Exists()
 	if err != nil {
 		return err
@@ -142,7 +199,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 	downloadDir := installs.DownloadPath(conf, plugin, version)
 	installDir := installs.InstallPath(conf, plugin, version)
 
-	if installs.IsInstalled(conf, plugin, version) {
+	if !reinstall && installs.IsInstalled(conf, plugin, version) {
 		return fmt.Errorf("version %s of %s is already installed", version, plugin.Name)
 	}
 
@@ -166,7 +223,7 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 		return fmt.Errorf("failed to run pre-download hook: %w", err)
 	}
 
-	err = plugin.RunCallback("download", []string{}, env, stdOut, stdErr)
+	err = plugin.Download(env, stdOut, stdErr)
 	if _, ok := err.(plugins.NoCallbackError); err != nil && !ok {
 		return fmt.Errorf("failed to run download callback: %w", err)
 	}
@@ -176,14 +233,33 @@ func InstallOneVersion(conf config.Config, plugin plugins.Plugin, versionStr str
 		return fmt.Errorf("failed to run pre-install hook: %w", err)
 	}
 
+	if reinstall {
+		err = moveAside(installDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = os.MkdirAll(installDir, 0o777)
 	if err != nil {
-		return fmt.Errorf("unable to create install dir: %w", err)
+		err = fmt.Errorf("unable to create install dir: %w", err)
+	} else if err = plugin.Install(env, stdOut, stdErr); err != nil {
+		err = fmt.Errorf("failed to run install callback: %w", err)
+	}
+
+	if reinstall {
+		err = finishReinstall(installDir, err)
 	}
 
-	err = plugin.RunCallback("install", []string{}, env, stdOut, stdErr)
 	if err != nil {
-		return fmt.Errorf("failed to run install callback: %w", err)
+		return err
+	}
+
+	if version.Type == "ref" && commit != "" {
+		err = installs.WriteMetadata(conf, plugin, version, installs.Metadata{Commit: commit})
+		if err != nil {
+			return fmt.Errorf("unable to record installed commit: %w", err)
+		}
 	}
 
 	// Reshim
@@ -230,63 +306,93 @@ func asdfConcurrency(conf config.Config) string {
 	return val
 }
 
-// Latest invokes the plugin's latest-stable callback if it exists and returns
-// the version it returns. If the callback is missing it invokes the list-all
-// callback and returns the last version matching the query, if a query is
-// provided.
-func Latest(plugin plugins.Plugin, query string) (version string, err error) {
-	var stdOut strings.Builder
-	var stdErr strings.Builder
+// Latest asks the backend for the latest stable version matching the query.
+// If the backend doesn't support it, for example because the plugin lacks a
+// latest-stable callback, it lists all versions and returns the newest stable
+// version matching the query, if a query is provided.
+func Latest(backend plugins.Backend, query string) (version string, err error) {
+	return latest(backend, query, false)
+}
+
+// LatestIncludingPrereleases returns the newest version matching the query,
+// including pre-releases like `2.0.0-rc1`. The latest-stable callback is
+// skipped since it only returns stable versions.
+func LatestIncludingPrereleases(backend plugins.Backend, query string) (version string, err error) {
+	return latest(backend, query, true)
+}
+
+func latest(backend plugins.Backend, query string, includePrereleases bool) (version string, err error) {
+	var allVersions []string
+	if includePrereleases {
+		allVersions, err = AllVersionsFiltered(backend, query)
+	} else {
+		allVersions, err = latestStableCandidates(backend, query)
+	}
 
-	err = plugin.RunCallback("latest-stable", []string{query}, map[string]string{}, &stdOut, &stdErr)
 	if err != nil {
-		if _, ok := err.(plugins.NoCallbackError); !ok {
-			return version, err
-		}
+		return version, err
+	}
 
-		allVersions, err := AllVersionsFiltered(plugin, query)
-		if err != nil {
-			return version, err
+	versions := filterOutByRegex(allVersions, unreleasedFilterRegex)
+	if !includePrereleases {
+		versions = filterOutByRegex(versions, latestFilterRegex)
+		versions = slices.DeleteFunc(versions, versioncmp.IsPrerelease)
+	}
+
+	if len(versions) < 1 {
+		return version, errors.New(noLatestVersionErrMsg)
+	}
+
+	// Versions are compared by prefix first, so without a prefix in the query
+	// the versions of another distribution, like stackless-3.7.5 of Python,
+	// would be newer than every version without a prefix
+	if versioncmp.Prefix(query) == "" {
+		unprefixed := slices.DeleteFunc(slices.Clone(versions), func(version string) bool {
+			return versioncmp.Prefix(version) != ""
+		})
+
+		if len(unprefixed) > 0 {
+			versions = unprefixed
 		}
+	}
 
-		versions := filterOutByRegex(allVersions, latestFilterRegex)
+	return slices.MaxFunc(versions, versioncmp.Compare), nil
+}
 
-		if len(versions) < 1 {
-			return version, errors.New(noLatestVersionErrMsg)
+// latestStableCandidates returns the versions printed by the latest-stable
+// callback, or all versions matching the query if the backend doesn't support
+// it
+func latestStableCandidates(backend plugins.Backend, query string) ([]string, error) {
+	latest, err := backend.Latest(query)
+	if err != nil {
+		if _, ok := err.(plugins.NoCallbackError); !ok {
+			return []string{}, err
 		}
 
-		return versions[len(versions)-1], nil
+		return AllVersionsFiltered(backend, query)
 	}
 
-	// parse stdOut and return version
-	allVersions := parseVersions(stdOut.String())
-	versions := filterOutByRegex(allVersions, latestFilterRegex)
-	if len(versions) < 1 {
-		return version, errors.New(noLatestVersionErrMsg)
-	}
-	return versions[len(versions)-1], nil
+	return parseVersions(latest), nil
 }
 
 // AllVersions returns a slice of all available versions for the tool managed by
-// the given plugin by invoking the plugin's list-all callback
-func AllVersions(plugin plugins.Plugin) (versions []string, err error) {
-	var stdout strings.Builder
-	var stderr strings.Builder
-
-	err = plugin.RunCallback("list-all", []string{}, map[string]string{}, &stdout, &stderr)
+// the given backend, sorted from oldest to newest. For plugins this invokes the
+// list-all callback.
+func AllVersions(backend plugins.Backend) (versions []string, err error) {
+	versions, err = backend.ListAll()
 	if err != nil {
 		return versions, err
 	}
 
-	versions = parseVersions(stdout.String())
-
-	return versions, err
+	versions = slices.Clone(versions)
+	versioncmp.Sort(versions)
+	return versions, nil
 }
 
 // AllVersionsFiltered returns a list of existing versions that match a regex
 // query provided by the user.
-func AllVersionsFiltered(plugin plugins.Plugin, query string) (versions []string, err error) {
-	all, err := AllVersions(plugin)
+func AllVersionsFiltered(backend plugins.Backend, query string) (versions []string, err error) {
+	all, err := AllVersions(backend)
 	if err != nil {
 		return versions, err
 	}
@@ -320,7 +426,7 @@ func Uninstall(conf config.Config, plugin plugins.Plugin, rawVersion string, std
 		"ASDF_INSTALL_VERSION": version.Value,
 		"ASDF_INSTALL_PATH":    installDir,
 	}
-	err = plugin.RunCallback("uninstall", []string{}, env, stdout, stderr)
+	err = plugin.Uninstall(env, stdout, stderr)
 	if _, ok := err.(plugins.NoCallbackError); !ok && err != nil {
 		return err
 	}
@@ -330,6 +436,11 @@ func Uninstall(conf config.Config, plugin plugins.Plugin, rawVersion string, std
 		return err
 	}
 
+	err = installs.RemoveMetadata(conf, plugin, version)
+	if err != nil {
+		return err
+	}
+
 	err = hook.RunWithOutput(conf, fmt.Sprintf("post_asdf_uninstall_%s", plugin.Name), []string{version.Value}, stdout, stderr)
 	if err != nil {
 		return err
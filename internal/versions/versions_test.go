@@ -2,6 +2,7 @@ package versions
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -122,6 +123,48 @@ func TestInstall(t *testing.T) {
 		assertVersionInstalled(t, conf.DataDir, plugin.Name, "1.0.0")
 		assertVersionInstalled(t, conf.DataDir, plugin.Name, "2.0.0")
 	})
+
+	t.Run("installs highest available version matching constraint", func(t *testing.T) {
+		conf, plugin := generateConfig(t)
+		stdout, stderr := buildOutputs()
+		currentDir := t.TempDir()
+		writeVersionFile(t, currentDir, plugin.Name+" >=1.0 <2")
+
+		err := Install(conf, plugin, currentDir, &stdout, &stderr)
+		assert.Nil(t, err)
+
+		assertVersionInstalled(t, conf.DataDir, plugin.Name, "1.1.0")
+	})
+}
+
+func TestResolveAvailable(t *testing.T) {
+	_, plugin := generateConfig(t)
+
+	tests := []struct {
+		version string
+		want    string
+		err     string
+	}{
+		{version: "1", want: "1.1.0"},
+		{version: "~1.0", want: "1.0.0"},
+		{version: "^1", want: "1.1.0"},
+		{version: "1.0.0", want: "1.0.0"},
+		{version: "ref:main", want: "ref:main"},
+		{version: "3", want: "3"},
+		{version: ">2", want: ">2", err: "no available version matches >2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			version, err := ResolveAvailable(plugin, tt.version)
+			if tt.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+			assert.Equal(t, tt.want, version)
+		})
+	}
 }
 
 func TestInstallVersion(t *testing.T) {
@@ -304,6 +347,27 @@ func TestLatest(t *testing.T) {
 		assert.Equal(t, "5.1.0", version)
 	})
 
+	t.Run("when given no query returns latest version without a prefix", func(t *testing.T) {
+		pluginName := "latest-with-prefixes"
+		_, err := repotest.InstallPlugin("dummy_legacy_plugin", conf.DataDir, pluginName)
+		assert.Nil(t, err)
+		plugin := plugins.New(conf, pluginName)
+		listAll := "#!/usr/bin/env bash\necho 3.12.0 pypy3.10-7.3.17 stackless-3.7.5 3.13.0 miniforge3-24.1.2\n"
+		assert.Nil(t, os.WriteFile(filepath.Join(plugin.Dir, "bin", "list-all"), []byte(listAll), 0o777))
+
+		version, err := Latest(plugin, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "3.13.0", version)
+
+		version, err = Latest(plugin, "stackless")
+		assert.Nil(t, err)
+		assert.Equal(t, "stackless-3.7.5", version)
+
+		version, err = Latest(plugin, "pypy")
+		assert.Nil(t, err)
+		assert.Equal(t, "pypy3.10-7.3.17", version)
+	})
+
 	t.Run("when given no query returns latest version of plugin", func(t *testing.T) {
 		version, err := Latest(plugin, "4")
 		assert.Nil(t, err)
@@ -336,6 +400,57 @@ func TestAllVersions(t *testing.T) {
 	})
 }
 
+func TestBackend(t *testing.T) {
+	conf, _ := generateConfig(t)
+	backend := &fakeBackend{versions: []string{"1.0.0", "1.1.0", "2.0.0-rc1"}}
+	plugin := plugins.NewWithBackend(conf, "fake", backend)
+
+	t.Run("lists versions from backend", func(t *testing.T) {
+		versions, err := AllVersions(backend)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.1.0", "2.0.0-rc1"}, versions)
+	})
+
+	t.Run("falls back to listing versions when backend has no latest", func(t *testing.T) {
+		version, err := Latest(backend, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "1.1.0", version)
+	})
+
+	t.Run("returns newest version regardless of list order", func(t *testing.T) {
+		backend := &fakeBackend{versions: []string{"1.10.0", "2.0.0-beta", "1.9.0", "1.2.0"}}
+		version, err := Latest(backend, "1")
+		assert.Nil(t, err)
+		assert.Equal(t, "1.10.0", version)
+	})
+
+	t.Run("returns newest pre-release when including pre-releases", func(t *testing.T) {
+		version, err := LatestIncludingPrereleases(backend, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "2.0.0-rc1", version)
+	})
+
+	t.Run("lists versions sorted from oldest to newest", func(t *testing.T) {
+		backend := &fakeBackend{versions: []string{"1.10.0", "2.0.0-beta", "1.9.0", "2.0.0"}}
+		versions, err := AllVersions(backend)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.9.0", "1.10.0", "2.0.0-beta", "2.0.0"}, versions)
+	})
+
+	t.Run("installs and uninstalls version with backend", func(t *testing.T) {
+		stdout, stderr := buildOutputs()
+		err := InstallOneVersion(conf, plugin, "1.0.0", false, &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"download 1.0.0", "install 1.0.0"}, backend.calls)
+		assert.DirExists(t, filepath.Join(conf.DataDir, "installs", "fake", "1.0.0", "bin"))
+
+		err = Uninstall(conf, plugin, "1.0.0", &stdout, &stderr)
+		assert.Nil(t, err)
+		assert.Equal(t, "uninstall 1.0.0", backend.calls[len(backend.calls)-1])
+		assert.NoDirExists(t, filepath.Join(conf.DataDir, "installs", "fake", "1.0.0"))
+	})
+}
+
 func TestUninstall(t *testing.T) {
 	t.Setenv("ASDF_CONFIG_FILE", "testdata/uninstall-asdfrc")
 	pluginName := "uninstall-test"
@@ -434,6 +549,56 @@ func assertNotInstalled(t *testing.T, dataDir, pluginName, version string) {
 	assert.Empty(t, entries)
 }
 
+// fakeBackend is an in-process backend that records the operations invoked on
+// it
+type fakeBackend struct {
+	versions []string
+	calls    []string
+}
+
+func (b *fakeBackend) ListAll() ([]string, error) {
+	return b.versions, nil
+}
+
+func (b *fakeBackend) Latest(_ string) (string, error) {
+	return "", plugins.NoCallbackError{}
+}
+
+func (b *fakeBackend) Download(env map[string]string, _, _ io.Writer) error {
+	b.calls = append(b.calls, "download "+env["ASDF_INSTALL_VERSION"])
+	return nil
+}
+
+func (b *fakeBackend) Install(env map[string]string, _, _ io.Writer) error {
+	b.calls = append(b.calls, "install "+env["ASDF_INSTALL_VERSION"])
+	return os.MkdirAll(filepath.Join(env["ASDF_INSTALL_PATH"], "bin"), 0o777)
+}
+
+func (b *fakeBackend) BinPaths(_ map[string]string) ([]string, error) {
+	return []string{"bin"}, nil
+}
+
+func (b *fakeBackend) ExecEnv(env map[string]string) (map[string]string, error) {
+	return env, nil
+}
+
+func (b *fakeBackend) LegacyFilenames() ([]string, error) {
+	return []string{}, nil
+}
+
+func (b *fakeBackend) ParseLegacyFile(_ string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (b *fakeBackend) Uninstall(env map[string]string, _, _ io.Writer) error {
+	b.calls = append(b.calls, "uninstall "+env["ASDF_INSTALL_VERSION"])
+	return nil
+}
+
+func (b *fakeBackend) Help(_ string, _ map[string]string, _, _ io.Writer) error {
+	return plugins.NoCallbackError{}
+}
+
 func generateConfig(t *testing.T) (config.Config, plugins.Plugin) {
 	t.Helper()
 	testDataDir := t.TempDir()
@@ -0,0 +1,140 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// deniedError is returned when the sandbox doesn't allow a download
+type deniedError struct {
+	reason string
+}
+
+func (e deniedError) Error() string {
+	return e.reason
+}
+
+// fetchTimeout limits how long a single download may take, so a stalled server
+// can't hang the callback forever
+const fetchTimeout = 10 * time.Minute
+
+// maxRedirects is the number of redirects followed, the same as Go's default
+const maxRedirects = 10
+
+// fetch downloads rawURL to path if the options allow it
+func fetch(options Options, rawURL, path string) error {
+	if !options.Network {
+		return deniedError{reason: fmt.Sprintf("network access denied, plugin does not declare network access: %s", rawURL)}
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return deniedError{reason: fmt.Sprintf("only HTTP and HTTPS URLs can be downloaded: %s", rawURL)}
+	}
+
+	err = checkURL(options, parsed)
+	if err != nil {
+		return err
+	}
+
+	if !writable(options, path) {
+		return deniedError{reason: fmt.Sprintf("unable to write %s, only the download and install paths are writable", path)}
+	}
+
+	client := http.Client{
+		Timeout: fetchTimeout,
+		// Redirects are checked like the original URL, so an allowed host
+		// can't send the download to a host the plugin doesn't declare
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+
+			return checkURL(options, request.URL)
+		},
+	}
+
+	response, err := client.Get(rawURL)
+	var denied deniedError
+	if errors.As(err, &denied) {
+		return denied
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to download %s: %w", rawURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download %s: %s", rawURL, response.Status)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, response.Body)
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("unable to download %s: %w", rawURL, err)
+	}
+
+	return closeErr
+}
+
+// checkURL returns a deniedError unless the URL is an HTTP(S) URL on one of the
+// hosts the plugin declares
+func checkURL(options Options, parsed *url.URL) error {
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return deniedError{reason: fmt.Sprintf("only HTTP and HTTPS URLs can be downloaded: %s", parsed)}
+	}
+
+	if len(options.Hosts) > 0 && !slices.Contains(options.Hosts, parsed.Hostname()) {
+		return deniedError{reason: fmt.Sprintf("network access to %s denied, plugin does not declare the host", parsed.Hostname())}
+	}
+
+	return nil
+}
+
+// writable returns true if path is inside one of the writable directories.
+// Symlinks are resolved so the callback can't escape the sandbox by linking to
+// a file outside of it.
+func writable(options Options, path string) bool {
+	if !filepath.IsAbs(path) {
+		return false
+	}
+
+	if fileInfo, err := os.Lstat(path); err == nil && fileInfo.Mode()&fs.ModeSymlink != 0 {
+		return false
+	}
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+
+	for _, dir := range options.WritableDirs {
+		dir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+
+		relative, err := filepath.Rel(dir, parent)
+		if err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
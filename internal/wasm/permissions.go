@@ -0,0 +1,78 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// PermissionsFilename is the name of the file in the root of a plugin that
+// declares what its WebAssembly callbacks need access to beyond the default
+// sandbox
+const PermissionsFilename = "sandbox.ini"
+
+// Permissions are what a plugin's WebAssembly callbacks are allowed to do
+// beyond the default sandbox. Without a permissions file callbacks have no
+// network access and only receive ASDF_ environment variables.
+type Permissions struct {
+	// Network allows callbacks to download files
+	Network bool
+	// Hosts limits the hosts callbacks may download files from. Any host is
+	// allowed when empty.
+	Hosts []string
+	// Env are the names of additional environment variables passed to
+	// callbacks
+	Env []string
+}
+
+// LoadPermissions reads the permissions declared by the plugin in pluginDir
+func LoadPermissions(pluginDir string) (Permissions, error) {
+	path := filepath.Join(pluginDir, PermissionsFilename)
+	file, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Permissions{}, nil
+		}
+
+		return Permissions{}, fmt.Errorf("unable to load plugin sandbox permissions %s: %w", path, err)
+	}
+
+	main := file.Section("")
+	permissions := Permissions{
+		Hosts: strings.Fields(main.Key("hosts").String()),
+		Env:   strings.Fields(main.Key("env").String()),
+	}
+
+	if main.HasKey("network") {
+		permissions.Network, err = main.Key("network").Bool()
+		if err != nil {
+			return permissions, fmt.Errorf("invalid network value in plugin sandbox permissions %s: %s", path, main.Key("network").String())
+		}
+	}
+
+	return permissions, nil
+}
+
+// FilterEnv returns the variables from env callbacks are allowed to see:
+// variables starting with ASDF_ and the variables the plugin declares
+func (p Permissions) FilterEnv(env map[string]string) map[string]string {
+	filtered := map[string]string{}
+
+	for key, value := range env {
+		if strings.HasPrefix(key, "ASDF_") {
+			filtered[key] = value
+		}
+	}
+
+	for _, key := range p.Env {
+		if value, ok := env[key]; ok {
+			filtered[key] = value
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,237 @@
+package wasm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	experimentalsys "github.com/tetratelabs/wazero/experimental/sys"
+	"github.com/tetratelabs/wazero/experimental/sysfs"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// maxSymlinks is the number of symlinks followed when resolving a path before
+// giving up, the same as Linux
+const maxSymlinks = 40
+
+// rootFS is a directory mounted in the sandbox. Symlinks are resolved by asdf
+// rather than the OS, and paths that resolve to a file outside the directory
+// are refused, so a callback can't escape the sandbox with a symlink it
+// created, or one that was already in the directory.
+type rootFS struct {
+	root string
+	fs   experimentalsys.FS
+}
+
+// newRootFS returns the directory dir as a file system, read only unless
+// writable is true
+func newRootFS(dir string, writable bool) (experimentalsys.FS, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var mount experimentalsys.FS = rootFS{root: root, fs: sysfs.DirFS(root)}
+	if !writable {
+		mount = &sysfs.ReadFS{FS: mount}
+	}
+
+	return mount, nil
+}
+
+// resolve returns the path relative to the root the path refers to once all
+// symlinks in it are resolved. The last element isn't resolved unless
+// followLast is true. Returns EPERM if the path is outside the root.
+func (r rootFS) resolve(path string, followLast bool) (string, experimentalsys.Errno) {
+	return r.resolveFrom(r.root, strings.TrimLeft(path, "/"), followLast)
+}
+
+// resolveFrom is like resolve, but relative paths are relative to the host
+// directory start rather than the root
+func (r rootFS) resolveFrom(start, path string, followLast bool) (string, experimentalsys.Errno) {
+	current := start
+	if filepath.IsAbs(path) {
+		current = string(filepath.Separator)
+	}
+
+	pending := strings.Split(filepath.ToSlash(path), "/")
+	links := 0
+
+	for len(pending) > 0 {
+		element := pending[0]
+		pending = pending[1:]
+
+		switch element {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			continue
+		}
+
+		next := filepath.Join(current, element)
+		fileInfo, err := os.Lstat(next)
+		if err != nil || fileInfo.Mode()&fs.ModeSymlink == 0 || (len(pending) == 0 && !followLast) {
+			current = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", experimentalsys.ELOOP
+		}
+
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", experimentalsys.UnwrapOSError(err)
+		}
+
+		if filepath.IsAbs(target) {
+			current = string(filepath.Separator)
+		}
+		pending = append(strings.Split(filepath.ToSlash(target), "/"), pending...)
+	}
+
+	relative, err := filepath.Rel(r.root, current)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", experimentalsys.EPERM
+	}
+
+	return relative, 0
+}
+
+// OpenFile implements experimentalsys.FS
+func (r rootFS) OpenFile(path string, flag experimentalsys.Oflag, perm fs.FileMode) (experimentalsys.File, experimentalsys.Errno) {
+	resolved, errno := r.resolve(path, flag&experimentalsys.O_NOFOLLOW == 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	return r.fs.OpenFile(resolved, flag, perm)
+}
+
+// Lstat implements experimentalsys.FS
+func (r rootFS) Lstat(path string) (sys.Stat_t, experimentalsys.Errno) {
+	resolved, errno := r.resolve(path, false)
+	if errno != 0 {
+		return sys.Stat_t{}, errno
+	}
+
+	return r.fs.Lstat(resolved)
+}
+
+// Stat implements experimentalsys.FS
+func (r rootFS) Stat(path string) (sys.Stat_t, experimentalsys.Errno) {
+	resolved, errno := r.resolve(path, true)
+	if errno != 0 {
+		return sys.Stat_t{}, errno
+	}
+
+	return r.fs.Stat(resolved)
+}
+
+// Mkdir implements experimentalsys.FS
+func (r rootFS) Mkdir(path string, perm fs.FileMode) experimentalsys.Errno {
+	resolved, errno := r.resolve(path, false)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Mkdir(resolved, perm)
+}
+
+// Chmod implements experimentalsys.FS
+func (r rootFS) Chmod(path string, perm fs.FileMode) experimentalsys.Errno {
+	resolved, errno := r.resolve(path, true)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Chmod(resolved, perm)
+}
+
+// Rename implements experimentalsys.FS
+func (r rootFS) Rename(from, to string) experimentalsys.Errno {
+	resolvedFrom, errno := r.resolve(from, false)
+	if errno != 0 {
+		return errno
+	}
+
+	resolvedTo, errno := r.resolve(to, false)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Rename(resolvedFrom, resolvedTo)
+}
+
+// Rmdir implements experimentalsys.FS
+func (r rootFS) Rmdir(path string) experimentalsys.Errno {
+	resolved, errno := r.resolve(path, false)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Rmdir(resolved)
+}
+
+// Unlink implements experimentalsys.FS
+func (r rootFS) Unlink(path string) experimentalsys.Errno {
+	resolved, errno := r.resolve(path, false)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Unlink(resolved)
+}
+
+// Link implements experimentalsys.FS
+func (r rootFS) Link(oldPath, newPath string) experimentalsys.Errno {
+	resolvedOld, errno := r.resolve(oldPath, false)
+	if errno != 0 {
+		return errno
+	}
+
+	resolvedNew, errno := r.resolve(newPath, false)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Link(resolvedOld, resolvedNew)
+}
+
+// Symlink implements experimentalsys.FS. Symlinks to files outside the root
+// are refused.
+func (r rootFS) Symlink(oldPath, linkName string) experimentalsys.Errno {
+	resolved, errno := r.resolve(linkName, false)
+	if errno != 0 {
+		return errno
+	}
+
+	if _, errno := r.resolveFrom(filepath.Join(r.root, filepath.Dir(resolved)), oldPath, true); errno != 0 {
+		return experimentalsys.EPERM
+	}
+
+	return r.fs.Symlink(oldPath, resolved)
+}
+
+// Readlink implements experimentalsys.FS
+func (r rootFS) Readlink(path string) (string, experimentalsys.Errno) {
+	resolved, errno := r.resolve(path, false)
+	if errno != 0 {
+		return "", errno
+	}
+
+	return r.fs.Readlink(resolved)
+}
+
+// Utimens implements experimentalsys.FS
+func (r rootFS) Utimens(path string, atim, mtim int64) experimentalsys.Errno {
+	resolved, errno := r.resolve(path, true)
+	if errno != 0 {
+		return errno
+	}
+
+	return r.fs.Utimens(resolved, atim, mtim)
+}
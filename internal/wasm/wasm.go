@@ -0,0 +1,224 @@
+// Package wasm runs plugin callbacks compiled to WebAssembly in a sandbox.
+// Callbacks are run with a pure Go WASI runtime so they can't execute other
+// programs, only see the directories they are given, only receive the
+// environment variables they are given and only reach the network through a
+// host function that is disabled unless the plugin declares it needs network
+// access. Symlinks are resolved inside the sandbox, so a callback can't reach
+// files outside of the directories it is given through them.
+package wasm
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental/sysfs"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// Extension is the file extension of WebAssembly callbacks
+const Extension = ".wasm"
+
+// HostModule is the name of the module providing asdf's host functions to
+// callbacks
+const HostModule = "asdf"
+
+// compilationCache is shared by all runtimes so a module run more than once by
+// the same asdf process is only compiled once
+var compilationCache = wazero.NewCompilationCache()
+
+// Options describe the sandbox a callback is run in
+type Options struct {
+	// Args are the arguments passed to the callback, not including the program
+	// name
+	Args []string
+	// Env is the complete environment of the callback
+	Env map[string]string
+	// ReadOnlyDirs and WritableDirs are the host directories the callback can
+	// access. They are mounted at the same path inside the sandbox so paths in
+	// the environment and arguments are valid in both.
+	ReadOnlyDirs []string
+	WritableDirs []string
+	// Network allows the callback to download files with the fetch host
+	// function
+	Network bool
+	// Hosts limits the hosts the callback may download files from. Any host is
+	// allowed when empty.
+	Hosts []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExitError is returned when a callback exits with a non-zero exit code
+type ExitError struct {
+	Code uint32
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Validate checks the file is a WebAssembly module the runtime can run
+func Validate(modulePath string) error {
+	ctx := context.Background()
+	runtime := newRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	_, err := compile(ctx, runtime, modulePath)
+	return err
+}
+
+// Run runs the WebAssembly module at modulePath with the options and waits for
+// it to exit
+func Run(modulePath string, options Options) error {
+	ctx := context.Background()
+	runtime := newRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	_, err := wasi_snapshot_preview1.Instantiate(ctx, runtime)
+	if err != nil {
+		return err
+	}
+
+	_, err = runtime.NewHostModuleBuilder(HostModule).
+		NewFunctionBuilder().WithFunc(fetchFunc(options)).Export("fetch").
+		Instantiate(ctx)
+	if err != nil {
+		return err
+	}
+
+	module, err := compile(ctx, runtime, modulePath)
+	if err != nil {
+		return err
+	}
+
+	fsConfig := wazero.NewFSConfig()
+	for _, dir := range options.ReadOnlyDirs {
+		fsConfig, err = mount(fsConfig, dir, false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, dir := range options.WritableDirs {
+		fsConfig, err = mount(fsConfig, dir, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{modulePath}, options.Args...)...).
+		WithFSConfig(fsConfig).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+
+	for key, value := range options.Env {
+		config = config.WithEnv(key, value)
+	}
+
+	if options.Stdin != nil {
+		config = config.WithStdin(options.Stdin)
+	}
+	if options.Stdout != nil {
+		config = config.WithStdout(options.Stdout)
+	}
+	if options.Stderr != nil {
+		config = config.WithStderr(options.Stderr)
+	}
+
+	instance, err := runtime.InstantiateModule(ctx, module, config)
+	if instance != nil {
+		instance.Close(ctx)
+	}
+
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() == 0 {
+			return nil
+		}
+
+		return ExitError{Code: exitErr.ExitCode()}
+	}
+
+	return err
+}
+
+// mount adds the directory to the sandbox at the same path as on the host
+func mount(fsConfig wazero.FSConfig, dir string, writable bool) (wazero.FSConfig, error) {
+	root, err := newRootFS(dir, writable)
+	if err != nil {
+		return fsConfig, err
+	}
+
+	return fsConfig.(sysfs.FSConfig).WithSysFSMount(root, dir), nil
+}
+
+func newRuntime(ctx context.Context) wazero.Runtime {
+	return wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCompilationCache(compilationCache))
+}
+
+func compile(ctx context.Context, runtime wazero.Runtime, modulePath string) (wazero.CompiledModule, error) {
+	contents, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := runtime.CompileModule(ctx, contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebAssembly module %s: %w", modulePath, err)
+	}
+
+	return module, nil
+}
+
+// Result codes returned by the fetch host function
+const (
+	fetchOK = iota
+	fetchDenied
+	fetchFailed
+)
+
+// fetchFunc returns the fetch host function. Callbacks call it with the URL to
+// download and the path to write it to, both passed as a pointer and length
+// into the module's memory. The destination must be inside one of the writable
+// directories. Reasons for failures are written to the callback's stderr.
+func fetchFunc(options Options) func(context.Context, api.Module, uint32, uint32, uint32, uint32) uint32 {
+	stderr := options.Stderr
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	return func(_ context.Context, module api.Module, urlPtr, urlLen, pathPtr, pathLen uint32) uint32 {
+		rawURL, ok := module.Memory().Read(urlPtr, urlLen)
+		if !ok {
+			return fetchFailed
+		}
+
+		path, ok := module.Memory().Read(pathPtr, pathLen)
+		if !ok {
+			return fetchFailed
+		}
+
+		err := fetch(options, string(rawURL), string(path))
+		if err != nil {
+			fmt.Fprintf(stderr, "asdf: %s\n", err)
+			if _, ok := err.(deniedError); ok {
+				return fetchDenied
+			}
+
+			return fetchFailed
+		}
+
+		return fetchOK
+	}
+}
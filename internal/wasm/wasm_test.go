@@ -0,0 +1,220 @@
+package wasm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asdf-vm/asdf/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	modulePath := filepath.Join(t.TempDir(), "callback.wasm")
+	err := repotest.BuildWasmCallback(modulePath)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	pluginDir := t.TempDir()
+	installDir := t.TempDir()
+	outsideDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDir, "versions"), []byte("1.0.0"), 0o666))
+	assert.Nil(t, os.WriteFile(filepath.Join(outsideDir, "secret"), []byte("secret"), 0o666))
+
+	run := func(options Options, args ...string) (string, string, error) {
+		var stdout, stderr strings.Builder
+		options.Args = args
+		options.ReadOnlyDirs = []string{pluginDir}
+		options.WritableDirs = []string{installDir}
+		options.Stdout = &stdout
+		options.Stderr = &stderr
+		err := Run(modulePath, options)
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("runs module and captures output", func(t *testing.T) {
+		stdout, _, err := run(Options{})
+		assert.Nil(t, err)
+		assert.Equal(t, "1.0.0 2.0.0\n", stdout)
+	})
+
+	t.Run("passes only given environment variables", func(t *testing.T) {
+		t.Setenv("HOST_VARIABLE", "host")
+		stdout, _, err := run(Options{Env: map[string]string{"ASDF_INSTALL_VERSION": "1.0.0"}}, "env")
+		assert.Nil(t, err)
+		assert.Equal(t, "ASDF_INSTALL_VERSION=1.0.0\n", stdout)
+	})
+
+	t.Run("reads files in read only directories", func(t *testing.T) {
+		stdout, _, err := run(Options{}, "read", filepath.Join(pluginDir, "versions"))
+		assert.Nil(t, err)
+		assert.Equal(t, "1.0.0", stdout)
+	})
+
+	t.Run("does not read files outside mounted directories", func(t *testing.T) {
+		stdout, _, err := run(Options{}, "read", filepath.Join(outsideDir, "secret"))
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("writes files in writable directories", func(t *testing.T) {
+		_, _, err := run(Options{}, "write", filepath.Join(installDir, "tool"), "installed")
+		assert.Nil(t, err)
+		contents, err := os.ReadFile(filepath.Join(installDir, "tool"))
+		assert.Nil(t, err)
+		assert.Equal(t, "installed", string(contents))
+	})
+
+	t.Run("does not write files in read only directories", func(t *testing.T) {
+		_, _, err := run(Options{}, "write", filepath.Join(pluginDir, "tool"), "installed")
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.NoFileExists(t, filepath.Join(pluginDir, "tool"))
+	})
+
+	t.Run("creates symlinks inside writable directories", func(t *testing.T) {
+		_, _, err := run(Options{}, "symlink", "tool", filepath.Join(installDir, "tool-link"))
+		assert.Nil(t, err)
+		stdout, _, err := run(Options{}, "read", filepath.Join(installDir, "tool-link"))
+		assert.Nil(t, err)
+		assert.Equal(t, "installed", stdout)
+	})
+
+	t.Run("does not create symlinks out of writable directories", func(t *testing.T) {
+		link := filepath.Join(installDir, "escape")
+		_, _, err := run(Options{}, "symlink", "../../../../../../../../etc", link)
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.NoFileExists(t, link)
+
+		stdout, _, err := run(Options{}, "read", filepath.Join(link, "hostname"))
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("does not create chained symlinks out of writable directories", func(t *testing.T) {
+		_, _, err := run(Options{}, "mkdir", filepath.Join(installDir, "b"))
+		assert.Nil(t, err)
+		_, _, err = run(Options{}, "symlink", "..", filepath.Join(installDir, "b", "c"))
+		assert.Nil(t, err)
+		_, _, err = run(Options{}, "symlink", "b/c/..", filepath.Join(installDir, "a"))
+		assert.Equal(t, ExitError{Code: 1}, err)
+
+		run(Options{}, "write", filepath.Join(installDir, "b", "c", "..", "evil"), "evil")
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(installDir), "evil"))
+	})
+
+	t.Run("does not follow existing symlinks out of mounted directories", func(t *testing.T) {
+		assert.Nil(t, os.Symlink(outsideDir, filepath.Join(installDir, "outside")))
+		assert.Nil(t, os.Symlink(outsideDir, filepath.Join(pluginDir, "outside")))
+
+		stdout, _, err := run(Options{}, "read", filepath.Join(installDir, "outside", "secret"))
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.Empty(t, stdout)
+
+		stdout, _, err = run(Options{}, "read", filepath.Join(pluginDir, "outside", "secret"))
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.Empty(t, stdout)
+
+		_, _, err = run(Options{}, "write", filepath.Join(installDir, "outside", "written"), "evil")
+		assert.Equal(t, ExitError{Code: 1}, err)
+		assert.NoFileExists(t, filepath.Join(outsideDir, "written"))
+	})
+
+	t.Run("reads stdin", func(t *testing.T) {
+		stdout, _, err := run(Options{Stdin: strings.NewReader("lts/*")}, "stdin")
+		assert.Nil(t, err)
+		assert.Equal(t, "lts/*", stdout)
+	})
+
+	t.Run("returns ExitError with exit code", func(t *testing.T) {
+		_, _, err := run(Options{}, "exit", "3")
+		assert.Equal(t, ExitError{Code: 3}, err)
+		assert.ErrorContains(t, err, "exit status 3")
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("artifact"))
+	}))
+	defer server.Close()
+
+	t.Run("denies network access unless allowed", func(t *testing.T) {
+		stdout, stderr, err := run(Options{}, "fetch", server.URL, filepath.Join(installDir, "artifact"))
+		assert.Nil(t, err)
+		assert.Equal(t, "1\n", stdout)
+		assert.Contains(t, stderr, "network access denied")
+		assert.NoFileExists(t, filepath.Join(installDir, "artifact"))
+	})
+
+	t.Run("denies network access to undeclared hosts", func(t *testing.T) {
+		stdout, stderr, err := run(Options{Network: true, Hosts: []string{"example.com"}}, "fetch", server.URL, filepath.Join(installDir, "artifact"))
+		assert.Nil(t, err)
+		assert.Equal(t, "1\n", stdout)
+		assert.Contains(t, stderr, "does not declare the host")
+	})
+
+	t.Run("denies redirects to undeclared hosts", func(t *testing.T) {
+		serverURL, err := url.Parse(server.URL)
+		assert.Nil(t, err)
+		redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://localhost:"+serverURL.Port(), http.StatusFound)
+		}))
+		defer redirect.Close()
+
+		stdout, stderr, err := run(Options{Network: true, Hosts: []string{"127.0.0.1"}}, "fetch", redirect.URL, filepath.Join(installDir, "redirected"))
+		assert.Nil(t, err)
+		assert.Equal(t, "1\n", stdout)
+		assert.Contains(t, stderr, "network access to localhost denied")
+		assert.NoFileExists(t, filepath.Join(installDir, "redirected"))
+	})
+
+	t.Run("denies downloads outside writable directories", func(t *testing.T) {
+		stdout, _, err := run(Options{Network: true}, "fetch", server.URL, filepath.Join(outsideDir, "artifact"))
+		assert.Nil(t, err)
+		assert.Equal(t, "1\n", stdout)
+		assert.NoFileExists(t, filepath.Join(outsideDir, "artifact"))
+	})
+
+	t.Run("downloads files when network access is allowed", func(t *testing.T) {
+		stdout, _, err := run(Options{Network: true}, "fetch", server.URL, filepath.Join(installDir, "artifact"))
+		assert.Nil(t, err)
+		assert.Equal(t, "0\n", stdout)
+		contents, err := os.ReadFile(filepath.Join(installDir, "artifact"))
+		assert.Nil(t, err)
+		assert.Equal(t, "artifact", string(contents))
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("returns error for invalid module", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "callback.wasm")
+		assert.Nil(t, os.WriteFile(path, []byte("#!/usr/bin/env bash\n"), 0o666))
+		assert.ErrorContains(t, Validate(path), "invalid WebAssembly module")
+	})
+}
+
+func TestWritable(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	options := Options{WritableDirs: []string{dir}}
+
+	t.Run("returns true for files in writable directory", func(t *testing.T) {
+		assert.True(t, writable(options, filepath.Join(dir, "file")))
+	})
+
+	t.Run("returns false for relative paths and paths outside writable directories", func(t *testing.T) {
+		assert.False(t, writable(options, "file"))
+		assert.False(t, writable(options, filepath.Join(outside, "file")))
+		assert.False(t, writable(options, filepath.Join(dir, "..", "file")))
+	})
+
+	t.Run("returns false for symlinks out of writable directory", func(t *testing.T) {
+		assert.Nil(t, os.Symlink(outside, filepath.Join(dir, "link")))
+		assert.Nil(t, os.Symlink(filepath.Join(outside, "file"), filepath.Join(dir, "file-link")))
+		assert.False(t, writable(options, filepath.Join(dir, "link", "file")))
+		assert.False(t, writable(options, filepath.Join(dir, "file-link")))
+	})
+}
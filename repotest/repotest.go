@@ -94,6 +94,25 @@ func GeneratePluginIndex(asdfDataDir string) (string, error) {
 	return createGitRepo(destination)
 }
 
+// BuildWasmCallback compiles the WebAssembly callback fixture to outputPath.
+// The fixture is a Go program built for WASI that reads, writes and downloads
+// files depending on its arguments.
+func BuildWasmCallback(outputPath string) error {
+	root, err := getModuleRoot()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "build", "-o", outputPath, filepath.Join(root, "test/fixtures/wasm_callback/main.go"))
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable to build WebAssembly callback: %w: %s", err, output)
+	}
+
+	return nil
+}
+
 func generatePluginInDir(root, fixtureName, outputDir, pluginName string) (string, error) {
 	// Copy in plugin files into output dir
 	pluginPath, err := copyInPlugin(root, fixtureName, outputDir, pluginName)
@@ -0,0 +1,64 @@
+//go:build wasip1
+
+// Command wasm_callback is a plugin callback compiled to WebAssembly for
+// testing the sandbox. The first argument selects what it does.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"unsafe"
+)
+
+//go:wasmimport asdf fetch
+func fetch(urlPtr, urlLen, pathPtr, pathLen uint32) uint32
+
+func main() {
+	if len(os.Args) < 2 {
+		// Without arguments behave like a list-all callback
+		fmt.Println("1.0.0 2.0.0")
+		return
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "env":
+		env := os.Environ()
+		sort.Strings(env)
+		for _, variable := range env {
+			fmt.Println(variable)
+		}
+	case "read":
+		var contents []byte
+		contents, err = os.ReadFile(os.Args[2])
+		os.Stdout.Write(contents)
+	case "write":
+		err = os.WriteFile(os.Args[2], []byte(os.Args[3]), 0o666)
+	case "symlink":
+		err = os.Symlink(os.Args[2], os.Args[3])
+	case "mkdir":
+		err = os.Mkdir(os.Args[2], 0o777)
+	case "fetch":
+		result := fetch(stringPointer(os.Args[2]), uint32(len(os.Args[2])), stringPointer(os.Args[3]), uint32(len(os.Args[3])))
+		fmt.Println(result)
+	case "exit":
+		code, _ := strconv.Atoi(os.Args[2])
+		os.Exit(code)
+	default:
+		// Behave like a parse-legacy-file callback, which receives the legacy
+		// file on stdin
+		_, err = io.Copy(os.Stdout, os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func stringPointer(s string) uint32 {
+	return uint32(uintptr(unsafe.Pointer(unsafe.StringData(s))))
+}
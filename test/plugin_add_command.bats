@@ -174,3 +174,28 @@ EOM
 ADD"
   [ "$output" = "${expected_output}" ]
 }
+
+@test "plugin_add command with --link after the name links the working copy" {
+  install_mock_plugin_repo "dummy"
+
+  run asdf plugin add "dummy" --link "${BASE_DIR}/repo-dummy"
+  [ "$status" -eq 0 ]
+  [ -L "${ASDF_DIR}/plugins/dummy" ]
+
+  run asdf plugin list
+  [ "$output" = "dummy" ]
+}
+
+@test "plugin_add command with --link before the name links the working copy" {
+  install_mock_plugin_repo "dummy"
+
+  run asdf plugin add --link "${BASE_DIR}/repo-dummy" "dummy"
+  [ "$status" -eq 0 ]
+  [ -L "${ASDF_DIR}/plugins/dummy" ]
+}
+
+@test "plugin_add command with --link and no path prints usage" {
+  run asdf plugin add "dummy" --link
+  [ "$status" -eq 1 ]
+  [ "$output" = "usage: asdf plugin add <name> --link <path>" ]
+}